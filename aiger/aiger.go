@@ -0,0 +1,92 @@
+package aiger
+
+import "fmt"
+
+// A Latch is a state element of an AIG.
+type Latch struct {
+	Lit  int // Literal of the latch
+	Next int // Literal giving the value of the latch at the next step
+	// Reset is the initial value of the latch: 0 or 1, or Lit if it is undetermined.
+	Reset int
+}
+
+// An And is an and gate: LHS is true iff both RHS0 and RHS1 are true.
+type And struct {
+	LHS, RHS0, RHS1 int
+}
+
+// An AIG is an and-inverter graph.
+type AIG struct {
+	MaxVar      int // Maximal variable index
+	Inputs      []int
+	Latches     []Latch
+	Outputs     []int
+	Bad         []int // Bad state properties
+	Constraints []int // Invariant constraints
+	Ands        []And // And gates, sorted so that each gate comes after the gates it depends on
+	// Symbols are the names given by the symbol table, e.g Symbols["i0"] is the name of the first input.
+	Symbols map[string]string
+}
+
+// Targets returns the literals whose satisfiability is checked: the bad state properties or,
+// if there are none, the outputs.
+func (aig *AIG) Targets() []int {
+	if len(aig.Bad) != 0 {
+		return aig.Bad
+	}
+	return aig.Outputs
+}
+
+// InputName returns the name of the i'th input, as given by the symbol table, or "i" followed by i.
+// Parse makes sure inputs and latches have distinct names.
+func (aig *AIG) InputName(i int) string {
+	return aig.name("i", i)
+}
+
+// LatchName returns the name of the i'th latch, as given by the symbol table, or "l" followed by i.
+func (aig *AIG) LatchName(i int) string {
+	return aig.name("l", i)
+}
+
+func (aig *AIG) name(prefix string, i int) string {
+	key := fmt.Sprintf("%s%d", prefix, i)
+	if name, ok := aig.Symbols[key]; ok {
+		return name
+	}
+	return key
+}
+
+// Eval returns the value of each variable of the AIG, given the values of its inputs and latches.
+// The value of a literal can then be read with LitValue.
+func (aig *AIG) Eval(inputs, latches []bool) []bool {
+	vals := make([]bool, aig.MaxVar+1)
+	for i, lit := range aig.Inputs {
+		vals[lit/2] = inputs[i]
+	}
+	for i, l := range aig.Latches {
+		vals[l.Lit/2] = latches[i]
+	}
+	for _, a := range aig.Ands {
+		vals[a.LHS/2] = LitValue(vals, a.RHS0) && LitValue(vals, a.RHS1)
+	}
+	return vals
+}
+
+// LitValue returns the value of lit, given the values of the variables, as returned by Eval.
+func LitValue(vals []bool, lit int) bool {
+	return vals[lit/2] != (lit&1 == 1)
+}
+
+// Assignment returns the values of the inputs and latches in model, a model of the formula returned by aig.Formula.
+// Variables missing from the model are false.
+func (aig *AIG) Assignment(model map[string]bool) (inputs, latches []bool) {
+	inputs = make([]bool, len(aig.Inputs))
+	for i := range inputs {
+		inputs[i] = model[aig.InputName(i)]
+	}
+	latches = make([]bool, len(aig.Latches))
+	for i := range latches {
+		latches[i] = model[aig.LatchName(i)]
+	}
+	return inputs, latches
+}
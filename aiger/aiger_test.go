@@ -0,0 +1,273 @@
+package aiger
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/DoOR-Team/gophersat/bf"
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// A toggle flip-flop: the latch changes its value when enable is true.
+// The output is true when the latch is true and enable is false.
+const toggle = `aag 5 1 1 1 3
+2
+4 11 0
+6
+10 7 9
+6 4 3
+8 5 2
+i0 enable
+l0 state
+o0 out
+c
+a comment
+`
+
+func TestParseASCII(t *testing.T) {
+	aig, err := Parse(strings.NewReader(toggle))
+	if err != nil {
+		t.Fatalf("could not parse AIG: %v", err)
+	}
+	if aig.MaxVar != 5 || !reflect.DeepEqual(aig.Inputs, []int{2}) || !reflect.DeepEqual(aig.Outputs, []int{6}) {
+		t.Errorf("invalid AIG %+v", aig)
+	}
+	if !reflect.DeepEqual(aig.Latches, []Latch{{Lit: 4, Next: 11, Reset: 0}}) {
+		t.Errorf("invalid latches %v", aig.Latches)
+	}
+	pos := make(map[int]int) // Position of each gate
+	for i, a := range aig.Ands {
+		pos[a.LHS] = i
+	}
+	if len(aig.Ands) != 3 || pos[6] > pos[10] || pos[8] > pos[10] {
+		t.Errorf("and gates are not sorted: %v", aig.Ands)
+	}
+	if aig.InputName(0) != "enable" || aig.LatchName(0) != "state" || aig.Symbols["o0"] != "out" {
+		t.Errorf("invalid symbols %v", aig.Symbols)
+	}
+	if vals := aig.Eval([]bool{false}, []bool{true}); !LitValue(vals, 6) || !LitValue(vals, 11) {
+		t.Errorf("invalid evaluation: output and next state should be true")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{"", "empty AIG"},
+		{"p cnf 1 1\n", "line 1: expected \"aag\" or \"aig\" header"},
+		{"aag 1 1 0\n", "line 1: expected 5 to 9 integers in header"},
+		{"aag 1 1 0 0 0 0 0 1 0\n2\n", "line 1: justice and fairness properties are not supported"},
+		{"aig 3 1 0 1 1\n", "line 1: invalid binary header"},
+		{"aag 1 1 0 1 0\n2\n", "line 3: unexpected end of file"},
+		{"aag 1 1 0 1 0\n2\n2 3\n", "line 3: expected 1 integers"},
+		{"aag 1 1 0 1 0\n3\n2\n", "invalid definition of literal 3"},
+		{"aag 1 1 1 0 0\n2\n2 2\n", "variable 1 is defined twice"},
+		{"aag 2 1 0 1 0\n2\n4\n", "undefined literal 4"},
+		{"aag 3 1 0 1 0\n2\n4\n", "maximal variable index 3 is greater than the largest variable used, 2"},
+		{"aag 900000000 0 0 0 0\n", "maximal variable index 900000000 is greater"},
+		{"aag 4000000000000 0 0 0 0\n", "maximal variable index 4000000000000 is greater"},
+		{"aag 3 1 0 1 2\n2\n4\n4 6 2\n6 4 2\n", "cycle through and gate"},
+		{"aag 2 1 1 0 0\n2\n4 2 3\n", "line 3: invalid reset value 3"},
+		{"aag 1 1 0 0 0\n2\nx0 name\n", "invalid symbol \"x0 name\""},
+		{"aag 1 1 0 0 0\n2\ni1 name\n", "invalid symbol \"i1 name\""},
+		{"aag 2 1 1 0 0\n2\n4 2\ni0 l0\n", "name \"l0\" is given to several inputs or latches"},
+		{"aag 2 2 0 0 0\n2\n4\ni0 x\ni1 x\n", "name \"x\" is given to several inputs or latches"},
+		{"aig 2 1 0 1 1\n4\n\x04", "and gate 4: unexpected end of file"},
+		{"aig 2 1 0 1 1\n4\n\x05\x00", "and gate 4: invalid deltas 5 and 0"},
+	}
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.content))
+		if err == nil {
+			t.Errorf("parsing %q: expected error %q, got nil", test.content, test.err)
+		} else if !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("parsing %q: expected error %q, got %q", test.content, test.err, err)
+		}
+	}
+}
+
+// randomAIG returns a random AIG, with variables numbered as expected by the binary format.
+func randomAIG(r *rand.Rand) *AIG {
+	nbInputs, nbLatches, nbAnds := 1+r.Intn(4), r.Intn(3), 1+r.Intn(10)
+	aig := &AIG{MaxVar: nbInputs + nbLatches + nbAnds, Symbols: make(map[string]string)}
+	randLit := func(maxVar int) int {
+		return r.Intn(2*maxVar + 2)
+	}
+	for i := 0; i < nbInputs; i++ {
+		aig.Inputs = append(aig.Inputs, 2*(i+1))
+	}
+	for i := 0; i < nbLatches; i++ {
+		lit := 2 * (nbInputs + i + 1)
+		resets := []int{0, 1, lit}
+		aig.Latches = append(aig.Latches, Latch{Lit: lit, Next: randLit(aig.MaxVar), Reset: resets[r.Intn(3)]})
+	}
+	for i := 0; i < nbAnds; i++ {
+		lhs := 2 * (nbInputs + nbLatches + i + 1)
+		rhs0, rhs1 := randLit(lhs/2-1), randLit(lhs/2-1)
+		if rhs0 < rhs1 {
+			rhs0, rhs1 = rhs1, rhs0
+		}
+		aig.Ands = append(aig.Ands, And{LHS: lhs, RHS0: rhs0, RHS1: rhs1})
+	}
+	for i := 0; i < 1+r.Intn(2); i++ {
+		aig.Outputs = append(aig.Outputs, randLit(aig.MaxVar))
+	}
+	for i := 0; i < r.Intn(3); i++ {
+		aig.Bad = append(aig.Bad, randLit(aig.MaxVar))
+	}
+	for i := 0; i < r.Intn(2); i++ {
+		aig.Constraints = append(aig.Constraints, randLit(aig.MaxVar))
+	}
+	return aig
+}
+
+// writeAIG writes aig in the ASCII or the binary format.
+func writeAIG(aig *AIG, binary bool) string {
+	var b bytes.Buffer
+	format := "aag"
+	if binary {
+		format = "aig"
+	}
+	fmt.Fprintf(&b, "%s %d %d %d %d %d %d %d\n", format, aig.MaxVar, len(aig.Inputs), len(aig.Latches), len(aig.Outputs),
+		len(aig.Ands), len(aig.Bad), len(aig.Constraints))
+	if !binary {
+		for _, lit := range aig.Inputs {
+			fmt.Fprintf(&b, "%d\n", lit)
+		}
+	}
+	for _, l := range aig.Latches {
+		if !binary {
+			fmt.Fprintf(&b, "%d ", l.Lit)
+		}
+		fmt.Fprintf(&b, "%d %d\n", l.Next, l.Reset)
+	}
+	for _, lits := range [][]int{aig.Outputs, aig.Bad, aig.Constraints} {
+		for _, lit := range lits {
+			fmt.Fprintf(&b, "%d\n", lit)
+		}
+	}
+	for _, a := range aig.Ands {
+		if !binary {
+			fmt.Fprintf(&b, "%d %d %d\n", a.LHS, a.RHS0, a.RHS1)
+			continue
+		}
+		for _, delta := range []int{a.LHS - a.RHS0, a.RHS0 - a.RHS1} {
+			for ; delta >= 0x80; delta >>= 7 {
+				b.WriteByte(byte(delta&0x7f | 0x80))
+			}
+			b.WriteByte(byte(delta))
+		}
+	}
+	b.WriteString("i0 first input\n")
+	return b.String()
+}
+
+func TestParseBinary(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 100; iter++ {
+		aig := randomAIG(r)
+		aig.Symbols["i0"] = "first input"
+		for _, binary := range []bool{false, true} {
+			content := writeAIG(aig, binary)
+			aig2, err := Parse(strings.NewReader(content))
+			if err != nil {
+				t.Fatalf("could not parse %q: %v", content, err)
+			}
+			if !reflect.DeepEqual(aig, aig2) {
+				t.Fatalf("parsing %q: expected %+v, got %+v", content, aig, aig2)
+			}
+		}
+	}
+}
+
+// bruteForce returns whether a target of aig can be true while all constraints are true.
+func bruteForce(aig *AIG) bool {
+	nbVars := len(aig.Inputs) + len(aig.Latches)
+	for mask := 0; mask < 1<<uint(nbVars); mask++ {
+		vals := make([]bool, nbVars)
+		for i := range vals {
+			vals[i] = mask&(1<<uint(i)) != 0
+		}
+		if accepts(aig, vals[:len(aig.Inputs)], vals[len(aig.Inputs):]) {
+			return true
+		}
+	}
+	return false
+}
+
+func accepts(aig *AIG, inputs, latches []bool) bool {
+	vals := aig.Eval(inputs, latches)
+	for _, lit := range aig.Constraints {
+		if !LitValue(vals, lit) {
+			return false
+		}
+	}
+	for _, lit := range aig.Targets() {
+		if LitValue(vals, lit) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTranslate(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 500; iter++ {
+		aig := randomAIG(r)
+		expected := bruteForce(aig)
+		cnf := aig.CNF()
+		s := solver.New(cnf.Problem)
+		if sat := s.Solve() == solver.Sat; sat != expected {
+			t.Fatalf("iter %d: expected sat=%t for CNF of %+v, got %t", iter, expected, aig, sat)
+		} else if sat && !accepts(aig, cnf.Inputs(s.Model()), cnf.Latches(s.Model())) {
+			t.Fatalf("iter %d: CNF model %v is not accepted by %+v", iter, s.Model(), aig)
+		}
+		model := bf.SolveWith(aig.Formula(), bf.Options{Conversion: bf.Tseitin})
+		if sat := model != nil; sat != expected {
+			t.Fatalf("iter %d: expected sat=%t for formula of %+v, got %t", iter, expected, aig, sat)
+		} else if inputs, latches := aig.Assignment(model); sat && !accepts(aig, inputs, latches) {
+			t.Fatalf("iter %d: formula model %v is not accepted by %+v", iter, model, aig)
+		}
+	}
+}
+
+// Structural hashing merges identical gates, and the cone of influence ignores gates the outputs do not depend on.
+func TestCNFSize(t *testing.T) {
+	const content = `aag 6 2 0 1 4
+2
+4
+8
+6 2 4
+8 4 2
+10 6 9
+12 3 5
+`
+	aig, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("could not parse AIG: %v", err)
+	}
+	cnf := aig.CNF()
+	if cnf.Problem.NbVars != 3 {
+		t.Errorf("expected 3 vars, got %d", cnf.Problem.NbVars)
+	}
+}
+
+func ExampleAIG_CNF() {
+	aig, err := Parse(strings.NewReader(toggle))
+	if err != nil {
+		fmt.Printf("could not parse AIG: %v\n", err)
+		return
+	}
+	cnf := aig.CNF()
+	s := solver.New(cnf.Problem)
+	if s.Solve() == solver.Sat {
+		fmt.Println(cnf.Inputs(s.Model()), cnf.Latches(s.Model()))
+	}
+	// Output:
+	// [false] [true]
+}
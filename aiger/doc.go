@@ -0,0 +1,17 @@
+// Package aiger reads and-inverter graphs in the AIGER format, and translates them to SAT problems.
+//
+// An and-inverter graph (AIG) is a circuit made of inputs, latches and two-input and gates.
+// Each signal is denoted by a literal: 2*v for variable v, 2*v+1 for its negation, 0 and 1 being the constants
+// false and true. Files can be either in the ASCII format (".aag" files, starting with "aag M I L O A") or
+// in the binary one (".aig" files, starting with "aig M I L O A"), where and gates are delta-encoded.
+// The bad state properties and invariant constraints of AIGER 1.9 are supported, but justice and fairness
+// properties are not. The maximal variable index M must be used by some literal of the file.
+//
+// The targets of an AIG are its bad state properties, or its outputs if it has none. The CNF and Formula methods
+// translate the AIG to a problem that is satisfiable iff at least one target can be true while all invariant
+// constraints hold. Latches are considered as free variables: the problem tells whether a bad state exists,
+// not whether it can be reached from the initial state.
+//
+// Before the translation, the graph is structurally hashed: constants are propagated, and and gates with the same
+// inputs are merged. Only the gates the targets depend on are then translated, with one variable per gate.
+package aiger
@@ -0,0 +1,402 @@
+package aiger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/DoOR-Team/gophersat/input"
+)
+
+// Parse parses an AIG in the ASCII or the binary AIGER format from r, possibly compressed.
+// The format is detected from the header.
+func Parse(r io.Reader) (*AIG, error) {
+	r, err := input.Decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	p := parser{r: bufio.NewReader(r), aig: &AIG{Symbols: make(map[string]string)}}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.aig, nil
+}
+
+type parser struct {
+	r      *bufio.Reader
+	lineNb int
+	aig    *AIG
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.lineNb, fmt.Sprintf(format, args...))
+}
+
+// readLine reads the next line, without its final newline, or returns io.EOF if there is none.
+func (p *parser) readLine() (string, error) {
+	line, err := p.r.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return "", io.EOF
+	}
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("could not read AIG: %v", err)
+	}
+	p.lineNb++
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readInts reads a line made of min to max non-negative integers.
+func (p *parser) readInts(min, max int) ([]int, error) {
+	line, err := p.readLine()
+	if err == io.EOF {
+		return nil, fmt.Errorf("line %d: unexpected end of file", p.lineNb+1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(line)
+	if len(fields) < min || len(fields) > max {
+		if min == max {
+			return nil, p.errorf("expected %d integers, found %q", min, line)
+		}
+		return nil, p.errorf("expected %d to %d integers, found %q", min, max, line)
+	}
+	res := make([]int, len(fields))
+	for i, field := range fields {
+		val, err := strconv.Atoi(field)
+		if err != nil || val < 0 {
+			return nil, p.errorf("expected non-negative integer, found %q", field)
+		}
+		res[i] = val
+	}
+	return res, nil
+}
+
+// header holds the counts given in the header of an AIGER file.
+type header struct {
+	binary                bool
+	maxVar, nbInputs      int
+	nbLatches, nbOutputs  int
+	nbAnds, nbBad, nbCons int
+}
+
+func (p *parser) parseHeader() (h header, err error) {
+	line, err := p.readLine()
+	if err == io.EOF {
+		return h, fmt.Errorf("empty AIG")
+	}
+	if err != nil {
+		return h, err
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 || (fields[0] != "aag" && fields[0] != "aig") {
+		return h, p.errorf("expected \"aag\" or \"aig\" header, found %q", line)
+	}
+	if len(fields) < 6 || len(fields) > 10 {
+		return h, p.errorf("expected 5 to 9 integers in header, found %q", line)
+	}
+	vals := make([]int, 9)
+	for i, field := range fields[1:] {
+		if vals[i], err = strconv.Atoi(field); err != nil || vals[i] < 0 {
+			return h, p.errorf("expected non-negative integer in header, found %q", field)
+		}
+	}
+	if vals[7] != 0 || vals[8] != 0 {
+		return h, p.errorf("justice and fairness properties are not supported")
+	}
+	h = header{
+		binary: fields[0] == "aig",
+		maxVar: vals[0], nbInputs: vals[1], nbLatches: vals[2], nbOutputs: vals[3], nbAnds: vals[4],
+		nbBad: vals[5], nbCons: vals[6],
+	}
+	if h.binary && h.maxVar != h.nbInputs+h.nbLatches+h.nbAnds {
+		return h, p.errorf("invalid binary header: M must be I+L+A")
+	}
+	return h, nil
+}
+
+func (p *parser) parse() error {
+	h, err := p.parseHeader()
+	if err != nil {
+		return err
+	}
+	aig := p.aig
+	aig.MaxVar = h.maxVar
+	for i := 0; i < h.nbInputs; i++ {
+		lit := 2 * (i + 1)
+		if !h.binary {
+			vals, err := p.readInts(1, 1)
+			if err != nil {
+				return err
+			}
+			lit = vals[0]
+		}
+		aig.Inputs = append(aig.Inputs, lit)
+	}
+	for i := 0; i < h.nbLatches; i++ {
+		l := Latch{Lit: 2 * (h.nbInputs + i + 1)}
+		var vals []int
+		if h.binary {
+			vals, err = p.readInts(1, 2)
+		} else {
+			vals, err = p.readInts(2, 3)
+			if err == nil {
+				l.Lit, vals = vals[0], vals[1:]
+			}
+		}
+		if err != nil {
+			return err
+		}
+		l.Next = vals[0]
+		if len(vals) == 2 {
+			if l.Reset = vals[1]; l.Reset != 0 && l.Reset != 1 && l.Reset != l.Lit {
+				return p.errorf("invalid reset value %d for latch %d", l.Reset, l.Lit)
+			}
+		}
+		aig.Latches = append(aig.Latches, l)
+	}
+	for _, lits := range []struct {
+		dest *[]int
+		nb   int
+	}{{&aig.Outputs, h.nbOutputs}, {&aig.Bad, h.nbBad}, {&aig.Constraints, h.nbCons}} {
+		for i := 0; i < lits.nb; i++ {
+			vals, err := p.readInts(1, 1)
+			if err != nil {
+				return err
+			}
+			*lits.dest = append(*lits.dest, vals[0])
+		}
+	}
+	if h.binary {
+		err = p.parseBinaryAnds(h)
+	} else {
+		err = p.parseAnds(h)
+	}
+	if err != nil {
+		return err
+	}
+	if err := p.parseSymbols(h); err != nil {
+		return err
+	}
+	return aig.check()
+}
+
+func (p *parser) parseAnds(h header) error {
+	for i := 0; i < h.nbAnds; i++ {
+		vals, err := p.readInts(3, 3)
+		if err != nil {
+			return err
+		}
+		p.aig.Ands = append(p.aig.Ands, And{LHS: vals[0], RHS0: vals[1], RHS1: vals[2]})
+	}
+	return nil
+}
+
+// parseBinaryAnds parses the delta-encoded and gates of a binary AIG.
+func (p *parser) parseBinaryAnds(h header) error {
+	for i := 0; i < h.nbAnds; i++ {
+		lhs := 2 * (h.nbInputs + h.nbLatches + i + 1)
+		delta0, err := p.readDelta()
+		if err != nil {
+			return fmt.Errorf("and gate %d: %v", lhs, err)
+		}
+		delta1, err := p.readDelta()
+		if err != nil {
+			return fmt.Errorf("and gate %d: %v", lhs, err)
+		}
+		if delta0 == 0 || delta0 > lhs || delta1 > lhs-delta0 {
+			return fmt.Errorf("and gate %d: invalid deltas %d and %d", lhs, delta0, delta1)
+		}
+		rhs0 := lhs - delta0
+		p.aig.Ands = append(p.aig.Ands, And{LHS: lhs, RHS0: rhs0, RHS1: rhs0 - delta1})
+	}
+	return nil
+}
+
+// readDelta reads an integer encoded on 7-bit groups, least significant first,
+// the most significant bit of each byte telling whether another byte follows.
+func (p *parser) readDelta() (int, error) {
+	res := 0
+	for shift := uint(0); ; shift += 7 {
+		if shift > 56 {
+			return 0, fmt.Errorf("delta is too big")
+		}
+		b, err := p.r.ReadByte()
+		if err == io.EOF {
+			return 0, fmt.Errorf("unexpected end of file")
+		}
+		if err != nil {
+			return 0, fmt.Errorf("could not read AIG: %v", err)
+		}
+		res |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return res, nil
+		}
+	}
+}
+
+// parseSymbols parses the optional symbol table, up to the comment section.
+func (p *parser) parseSymbols(h header) error {
+	counts := map[byte]int{'i': h.nbInputs, 'l': h.nbLatches, 'o': h.nbOutputs, 'b': h.nbBad, 'c': h.nbCons}
+	for {
+		line, err := p.readLine()
+		if err == io.EOF || line == "c" {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if line == "" {
+			continue
+		}
+		sep := strings.IndexByte(line, ' ')
+		if sep <= 0 {
+			return fmt.Errorf("invalid symbol %q", line)
+		}
+		key, name := line[:sep], line[sep+1:]
+		idx, err := strconv.Atoi(key[1:])
+		if nb, ok := counts[key[0]]; !ok || err != nil || idx < 0 || idx >= nb {
+			return fmt.Errorf("invalid symbol %q", line)
+		}
+		p.aig.Symbols[key] = name
+	}
+}
+
+// check checks that each variable is defined once, each used literal is defined, and inputs and latches
+// have distinct names, and sorts the and gates so that each one comes after the gates it depends on.
+func (aig *AIG) check() error {
+	if max := aig.maxUsedVar(); aig.MaxVar > max { // Don't trust M before allocating anything from it
+		return fmt.Errorf("maximal variable index %d is greater than the largest variable used, %d", aig.MaxVar, max)
+	}
+	defs := make([]int, aig.MaxVar+1) // 1 for inputs and latches, 2+i for the i'th and gate, 0 if undefined
+	define := func(lit, def int) error {
+		if lit < 2 || lit&1 == 1 || lit/2 > aig.MaxVar {
+			return fmt.Errorf("invalid definition of literal %d", lit)
+		}
+		if defs[lit/2] != 0 {
+			return fmt.Errorf("variable %d is defined twice", lit/2)
+		}
+		defs[lit/2] = def
+		return nil
+	}
+	for _, lit := range aig.Inputs {
+		if err := define(lit, 1); err != nil {
+			return err
+		}
+	}
+	for _, l := range aig.Latches {
+		if err := define(l.Lit, 1); err != nil {
+			return err
+		}
+	}
+	for i, a := range aig.Ands {
+		if err := define(a.LHS, 2+i); err != nil {
+			return err
+		}
+	}
+	var used []int
+	for _, l := range aig.Latches {
+		used = append(used, l.Next)
+	}
+	for _, a := range aig.Ands {
+		used = append(used, a.RHS0, a.RHS1)
+	}
+	used = append(used, aig.Outputs...)
+	used = append(used, aig.Bad...)
+	used = append(used, aig.Constraints...)
+	for _, lit := range used {
+		if lit/2 > aig.MaxVar || (lit > 1 && defs[lit/2] == 0) {
+			return fmt.Errorf("undefined literal %d", lit)
+		}
+	}
+	if err := aig.checkNames(); err != nil {
+		return err
+	}
+	return aig.sortAnds(defs)
+}
+
+// maxUsedVar returns the largest variable index found in the lits of aig.
+func (aig *AIG) maxUsedVar() int {
+	lits := append([]int{}, aig.Inputs...)
+	for _, l := range aig.Latches {
+		lits = append(lits, l.Lit, l.Next)
+	}
+	for _, a := range aig.Ands {
+		lits = append(lits, a.LHS, a.RHS0, a.RHS1)
+	}
+	lits = append(lits, aig.Outputs...)
+	lits = append(lits, aig.Bad...)
+	lits = append(lits, aig.Constraints...)
+	max := 0
+	for _, lit := range lits {
+		if lit/2 > max {
+			max = lit / 2
+		}
+	}
+	return max
+}
+
+// checkNames checks that no two inputs or latches have the same name, as given by InputName and LatchName,
+// so that they are associated with distinct variables in formulas.
+func (aig *AIG) checkNames() error {
+	names := make([]string, 0, len(aig.Inputs)+len(aig.Latches))
+	for i := range aig.Inputs {
+		names = append(names, aig.InputName(i))
+	}
+	for i := range aig.Latches {
+		names = append(names, aig.LatchName(i))
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			return fmt.Errorf("name %q is given to several inputs or latches", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// sortAnds sorts the and gates in topological order, through an iterative depth-first search.
+func (aig *AIG) sortAnds(defs []int) error {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]byte, len(aig.Ands))
+	sorted := make([]And, 0, len(aig.Ands))
+	for i := range aig.Ands {
+		stack := []int{i}
+		for len(stack) != 0 {
+			j := stack[len(stack)-1]
+			if state[j] == visited {
+				stack = stack[:len(stack)-1]
+				continue
+			}
+			state[j] = visiting
+			done := true
+			for _, rhs := range []int{aig.Ands[j].RHS0, aig.Ands[j].RHS1} {
+				k := defs[rhs/2] - 2
+				if k < 0 { // Constant, input or latch
+					continue
+				}
+				switch state[k] {
+				case unvisited:
+					stack = append(stack, k)
+					done = false
+				case visiting:
+					return fmt.Errorf("cycle through and gate %d", aig.Ands[k].LHS)
+				}
+			}
+			if done {
+				state[j] = visited
+				sorted = append(sorted, aig.Ands[j])
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	aig.Ands = sorted
+	return nil
+}
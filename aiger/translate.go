@@ -0,0 +1,214 @@
+package aiger
+
+import (
+	"github.com/DoOR-Team/gophersat/bf"
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// A graph is a structurally hashed copy of an AIG.
+// Nodes are referred to by literals, as in the AIG: node 0 is the constant false, nodes 1 to nbLeaves are
+// the inputs followed by the latches, and the following nodes are and gates.
+type graph struct {
+	nbLeaves int
+	ands     [][2]int       // Children of each and node
+	hashes   map[[2]int]int // Node associated with each pair of children
+	lits     []int          // For each variable of the AIG, the equivalent literal in the graph
+}
+
+func newGraph(aig *AIG) *graph {
+	g := &graph{
+		nbLeaves: len(aig.Inputs) + len(aig.Latches),
+		hashes:   make(map[[2]int]int),
+		lits:     make([]int, aig.MaxVar+1),
+	}
+	for i, lit := range aig.Inputs {
+		g.lits[lit/2] = 2 * (i + 1)
+	}
+	for i, l := range aig.Latches {
+		g.lits[l.Lit/2] = 2 * (len(aig.Inputs) + i + 1)
+	}
+	for _, a := range aig.Ands {
+		g.lits[a.LHS/2] = g.and(g.lit(a.RHS0), g.lit(a.RHS1))
+	}
+	return g
+}
+
+// lit returns the literal of the graph equivalent to the given literal of the AIG.
+func (g *graph) lit(aigLit int) int {
+	return g.lits[aigLit/2] ^ (aigLit & 1)
+}
+
+// and returns the literal of the conjunction of l1 and l2, after propagating constants.
+func (g *graph) and(l1, l2 int) int {
+	if l1 > l2 {
+		l1, l2 = l2, l1
+	}
+	switch {
+	case l1 == 0 || l1^1 == l2: // false & x, ~x & x
+		return 0
+	case l1 == 1 || l1 == l2: // true & x, x & x
+		return l2
+	}
+	key := [2]int{l1, l2}
+	if n, ok := g.hashes[key]; ok {
+		return 2 * n
+	}
+	g.ands = append(g.ands, key)
+	n := g.nbLeaves + len(g.ands)
+	g.hashes[key] = n
+	return 2 * n
+}
+
+// cone returns, for each node, whether one of the given literals depends on it.
+func (g *graph) cone(lits []int) []bool {
+	needed := make([]bool, g.nbLeaves+len(g.ands)+1)
+	for _, lit := range lits {
+		needed[lit/2] = true
+	}
+	for i := len(g.ands) - 1; i >= 0; i-- {
+		if needed[g.nbLeaves+i+1] {
+			needed[g.ands[i][0]/2] = true
+			needed[g.ands[i][1]/2] = true
+		}
+	}
+	return needed
+}
+
+// targets returns the literals of the graph associated with the targets and the invariant constraints of aig.
+func (g *graph) targets(aig *AIG) (targets, constrs []int) {
+	for _, lit := range aig.Targets() {
+		targets = append(targets, g.lit(lit))
+	}
+	for _, lit := range aig.Constraints {
+		constrs = append(constrs, g.lit(lit))
+	}
+	return targets, constrs
+}
+
+// A CNF is the translation of an AIG to a CNF problem.
+// Variables 1 to len(Inputs) are the inputs of the AIG, they are followed by the latches,
+// and the other variables are associated with and gates.
+type CNF struct {
+	Problem   *solver.Problem
+	nbInputs  int
+	nbLatches int
+}
+
+// CNF returns the Tseitin translation of aig. The problem is satisfiable iff at least one target of aig
+// can be true while all invariant constraints are true.
+func (aig *AIG) CNF() *CNF {
+	g := newGraph(aig)
+	targets, constrs := g.targets(aig)
+	needed := g.cone(append(targets, constrs...))
+	vars := make([]int, len(needed)) // CNF var associated with each node
+	nbVars := g.nbLeaves
+	for i := 1; i <= g.nbLeaves; i++ {
+		vars[i] = i
+	}
+	var clauses [][]int
+	cnfLit := func(lit int) int {
+		if lit&1 == 1 {
+			return -vars[lit/2]
+		}
+		return vars[lit/2]
+	}
+	for i, children := range g.ands {
+		n := g.nbLeaves + i + 1
+		if !needed[n] {
+			continue
+		}
+		nbVars++
+		vars[n] = nbVars
+		l1, l2 := cnfLit(children[0]), cnfLit(children[1])
+		clauses = append(clauses, []int{-nbVars, l1}, []int{-nbVars, l2}, []int{nbVars, -l1, -l2})
+	}
+	for _, lit := range constrs {
+		switch lit {
+		case 0:
+			clauses = append(clauses, []int{})
+		case 1:
+		default:
+			clauses = append(clauses, []int{cnfLit(lit)})
+		}
+	}
+	var clause []int
+	trivial := false
+	for _, lit := range targets {
+		switch lit {
+		case 0:
+		case 1:
+			trivial = true
+		default:
+			clause = append(clause, cnfLit(lit))
+		}
+	}
+	if !trivial {
+		clauses = append(clauses, clause)
+	}
+	return &CNF{Problem: solver.ParseSliceNb(clauses, nbVars), nbInputs: len(aig.Inputs), nbLatches: len(aig.Latches)}
+}
+
+// Inputs returns the values of the inputs of the AIG in model, a model of c.Problem.
+func (c *CNF) Inputs(model []bool) []bool {
+	return append([]bool(nil), model[:c.nbInputs]...)
+}
+
+// Latches returns the values of the latches of the AIG in model, a model of c.Problem.
+func (c *CNF) Latches(model []bool) []bool {
+	return append([]bool(nil), model[c.nbInputs:c.nbInputs+c.nbLatches]...)
+}
+
+// Formula returns a formula that is satisfiable iff at least one target of aig can be true while
+// all invariant constraints are true. Its variables are named after the inputs and latches of aig,
+// as given by InputName and LatchName.
+func (aig *AIG) Formula() bf.Formula {
+	targets := aig.Targets()
+	fs := aig.Formulas(append(append([]int(nil), aig.Constraints...), targets...))
+	subs := append([]bf.Formula(nil), fs[:len(aig.Constraints)]...)
+	return bf.And(append(subs, bf.Or(fs[len(aig.Constraints):]...))...)
+}
+
+// Formulas returns formulas equivalent to the given literals of aig. Their variables are named after
+// the inputs and latches of aig, as given by InputName and LatchName. The formulas are built after
+// structural hashing, and their subformulas are shared, so they are best translated to CNF with
+// the Tseitin or the Plaisted-Greenbaum transformation.
+func (aig *AIG) Formulas(lits []int) []bf.Formula {
+	g := newGraph(aig)
+	leaves := make([]bf.Formula, g.nbLeaves)
+	for i := range aig.Inputs {
+		leaves[i] = bf.Var(aig.InputName(i))
+	}
+	for i := range aig.Latches {
+		leaves[len(aig.Inputs)+i] = bf.Var(aig.LatchName(i))
+	}
+	glits := make([]int, len(lits))
+	for i, lit := range lits {
+		glits[i] = g.lit(lit)
+	}
+	return g.formulas(leaves, glits)
+}
+
+// formulas returns the formulas associated with the given literals of g, given the formulas associated
+// with its leaves. The formula of each and node is built once, and shared.
+func (g *graph) formulas(leaves []bf.Formula, lits []int) []bf.Formula {
+	needed := g.cone(lits)
+	nodes := make([]bf.Formula, len(needed))
+	nodes[0] = bf.False
+	copy(nodes[1:], leaves)
+	litFormula := func(lit int) bf.Formula {
+		if lit&1 == 1 {
+			return bf.Not(nodes[lit/2])
+		}
+		return nodes[lit/2]
+	}
+	for i, children := range g.ands {
+		if n := g.nbLeaves + i + 1; needed[n] {
+			nodes[n] = bf.And(litFormula(children[0]), litFormula(children[1]))
+		}
+	}
+	res := make([]bf.Formula, len(lits))
+	for i, lit := range lits {
+		res[i] = litFormula(lit)
+	}
+	return res
+}
@@ -8,6 +8,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/DoOR-Team/gophersat/encode"
 	"github.com/DoOR-Team/gophersat/solver"
 )
 
@@ -18,11 +19,73 @@ type Formula interface {
 	Eval(model map[string]bool) bool
 }
 
+// A Conversion is a way to translate a formula to CNF.
+type Conversion int
+
+const (
+	// Classic first translates the formula to negation normal form, then to CNF,
+	// by introducing a dummy variable for every conjunction nested in a disjunction.
+	// It can blow up exponentially on formulas containing nested Eq or Xor.
+	Classic Conversion = iota
+	// Tseitin introduces a variable for each distinct subformula, equivalent to it.
+	// Identical subformulas are shared, so the size of the CNF is linear in the size of the formula.
+	Tseitin
+	// PlaistedGreenbaum is like Tseitin, but each variable only implies its subformula, or is
+	// only implied by it, depending on the polarities the subformula appears with.
+	// It generates about half as many clauses as Tseitin.
+	PlaistedGreenbaum
+)
+
+// String returns the name of the conversion.
+func (conv Conversion) String() string {
+	switch conv {
+	case Classic:
+		return "classic"
+	case Tseitin:
+		return "tseitin"
+	case PlaistedGreenbaum:
+		return "pg"
+	default:
+		return fmt.Sprintf("Conversion(%d)", int(conv))
+	}
+}
+
+// ParseConversion returns the conversion whose name is given, as returned by Conversion.String.
+func ParseConversion(name string) (Conversion, error) {
+	for _, conv := range []Conversion{Classic, Tseitin, PlaistedGreenbaum} {
+		if conv.String() == name {
+			return conv, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown conversion %q", name)
+}
+
+// Options describe how a formula is translated before being solved or written.
+type Options struct {
+	Conversion Conversion      // How the formula is translated to CNF
+	Native     bool            // Are cardinality and PB constraints given as is to the solver? Ignored when writing DIMACS.
+	Encoding   encode.Encoding // If not, how they are translated to CNF
+}
+
 // Solve solves the given formula.
 // f is first converted as a CNF formula. It is then given to gophersat.
 // The function returns a model associating each variable name with its binding, or nil if the formula was not satisfiable.
 func Solve(f Formula) map[string]bool {
-	return asCnf(f).solve()
+	return SolveWith(f, Options{Native: true})
+}
+
+// SolveEncoded solves the given formula, after translating it to a pure CNF formula.
+// Cardinality and PB constraints are translated with the given encoding, or with encode.Auto
+// when the encoding cannot deal with weighted constraints.
+// The function returns a model associating each variable name with its binding, or nil if the formula was not satisfiable.
+func SolveEncoded(f Formula, enc encode.Encoding) map[string]bool {
+	return SolveWith(f, Options{Encoding: enc})
+}
+
+// SolveWith solves the given formula, after translating it as described by opts.
+// The function returns a model associating each variable name with its binding, or nil if the formula was not satisfiable.
+func SolveWith(f Formula, opts Options) map[string]bool {
+	return asCnf(f, opts).solve()
 }
 
 // Dimacs writes the DIMACS CNF version of the formula on w.
@@ -31,8 +94,22 @@ func Solve(f Formula) map[string]bool {
 // in comments, between the prolog and the set of clauses.
 // For instance, if the variable "a" is associated with the index 1, there will be a comment line
 // "c a=1".
+// Cardinality and PB constraints are translated with encode.Auto.
 func Dimacs(f Formula, w io.Writer) error {
-	cnf := asCnf(f)
+	return DimacsWith(f, Options{}, w)
+}
+
+// DimacsEncoded writes the DIMACS CNF version of the formula on w, as Dimacs does,
+// but cardinality and PB constraints are translated with the given encoding.
+func DimacsEncoded(f Formula, enc encode.Encoding, w io.Writer) error {
+	return DimacsWith(f, Options{Encoding: enc}, w)
+}
+
+// DimacsWith writes the DIMACS CNF version of the formula on w, as Dimacs does,
+// but the formula is translated as described by opts.
+func DimacsWith(f Formula, opts Options, w io.Writer) error {
+	opts.Native = false
+	cnf := asCnf(f, opts)
 	nbVars := len(cnf.vars.all)
 	nbClauses := len(cnf.clauses)
 	prefix := fmt.Sprintf("p cnf %d %d\n", nbVars, nbClauses)
@@ -178,6 +255,10 @@ func (n not) nnf() Formula {
 		return False
 	case falseConst:
 		return True
+	case leq:
+		return f.negation()
+	case card, pbLeq:
+		return not{f.nnf()}.nnf()
 	default:
 		panic("invalid formula type")
 	}
@@ -215,8 +296,8 @@ func (a and) nnf() Formula {
 	if len(res) == 1 {
 		return res[0]
 	}
-	if len(res) == 0 {
-		return False
+	if len(res) == 0 { // All subformulas were true
+		return True
 	}
 	return res
 }
@@ -267,8 +348,8 @@ func (o or) nnf() Formula {
 	if len(res) == 1 {
 		return res[0]
 	}
-	if len(res) == 0 {
-		return True
+	if len(res) == 0 { // All subformulas were false
+		return False
 	}
 	return res
 }
@@ -410,6 +491,10 @@ func (vars *vars) dummy() int {
 type cnf struct {
 	vars    vars
 	clauses [][]int
+	native  bool              // Are cardinality and PB constraints kept as native solver constraints?
+	enc     encode.Encoding   // If not, how they are translated to CNF
+	pbs     []solver.PBConstr // Native cardinality and PB constraints
+	unused  []variable        // Vars of the formula that were simplified away, and can have any value
 }
 
 // solve solves the given formula.
@@ -417,38 +502,102 @@ type cnf struct {
 // If it is satisfiable, the function returns a model, associating each variable name with its binding.
 // Else, the function returns nil.
 func (cnf *cnf) solve() map[string]bool {
-	pb := solver.ParseSlice(cnf.clauses)
-	s := solver.New(pb)
+	s := solver.New(cnf.problem())
 	if s.Solve() != solver.Sat {
 		return nil
 	}
 	m := s.Model()
 	vars := make(map[string]bool)
 	for v, idx := range cnf.vars.pb {
-		vars[v.name] = m[idx-1]
+		vars[v.name] = idx <= len(m) && m[idx-1]
+	}
+	for _, v := range cnf.unused {
+		vars[v.name] = false
 	}
 	return vars
 }
 
-// asCnf returns a CNF representation of the given formula.
-func asCnf(f Formula) *cnf {
-	vars := vars{all: make(map[variable]int), pb: make(map[variable]int)}
-	clauses := cnfRec(f.nnf(), &vars)
-	return &cnf{vars: vars, clauses: clauses}
+// problem returns the solver problem associated with cnf.
+func (cnf *cnf) problem() *solver.Problem {
+	if cnf.pbs == nil {
+		return solver.ParseSlice(cnf.clauses)
+	}
+	constrs := make([]solver.PBConstr, 0, len(cnf.clauses)+len(cnf.pbs))
+	for _, clause := range cnf.clauses {
+		constrs = append(constrs, solver.PropClause(clause...))
+	}
+	return solver.ParsePBConstrs(append(constrs, cnf.pbs...))
+}
+
+// asCnf returns a CNF representation of the given formula, translated as described by opts.
+func asCnf(f Formula, opts Options) *cnf {
+	res := &cnf{vars: vars{all: make(map[variable]int), pb: make(map[variable]int)}, native: opts.Native, enc: opts.Encoding}
+	switch opts.Conversion {
+	case Tseitin, PlaistedGreenbaum:
+		res.clauses = res.dagClauses(f, opts.Conversion == PlaistedGreenbaum)
+	default:
+		res.clauses = res.cnfRec(Simplify(f))
+		res.setUnused(formulaVars(f, make(map[variable]bool), make(map[dagKey]bool)))
+	}
+	return res
+}
+
+// formulaVars adds the vars appearing in f to vars, and returns it.
+// visited contains the conjunctions and disjunctions that were already visited.
+func formulaVars(f Formula, vars map[variable]bool, visited map[dagKey]bool) map[variable]bool {
+	var subs []Formula
+	switch f := f.(type) {
+	case variable:
+		vars[f] = true
+	case lit:
+		vars[f.v] = true
+	case not:
+		formulaVars(f[0], vars, visited)
+	case and:
+		subs = f
+	case or:
+		subs = f
+	case card:
+		subs = f.subs
+	case pbLeq:
+		subs = f.subs
+	case leq:
+		subs = f.subs
+	}
+	if len(subs) == 0 {
+		return vars
+	}
+	key := dagKey{first: &subs[0], len: len(subs)}
+	if visited[key] {
+		return vars
+	}
+	visited[key] = true
+	for _, sub := range subs {
+		formulaVars(sub, vars, visited)
+	}
+	return vars
+}
+
+// setUnused sets c.unused to the vars of the given set that do not appear in the CNF.
+func (c *cnf) setUnused(vars map[variable]bool) {
+	for v := range vars {
+		if _, ok := c.vars.all[v]; !ok {
+			c.unused = append(c.unused, v)
+		}
+	}
 }
 
 // transforms the f NNF formula into a CNF formula.
-// nbDummies is the current number of dummy variables created.
 // Note: code should be improved, there are a few useless allocs/deallocs
 // here and there.
-func cnfRec(f Formula, vars *vars) [][]int {
+func (c *cnf) cnfRec(f Formula) [][]int {
 	switch f := f.(type) {
 	case lit:
-		return [][]int{{vars.litValue(f)}}
+		return [][]int{{c.vars.litValue(f)}}
 	case and:
 		var res [][]int
 		for _, sub := range f {
-			res = append(res, cnfRec(sub, vars)...)
+			res = append(res, c.cnfRec(sub)...)
 		}
 		return res
 	case or:
@@ -457,26 +606,73 @@ func cnfRec(f Formula, vars *vars) [][]int {
 		for _, sub := range f {
 			switch sub := sub.(type) {
 			case lit:
-				lits = append(lits, vars.litValue(sub))
-			case and:
-				d := vars.dummy()
+				lits = append(lits, c.vars.litValue(sub))
+			case and, leq:
+				d := c.vars.dummy()
 				lits = append(lits, d)
-				for _, sub2 := range sub {
-					cnf := cnfRec(sub2, vars)
-					cnf[0] = append(cnf[0], -d)
-					res = append(res, cnf...)
-				}
+				res = append(res, c.condClauses(sub, d)...)
 			default:
 				panic("unexpected or in or")
 			}
 		}
-		res = append(res, lits)
-		return res
+		lits, ok := simplifyClause(lits)
+		if !ok { // Tautology: dummy vars need not be defined
+			return [][]int{}
+		}
+		return append(res, lits)
+	case leq:
+		return c.leqClauses(f, 0)
 	case trueConst: // True clauses are ignored
 		return [][]int{}
-	case falseConst: // TODO: improve this. This should simply be declared to make the problem UNSAT.
+	case falseConst: // After simplification, only happens when the whole formula is false: the empty clause makes the problem UNSAT.
 		return [][]int{{}}
 	default:
 		panic("invalid NNF formula")
 	}
 }
+
+// condClauses returns the clauses stating the NNF formula f holds when the literal act is true.
+func (c *cnf) condClauses(f Formula, act int) [][]int {
+	switch f := f.(type) {
+	case lit:
+		return [][]int{{c.vars.litValue(f), -act}}
+	case and:
+		var res [][]int
+		for _, sub := range f {
+			res = append(res, c.condClauses(sub, act)...)
+		}
+		return res
+	case or:
+		// Only the last clause must be conditioned by act: previous ones define dummy vars.
+		res := c.cnfRec(f)
+		if len(res) > 0 {
+			res[len(res)-1] = append(res[len(res)-1], -act)
+		}
+		return res
+	case leq:
+		return c.leqClauses(f, act)
+	case trueConst:
+		return [][]int{}
+	case falseConst:
+		return [][]int{{-act}}
+	default:
+		panic("invalid NNF formula")
+	}
+}
+
+// simplifyClause removes duplicate lits from the given clause.
+// It returns false if the clause is a tautology, i.e it contains both a lit and its negation.
+func simplifyClause(lits []int) ([]int, bool) {
+	res := lits[:0]
+	seen := make(map[int]bool, len(lits))
+	for _, lit := range lits {
+		if seen[-lit] {
+			return nil, false
+		}
+		if !seen[lit] {
+			seen[lit] = true
+			res = append(res, lit)
+		}
+	}
+	return res, true
+}
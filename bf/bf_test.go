@@ -2,8 +2,12 @@ package bf
 
 import (
 	"fmt"
+	"math/rand"
 	"os"
 	"testing"
+
+	"github.com/DoOR-Team/gophersat/encode"
+	"github.com/DoOR-Team/gophersat/solver"
 )
 
 func TestIdentityAnd(t *testing.T) {
@@ -116,15 +120,11 @@ func ExampleDimacs() {
 		fmt.Printf("Could not generate DIMACS file: %v", err)
 	}
 	// Output:
-	// p cnf 4 6
-	// c a=2
-	// c b=3
-	// -2 -1 0
-	// 3 -1 0
-	// 1 2 3 0
-	// 2 -3 -4 0
-	// -2 -4 0
-	// 4 -3 0
+	// p cnf 2 2
+	// c a=1
+	// c b=2
+	// 1 2 0
+	// -2 0
 }
 
 func ExampleSolve_sudoku() {
@@ -317,3 +317,315 @@ func TestPanic2(t *testing.T) {
 		t.Errorf("should be exactly two vars")
 	}
 }
+
+var cardVars = []string{"a", "b", "c", "d", "e"}
+
+// randomCardFormula returns a random formula made of cardinality and PB constraints on random subformulas.
+func randomCardFormula(r *rand.Rand, depth int) Formula {
+	if depth == 0 || r.Intn(4) == 0 {
+		f := Var(cardVars[r.Intn(len(cardVars))])
+		if r.Intn(2) == 0 {
+			f = Not(f)
+		}
+		return f
+	}
+	subs := make([]Formula, 1+r.Intn(4))
+	for i := range subs {
+		subs[i] = randomCardFormula(r, depth-1)
+	}
+	k := r.Intn(len(subs)+2) - 1
+	switch r.Intn(7) {
+	case 0:
+		return AtMost(k, subs...)
+	case 1:
+		return AtLeast(k, subs...)
+	case 2:
+		return Exactly(k, subs...)
+	case 3:
+		weights := make([]int, len(subs))
+		for i := range weights {
+			weights[i] = r.Intn(9) - 3
+		}
+		return PBLeq(r.Intn(10)-2, weights, subs...)
+	case 4:
+		return Not(AtMost(k, subs...))
+	case 5:
+		return Or(subs...)
+	default:
+		return And(subs...)
+	}
+}
+
+// isSat returns true iff f has a model on cardVars.
+func isSat(f Formula) bool {
+	model := make(map[string]bool)
+	for m := 0; m < 1<<uint(len(cardVars)); m++ {
+		for i, v := range cardVars {
+			model[v] = m&(1<<uint(i)) != 0
+		}
+		if f.Eval(model) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCardConstraints(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 500; iter++ {
+		f := randomCardFormula(r, 3)
+		sat := isSat(f)
+		models := []map[string]bool{Solve(f)}
+		for _, enc := range []encode.Encoding{encode.Auto, encode.SeqCounter, encode.Totalizer, encode.CardNetwork, encode.Adder, encode.BDD, encode.GTE} {
+			models = append(models, SolveEncoded(f, enc))
+		}
+		for i, model := range models {
+			if (model != nil) != sat {
+				t.Fatalf("iter %d, solver %d: %v: expected sat=%t, got model %v", iter, i, f, sat, model)
+			}
+			if model != nil {
+				for _, v := range cardVars { // Vars that do not appear in the CNF can have any value
+					if _, ok := model[v]; !ok {
+						model[v] = false
+					}
+				}
+				if !f.Eval(model) {
+					t.Fatalf("iter %d, solver %d: %v: invalid model %v", iter, i, f, model)
+				}
+			}
+		}
+	}
+}
+
+// TestEncoder checks random formulas, renamed, and their negations, one after the other with a single encoder.
+func TestEncoder(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	enc := NewEncoder()
+	for iter := 0; iter < 500; iter++ {
+		f := randomCardFormula(r, 3)
+		g := Rename(f, func(name string) string { return "x" + name })
+		for _, neg := range []bool{false, true} {
+			lit := enc.Lit(g)
+			expected := f
+			if neg {
+				lit, expected = lit.Negation(), Not(f)
+			}
+			sat := enc.Solver.SolveAssumptions([]solver.Lit{lit}) == solver.Sat
+			if sat != isSat(expected) {
+				t.Fatalf("iter %d: %v: expected sat=%t, got %t", iter, expected, !sat, sat)
+			}
+			if sat {
+				model := enc.Model()
+				for _, v := range cardVars {
+					model[v] = model["x"+v]
+				}
+				if !expected.Eval(model) {
+					t.Fatalf("iter %d: %v: invalid model %v", iter, expected, model)
+				}
+			}
+		}
+	}
+}
+
+func ExampleAtMost() {
+	f := And(AtMost(1, Var("a"), Var("b"), Var("c")), AtLeast(1, Var("a"), Var("b")), Not(Var("a")))
+	model := Solve(f)
+	fmt.Printf("a=%t, b=%t, c=%t", model["a"], model["b"], model["c"])
+	// Output: a=false, b=true, c=false
+}
+
+func TestConversions(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 300; iter++ {
+		f1, f2 := randomCardFormula(r, 2), randomCardFormula(r, 2)
+		var f Formula
+		switch r.Intn(4) {
+		case 0:
+			f = Eq(f1, f2)
+		case 1:
+			f = Xor(f1, Not(f2))
+		case 2:
+			f = Implies(f1, And(f2, f1))
+		default:
+			f = Or(And(f1, True), Eq(f2, False))
+		}
+		sat := isSat(f)
+		for _, conv := range []Conversion{Classic, Tseitin, PlaistedGreenbaum} {
+			for _, native := range []bool{true, false} {
+				model := SolveWith(f, Options{Conversion: conv, Native: native})
+				if (model != nil) != sat {
+					t.Fatalf("iter %d, %v, native=%t: %v: expected sat=%t, got model %v", iter, conv, native, f, sat, model)
+				}
+				if model != nil {
+					for _, v := range cardVars {
+						if _, ok := model[v]; !ok {
+							model[v] = false
+						}
+					}
+					if !f.Eval(model) {
+						t.Fatalf("iter %d, %v, native=%t: %v: invalid model %v", iter, conv, native, f, model)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestDeepEq(t *testing.T) {
+	const depth = 100
+	f := Var("x0")
+	for i := 1; i < depth; i++ {
+		f = Eq(f, Var(fmt.Sprintf("x%d", i)))
+	}
+	f = And(f, Not(Var("x0")))
+	for _, conv := range []Conversion{Tseitin, PlaistedGreenbaum} {
+		model := SolveWith(f, Options{Conversion: conv})
+		if model == nil {
+			t.Fatalf("%v: expected a model", conv)
+		}
+		val := model["x0"]
+		for i := 1; i < depth; i++ {
+			val = val == model[fmt.Sprintf("x%d", i)]
+		}
+		if !val || model["x0"] {
+			t.Errorf("%v: invalid model %v", conv, model)
+		}
+		cnf := asCnf(f, Options{Conversion: conv})
+		if nb := len(cnf.clauses); nb > 10*depth {
+			t.Errorf("%v: too many clauses: %d", conv, nb)
+		}
+	}
+}
+
+func TestSharing(t *testing.T) {
+	// Both subformulas are identical, so they should be translated only once.
+	f := Or(And(Var("a"), Or(Var("b"), Var("c"))), And(Var("d"), Not(And(Or(Var("c"), Var("b")), Var("a")))))
+	cnf := asCnf(f, Options{Conversion: PlaistedGreenbaum})
+	// 4 vars, and a dummy var for b|c, for a&(b|c) and for d&^(a&(b|c)).
+	if nb := len(cnf.vars.all); nb != 7 {
+		t.Errorf("expected 7 vars, got %d: %v", nb, cnf.clauses)
+	}
+}
+
+func ExampleDimacsWith() {
+	f := Or(And(Var("a"), Var("b")), Not(Var("c")))
+	if err := DimacsWith(f, Options{Conversion: PlaistedGreenbaum}, os.Stdout); err != nil {
+		fmt.Printf("could not create DIMACS file: %v", err)
+	}
+	// Output:
+	// p cnf 4 3
+	// c a=1
+	// c b=2
+	// c c=4
+	// -3 1 0
+	// -3 2 0
+	// 3 -4 0
+}
+
+func TestSimplify(t *testing.T) {
+	tests := []struct {
+		f        Formula
+		expected string
+	}{
+		{And(Var("a"), True, Or(False, Var("b"))), "and(a, b)"},
+		{Or(Var("a"), And(Var("b"), False)), "a"},
+		{And(Var("a"), And(Var("b"), Var("c")), Var("a")), "and(a, b, c)"},
+		{Or(Var("a"), Var("b"), Not(Var("a"))), "⊤"},
+		{And(Var("a"), Or(Not(Var("a")), Var("b"))), "and(a, b)"},
+		{Or(Not(Var("a")), And(Var("a"), Var("b"))), "or(not(a), b)"},
+		{And(Or(Var("a"), Var("b")), Or(Var("b"), Var("c"), Var("a"))), "or(a, b)"},
+		{And(Or(Var("a"), And(Var("b"), Var("c"))), Or(And(Var("c"), Var("b")), Var("a"))), "or(a, and(b, c))"},
+		{And(Eq(Var("a"), Var("b")), Var("a")), "and(b, a)"},
+		{And(Var("a"), AtMost(1, Var("a"), Var("b"), Var("c"))), "and(a, not(b), not(c))"},
+		{Or(Var("a"), AtLeast(2, Var("a"), Var("b"), And(Var("b"), Var("c")))), "or(a, and(b, c))"},
+		{And(Var("a"), Not(Var("a"))), "⊥"},
+	}
+	for _, test := range tests {
+		if got := Simplify(test.f).String(); got != test.expected {
+			t.Errorf("%v: expected %s, got %s", test.f, test.expected, got)
+		}
+	}
+}
+
+func TestSimplifyEquivalence(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 1000; iter++ {
+		f := randomCardFormula(r, 3)
+		if r.Intn(2) == 0 {
+			f = Eq(f, randomCardFormula(r, 2))
+		}
+		simplified := Simplify(f)
+		model := make(map[string]bool)
+		for m := 0; m < 1<<uint(len(cardVars)); m++ {
+			for i, v := range cardVars {
+				model[v] = m&(1<<uint(i)) != 0
+			}
+			if f.Eval(model) != simplified.Eval(model) {
+				t.Fatalf("iter %d: %v and %v differ on model %v", iter, f, simplified, model)
+			}
+		}
+	}
+}
+
+func TestSolveFalse(t *testing.T) {
+	for _, f := range []Formula{False, And(Var("a"), Or(Not(Var("a")), False), Var("b"))} {
+		for _, conv := range []Conversion{Classic, Tseitin, PlaistedGreenbaum} {
+			if model := SolveWith(f, Options{Conversion: conv, Native: true}); model != nil {
+				t.Errorf("%v, %v: expected no model, got %v", f, conv, model)
+			}
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	allVars := make([]Formula, len(cardVars))
+	for i, v := range cardVars {
+		allVars[i] = Var(v)
+	}
+	for iter := 0; iter < 200; iter++ {
+		// All vars appear in the formula, even if some of them are simplified away.
+		f := And(randomCardFormula(r, 3), Or(append(allVars, True)...))
+		expected := 0
+		model := make(map[string]bool)
+		for m := 0; m < 1<<uint(len(cardVars)); m++ {
+			for i, v := range cardVars {
+				model[v] = m&(1<<uint(i)) != 0
+			}
+			if f.Eval(model) {
+				expected++
+			}
+		}
+		if nb := Count(f); nb != expected {
+			t.Fatalf("iter %d: %v: expected %d models, got %d", iter, f, expected, nb)
+		}
+		models := make(chan map[string]bool)
+		go Models(f, models, nil)
+		seen := make(map[string]bool)
+		for model := range models {
+			if len(model) != len(cardVars) {
+				t.Fatalf("iter %d: %v: invalid model %v", iter, f, model)
+			}
+			if !f.Eval(model) {
+				t.Fatalf("iter %d: %v: model %v does not satisfy formula", iter, f, model)
+			}
+			key := fmt.Sprint(model)
+			if seen[key] {
+				t.Fatalf("iter %d: %v: model %v found twice", iter, f, model)
+			}
+			seen[key] = true
+		}
+		if len(seen) != expected {
+			t.Fatalf("iter %d: %v: expected %d models, got %d", iter, f, expected, len(seen))
+		}
+	}
+}
+
+func ExampleCount() {
+	// Unique creates dummy variables, but they are not taken into account
+	fmt.Println(Count(Unique("a", "b", "c", "d", "e", "f")))
+	fmt.Println(Count(Or(Var("a"), Var("b"))))
+	// Output:
+	// 6
+	// 3
+}
@@ -0,0 +1,399 @@
+package bf
+
+import (
+	"fmt"
+	"sort"
+)
+
+// A dag is a representation of a formula where identical subformulas are shared.
+// Nodes are referred to by literals: the node at index i is associated with the literal i+1,
+// and its negation with -(i+1). Disjunctions are represented as negated conjunctions.
+type dag struct {
+	nodes  []dagNode
+	pol    []byte           // Polarities each node appears with, once computed
+	hashes map[string]int   // Literal of each and/leq node, indexed by its structure
+	vars   map[variable]int // Literal of each variable
+	memo   map[dagKey]int   // Literal of each and/or formula already translated
+}
+
+type nodeKind byte
+
+const (
+	nodeTrue nodeKind = iota
+	nodeVar
+	nodeAnd
+	nodeLeq
+)
+
+// Polarities of a node.
+const (
+	posPol byte = 1 << iota
+	negPol
+)
+
+// The literal associated with the constant true.
+const dagTrue = 1
+
+type dagNode struct {
+	kind     nodeKind
+	v        variable // For nodeVar
+	children []int    // For nodeAnd and nodeLeq
+	weights  []int    // For nodeLeq, all strictly positive
+	k        int      // For nodeLeq: sum(weights[i].children[i]) <= k
+}
+
+// A dagKey identifies a conjunction or a disjunction in the original formula.
+// As subformulas can be shared in memory, this avoids traversing them several times.
+type dagKey struct {
+	and   bool
+	first *Formula
+	len   int
+}
+
+func newDag() *dag {
+	return &dag{
+		nodes:  []dagNode{{kind: nodeTrue}},
+		hashes: make(map[string]int),
+		vars:   make(map[variable]int),
+		memo:   make(map[dagKey]int),
+	}
+}
+
+// node returns the literal associated with n, creating it if it does not exist yet.
+func (d *dag) node(n dagNode) int {
+	key := fmt.Sprint(n.kind, n.k, n.children, n.weights)
+	if l, ok := d.hashes[key]; ok {
+		return l
+	}
+	d.nodes = append(d.nodes, n)
+	d.hashes[key] = len(d.nodes)
+	return len(d.nodes)
+}
+
+func (d *dag) varLit(v variable) int {
+	if l, ok := d.vars[v]; ok {
+		return l
+	}
+	d.nodes = append(d.nodes, dagNode{kind: nodeVar, v: v})
+	d.vars[v] = len(d.nodes)
+	return len(d.nodes)
+}
+
+// lit returns the literal associated with f.
+func (d *dag) lit(f Formula) int {
+	switch f := f.(type) {
+	case variable:
+		return d.varLit(f)
+	case lit:
+		if f.signed {
+			return -d.varLit(f.v)
+		}
+		return d.varLit(f.v)
+	case not:
+		return -d.lit(f[0])
+	case and:
+		return d.andLit(f, true)
+	case or: // or(subs) = not(and(not(subs)))
+		return -d.andLit(f, false)
+	case trueConst:
+		return dagTrue
+	case falseConst:
+		return -dagTrue
+	case card:
+		lits := d.lits(f.subs)
+		ones := make([]int, len(lits))
+		for i := range ones {
+			ones[i] = 1
+		}
+		atMost := d.leq(lits, ones, f.k)
+		atLeast := d.leq(negatedInts(lits), ones, len(lits)-f.k)
+		switch f.op {
+		case "atmost":
+			return atMost
+		case "atleast":
+			return atLeast
+		default:
+			return d.and([]int{atMost, atLeast})
+		}
+	case pbLeq:
+		return d.leq(d.lits(f.subs), f.weights, f.k)
+	case leq:
+		return d.leq(d.lits(f.subs), f.weights, f.k)
+	default:
+		panic("invalid formula type")
+	}
+}
+
+func (d *dag) lits(subs []Formula) []int {
+	res := make([]int, len(subs))
+	for i, sub := range subs {
+		res[i] = d.lit(sub)
+	}
+	return res
+}
+
+// andLit returns the literal associated with the conjunction of subs,
+// or of their negations if isAnd is false.
+func (d *dag) andLit(subs []Formula, isAnd bool) int {
+	if len(subs) == 0 {
+		return dagTrue
+	}
+	key := dagKey{and: isAnd, first: &subs[0], len: len(subs)}
+	if l, ok := d.memo[key]; ok {
+		return l
+	}
+	lits := d.lits(subs)
+	if !isAnd {
+		lits = negatedInts(lits)
+	}
+	res := d.and(lits)
+	d.memo[key] = res
+	return res
+}
+
+// and returns the literal associated with the conjunction of the given literals.
+// Constants are folded, and conjunctions in the conjunction are flattened.
+func (d *dag) and(lits []int) int {
+	var children []int
+	seen := make(map[int]bool)
+	add := func(l int) bool {
+		if seen[-l] {
+			return false
+		}
+		if !seen[l] {
+			seen[l] = true
+			children = append(children, l)
+		}
+		return true
+	}
+	for _, l := range lits {
+		switch {
+		case l == dagTrue:
+		case l == -dagTrue:
+			return -dagTrue
+		case l > 0 && d.nodes[l-1].kind == nodeAnd:
+			for _, child := range d.nodes[l-1].children {
+				if !add(child) {
+					return -dagTrue
+				}
+			}
+		default:
+			if !add(l) {
+				return -dagTrue
+			}
+		}
+	}
+	switch len(children) {
+	case 0:
+		return dagTrue
+	case 1:
+		return children[0]
+	}
+	sort.Ints(children)
+	return d.node(dagNode{kind: nodeAnd, children: children})
+}
+
+// leq returns the literal associated with the constraint sum(weights[i].lits[i]) <= k.
+// Weights can be negative.
+func (d *dag) leq(lits, weights []int, k int) int {
+	coeffs := make(map[int]int) // For each node, the weight of its positive literal
+	var order []int             // Nodes, in the order they appeared
+	for i, l := range lits {
+		w := weights[i]
+		switch {
+		case l == dagTrue:
+			k -= w
+			continue
+		case l == -dagTrue:
+			continue
+		case l < 0: // w.not(l) = w - w.l
+			l, w = -l, -w
+			k -= weights[i]
+		}
+		if _, ok := coeffs[l]; !ok {
+			order = append(order, l)
+		}
+		coeffs[l] += w
+	}
+	sort.Ints(order)
+	var n dagNode
+	sum := 0
+	for _, l := range order {
+		w := coeffs[l]
+		if w < 0 { // w.l = w + (-w).not(l)
+			l, w = -l, -w
+			k += w
+		}
+		if w != 0 {
+			n.children = append(n.children, l)
+			n.weights = append(n.weights, w)
+			sum += w
+		}
+	}
+	switch {
+	case k < 0:
+		return -dagTrue
+	case sum <= k:
+		return dagTrue
+	}
+	n.kind, n.k = nodeLeq, k
+	return d.node(n)
+}
+
+// assert adds the clauses and the PB constraints stating the literal l is true.
+// Conjunctions are split, and disjunctions are written as clauses, so they do not need a dummy variable.
+func (d *dag) assert(l int, clauses *[][]int, leqs *[]int) {
+	if l == dagTrue {
+		return
+	}
+	if l == -dagTrue {
+		*clauses = append(*clauses, []int{})
+		return
+	}
+	switch n := d.nodes[abs(l)-1]; {
+	case n.kind == nodeAnd && l > 0:
+		for _, child := range n.children {
+			d.assert(child, clauses, leqs)
+		}
+	case n.kind == nodeAnd:
+		*clauses = append(*clauses, negatedInts(n.children))
+	case n.kind == nodeLeq:
+		*leqs = append(*leqs, l)
+	default:
+		*clauses = append(*clauses, []int{l})
+	}
+}
+
+// need indicates the literal l must be defined.
+// If both is true, both polarities of its node must be defined.
+func (d *dag) need(l int, both bool) {
+	p := posPol
+	if l < 0 {
+		p = negPol
+	}
+	if both {
+		p = posPol | negPol
+	}
+	idx := abs(l) - 1
+	if newPol := p &^ d.pol[idx]; newPol != 0 {
+		d.pol[idx] |= newPol
+		d.needChildren(idx, newPol, both)
+	}
+}
+
+// needChildren indicates the children of the given node must be defined, for the given polarity of the node.
+func (d *dag) needChildren(idx int, p byte, both bool) {
+	n := d.nodes[idx]
+	for _, child := range n.children {
+		if n.kind == nodeLeq { // The constraint is falsified by children being true
+			child = -child
+		}
+		if p&posPol != 0 {
+			d.need(child, both)
+		}
+		if p&negPol != 0 {
+			d.need(-child, both)
+		}
+	}
+}
+
+// dagClauses returns the clauses associated with f, thanks to a Tseitin transformation,
+// or a Plaisted-Greenbaum one if pg is true.
+func (c *cnf) dagClauses(f Formula, pg bool) [][]int {
+	d := newDag()
+	var top [][]int
+	var leqs []int
+	d.assert(d.lit(f), &top, &leqs)
+	d.pol = make([]byte, len(d.nodes))
+	for _, clause := range top {
+		for _, l := range clause {
+			d.need(l, !pg)
+		}
+	}
+	for _, l := range leqs {
+		p := posPol
+		if l < 0 {
+			p = negPol
+		}
+		d.needChildren(abs(l)-1, p, !pg)
+	}
+	indices := make([]int, len(d.nodes))
+	for i, n := range d.nodes {
+		switch {
+		case d.pol[i] == 0:
+		case n.kind == nodeVar:
+			indices[i] = c.vars.litValue(lit{v: n.v})
+		default:
+			indices[i] = c.vars.dummy()
+		}
+	}
+	toInts := func(lits []int) []int {
+		res := make([]int, len(lits))
+		for i, l := range lits {
+			res[i] = indices[abs(l)-1]
+			if l < 0 {
+				res[i] = -res[i]
+			}
+		}
+		return res
+	}
+	var res [][]int
+	for i, n := range d.nodes {
+		o := indices[i]
+		switch {
+		case d.pol[i] == 0:
+		case n.kind == nodeAnd:
+			children := toInts(n.children)
+			if d.pol[i]&posPol != 0 { // o -> and(children)
+				for _, child := range children {
+					res = append(res, []int{-o, child})
+				}
+			}
+			if d.pol[i]&negPol != 0 { // and(children) -> o
+				res = append(res, append(negatedInts(children), o))
+			}
+		case n.kind == nodeLeq:
+			if d.pol[i]&posPol != 0 {
+				res = append(res, c.pbClauses(toInts(n.children), n.weights, n.k, o)...)
+			}
+			if d.pol[i]&negPol != 0 {
+				res = append(res, c.leqNegation(toInts(n.children), n.weights, n.k, -o)...)
+			}
+		}
+	}
+	for _, clause := range top {
+		res = append(res, toInts(clause))
+	}
+	for _, l := range leqs {
+		n := d.nodes[abs(l)-1]
+		if l > 0 {
+			res = append(res, c.pbClauses(toInts(n.children), n.weights, n.k, 0)...)
+		} else {
+			res = append(res, c.leqNegation(toInts(n.children), n.weights, n.k, 0)...)
+		}
+	}
+	vars := make(map[variable]bool, len(d.vars))
+	for v := range d.vars {
+		vars[v] = true
+	}
+	c.setUnused(vars)
+	return res
+}
+
+// leqNegation returns the clauses stating sum(weights[i].lits[i]) > k, where all weights are positive.
+// If act is not 0, the constraint only holds when the literal act is true.
+func (c *cnf) leqNegation(lits, weights []int, k, act int) [][]int {
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	// sum(w.l) >= k+1 iff sum(w.not(l)) <= sum(w) - k - 1
+	return c.pbClauses(negatedInts(lits), weights, sum-k-1, act)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
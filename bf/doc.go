@@ -23,6 +23,9 @@
 //
 // Note that this formula is longer than the original one and that some variables were added to it.
 // The translation is both polynomial in time and space.
+// However, it can blow up on deeply nested equivalences. SolveWith and DimacsWith can then use
+// the Tseitin or the Plaisted-Greenbaum transformation instead: identical subformulas are shared,
+// and the size of the CNF is always linear in the size of the formula.
 // When fed this CNF as an input, gophersat then returns the following map:
 //
 //     map[a:true b:true c:false d:true e:false]
@@ -30,12 +33,15 @@
 // It is also possible to create boolean formulas using a dedicated syntax. The BNF grammar is as follows:
 //
 //    formula ::= clause { ';' clause }*
-//    clause  ::= implies { '=' implies }*
-//    implies ::= or { '->' or}*
+//    clause  ::= 'let' ident ':=' equiv | equiv
+//    equiv   ::= implies { ('=' | '<->' | 'xor' | '!=') implies }*
+//    implies ::= or { ('->' | '<-') or}*
 //    or      ::= and { '|' and}*
 //    and     ::= not { '&' not}*
 //    not     ::= '^'not | atom
-//    atom    ::= ident | '(' formula ')'
+//    atom    ::= ident | string | 'true' | 'false' | '(' equiv ')' | '{' ident { ',' ident }* '}' | card | pb
+//    card    ::= ('atmost' | 'atleast' | 'exactly') '(' int { ',' equiv }* ')'
+//    pb      ::= 'pbleq' '(' int { ',' int '*' equiv }* ')'
 //
 // So the formula
 //
@@ -46,4 +52,19 @@
 //    ^(a & b) -> ((c | ^d) & ^(c & (e = ^c)) & ^(a = ^b))
 //
 // a call to the `Parse` function will then create the associated Formula.
+//
+// Comments start with '#' or '//' and run until the end of the line.
+// A 'let' clause defines a name that can then be used in place of the formula it stands for:
+//
+//    # Rules of the ferry
+//    let alone := ^(farmer = goat);
+//    alone -> ^(goat = cabbage) & ^(goat = wolf)
+//
+// Conversely, the `Format` function writes a formula in that syntax, with as few parentheses as possible,
+// so that it can be read back by `Parse`. It can also write formulas as S-expressions or JSON documents,
+// that are read back by `ParseSExpr` and `ParseJSON`.
+//
+// To solve several related formulas, an Encoder adds them one after the other to a single incremental solver,
+// variables with the same name being the same variable in all of them. `Rename` helps building copies of
+// a formula on other variables, e.g to unroll a transition system.
 package bf
@@ -0,0 +1,225 @@
+package bf
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A Style is a way to write a formula.
+type Style int
+
+const (
+	// Text writes the formula on a single line, using the syntax accepted by Parse.
+	Text Style = iota
+	// Lines is like Text, but each clause of the top-level conjunction is written on its own line.
+	Lines
+	// SExpr writes the formula as an S-expression, that can be read back with ParseSExpr.
+	SExpr
+	// JSON writes the formula as a JSON document, that can be read back with ParseJSON.
+	JSON
+)
+
+// String returns the name of the style.
+func (style Style) String() string {
+	switch style {
+	case Text:
+		return "text"
+	case Lines:
+		return "lines"
+	case SExpr:
+		return "sexpr"
+	case JSON:
+		return "json"
+	default:
+		return fmt.Sprintf("Style(%d)", int(style))
+	}
+}
+
+// Format writes f to w, in the given style, followed by a newline.
+// In the Text and Lines styles, only the parentheses that are needed are written, and names of variables that are not
+// identifiers are written as quoted strings.
+// Reading the output back yields an equivalent formula, that is written exactly the same way.
+// Dummy variables, as created by Unique, are written as regular variables in the Text and Lines styles.
+func Format(f Formula, w io.Writer, style Style) error {
+	var b strings.Builder
+	switch style {
+	case Text:
+		writeText(&b, f, 0)
+	case Lines:
+		clauses := topClauses(f, nil)
+		if len(clauses) == 0 {
+			clauses = []Formula{True}
+		}
+		for i, clause := range clauses {
+			if i != 0 {
+				b.WriteString(";\n")
+			}
+			writeText(&b, clause, 0)
+		}
+	case SExpr:
+		writeSExpr(&b, f)
+	case JSON:
+		data, err := JSONFormula{f}.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		b.Write(data)
+	default:
+		return fmt.Errorf("unknown style %v", style)
+	}
+	b.WriteByte('\n')
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("could not write formula: %v", err)
+	}
+	return nil
+}
+
+// topClauses appends the conjuncts of f to clauses, nested conjunctions being flattened.
+func topClauses(f Formula, clauses []Formula) []Formula {
+	if a, ok := unwrap(f).(and); ok {
+		for _, sub := range a {
+			clauses = topClauses(sub, clauses)
+		}
+		return clauses
+	}
+	return append(clauses, f)
+}
+
+// Priorities of the operators, as used by Parse.
+const (
+	prioOr = iota + 1
+	prioAnd
+	prioNot
+	prioAtom
+)
+
+// priority returns the priority of the main operator of f, once written.
+// Conjunctions and disjunctions of a single subformula are not considered, as only their subformula is written.
+func priority(f Formula) int {
+	switch f := f.(type) {
+	case and:
+		if len(f) != 0 {
+			return prioAnd
+		}
+	case or:
+		if len(f) != 0 {
+			return prioOr
+		}
+	case not:
+		return prioNot
+	case lit:
+		if f.signed {
+			return prioNot
+		}
+	}
+	return prioAtom
+}
+
+// writeText writes f in the syntax accepted by Parse.
+// f is surrounded by parentheses if its priority is lower than prio.
+func writeText(b *strings.Builder, f Formula, prio int) {
+	f = unwrap(f)
+	if priority(f) < prio {
+		b.WriteByte('(')
+		writeText(b, f, 0)
+		b.WriteByte(')')
+		return
+	}
+	switch f := f.(type) {
+	case trueConst:
+		b.WriteString("true")
+	case falseConst:
+		b.WriteString("false")
+	case variable:
+		b.WriteString(quoteName(f.name))
+	case lit:
+		if f.signed {
+			b.WriteByte('^')
+		}
+		b.WriteString(quoteName(f.v.name))
+	case not:
+		b.WriteByte('^')
+		writeText(b, f[0], prioNot)
+	case and:
+		writeJunction(b, f, " & ", "true", prioAnd)
+	case or:
+		writeJunction(b, f, " | ", "false", prioOr)
+	case card:
+		b.WriteString(f.op + "(" + strconv.Itoa(f.k))
+		for _, sub := range f.subs {
+			b.WriteString(", ")
+			writeText(b, sub, 0)
+		}
+		b.WriteByte(')')
+	case pbLeq:
+		writePBText(b, f.k, f.weights, f.subs)
+	case leq:
+		writePBText(b, f.k, f.weights, f.subs)
+	default:
+		panic("invalid formula type")
+	}
+}
+
+// unwrap returns the only subformula of f if f is a conjunction or a disjunction of a single subformula,
+// recursively, or f itself else.
+func unwrap(f Formula) Formula {
+	for {
+		switch sub := f.(type) {
+		case and:
+			if len(sub) == 1 {
+				f = sub[0]
+				continue
+			}
+		case or:
+			if len(sub) == 1 {
+				f = sub[0]
+				continue
+			}
+		}
+		return f
+	}
+}
+
+func writeJunction(b *strings.Builder, subs []Formula, op, empty string, prio int) {
+	if len(subs) == 0 {
+		b.WriteString(empty)
+		return
+	}
+	for i, sub := range subs {
+		if i != 0 {
+			b.WriteString(op)
+		}
+		writeText(b, sub, prio)
+	}
+}
+
+func writePBText(b *strings.Builder, k int, weights []int, subs []Formula) {
+	b.WriteString("pbleq(" + strconv.Itoa(k))
+	for i, sub := range subs {
+		b.WriteString(", " + strconv.Itoa(weights[i]) + "*")
+		writeText(b, sub, 0)
+	}
+	b.WriteByte(')')
+}
+
+// quoteName returns the name as is if it can be read back as a variable name, or as a quoted string else.
+func quoteName(name string) string {
+	if isIdent(name) && token.Lookup(name) == token.IDENT && !isKeyword(name) {
+		return name
+	}
+	return strconv.Quote(name)
+}
+
+// isIdent returns true iff name is read as a single identifier by the scanner.
+func isIdent(name string) bool {
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
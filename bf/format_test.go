@@ -0,0 +1,162 @@
+package bf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+)
+
+var formatTests = []struct {
+	f        Formula
+	expected string
+}{
+	{And(Var("a"), Or(Var("b"), Var("c"))), "a & (b | c)"},
+	{Or(And(Var("a"), Var("b")), Not(Var("c"))), "a & b | ^c"},
+	{And(Var("a"), And(Var("b"), Var("c"))), "a & b & c"},
+	{Not(Or(Var("a"), Not(Var("b")))), "^(a | ^b)"},
+	{Implies(Var("a"), Var("b")), "^a | b"},
+	{And(), "true"},
+	{Or(False, Var("true")), "false | \"true\""},
+	{Var("x#0"), "\"x#0\""},
+	{Or(And(Var("a"))), "a"},
+	{AtMost(1, Var("a"), Or(Var("b"), Var("c"))), "atmost(1, a, b | c)"},
+	{PBLeq(2, []int{3, -1}, Var("a"), Not(Var("b"))), "pbleq(2, 3*a, -1*^b)"},
+	{Simplify(Not(And(Var("a"), Var("b")))), "^a | ^b"},
+}
+
+func TestFormat(t *testing.T) {
+	for _, test := range formatTests {
+		var b strings.Builder
+		if err := Format(test.f, &b, Text); err != nil {
+			t.Fatalf("could not format %v: %v", test.f, err)
+		}
+		if got := strings.TrimSuffix(b.String(), "\n"); got != test.expected {
+			t.Errorf("for formula %v, expected %q, got %q", test.f, test.expected, got)
+		}
+	}
+}
+
+// parseStyle parses a formula written with the given style.
+func parseStyle(s string, style Style) (Formula, error) {
+	switch style {
+	case SExpr:
+		return ParseSExpr(strings.NewReader(s))
+	case JSON:
+		return ParseJSON(strings.NewReader(s))
+	default:
+		return Parse(strings.NewReader(s))
+	}
+}
+
+// equivalent returns true iff f1 and f2 have the same value on all assignments of cardVars.
+func equivalent(f1, f2 Formula) bool {
+	model := make(map[string]bool)
+	for m := 0; m < 1<<uint(len(cardVars)); m++ {
+		for i, v := range cardVars {
+			model[v] = m&(1<<uint(i)) != 0
+		}
+		if f1.Eval(model) != f2.Eval(model) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 300; iter++ {
+		f := randomCardFormula(r, 4)
+		if iter%3 == 0 {
+			f = Simplify(f)
+		}
+		for _, style := range []Style{Text, Lines, SExpr, JSON} {
+			var b1 bytes.Buffer
+			if err := Format(f, &b1, style); err != nil {
+				t.Fatalf("iter %d: could not format %v as %v: %v", iter, f, style, err)
+			}
+			f2, err := parseStyle(b1.String(), style)
+			if err != nil {
+				t.Fatalf("iter %d: could not parse %q as %v: %v", iter, b1.String(), style, err)
+			}
+			if !equivalent(f, f2) {
+				t.Fatalf("iter %d: %v and %v, read as %v, are not equivalent", iter, f, f2, style)
+			}
+			var b2 bytes.Buffer
+			if err := Format(f2, &b2, style); err != nil {
+				t.Fatalf("iter %d: could not format %v as %v: %v", iter, f2, style, err)
+			}
+			if b1.String() != b2.String() {
+				t.Errorf("iter %d: %v: formula written as %q, then as %q", iter, style, b1.String(), b2.String())
+			}
+		}
+	}
+}
+
+func TestStructuredRoundTrip(t *testing.T) {
+	f := And(Unique("a", "b", "c", "d", "e", "f"), PBLeq(3, []int{2, 2}, Var("a b"), And()))
+	for _, style := range []Style{SExpr, JSON} {
+		var b strings.Builder
+		if err := Format(f, &b, style); err != nil {
+			t.Fatalf("could not format %v as %v: %v", f, style, err)
+		}
+		f2, err := parseStyle(b.String(), style)
+		if err != nil {
+			t.Fatalf("could not parse %q as %v: %v", b.String(), style, err)
+		}
+		if f.String() != f2.String() {
+			t.Errorf("%v: expected %v, got %v", style, f, f2)
+		}
+		if m1, m2 := Solve(f), Solve(f2); fmt.Sprint(m1) != fmt.Sprint(m2) {
+			t.Errorf("%v: expected model %v, got %v", style, m1, m2)
+		}
+	}
+}
+
+func TestParseStructuredErrors(t *testing.T) {
+	for _, s := range []string{"(and a", "(foo a)", "(not a b)", "(pbleq 2 (1 a) b)", "(dummy a)", "a b", "()"} {
+		if _, err := ParseSExpr(strings.NewReader(s)); err == nil {
+			t.Errorf("S-expression %q should not have been parsed", s)
+		}
+	}
+	for _, s := range []string{`{"op":"foo"}`, `{"op":"var"}`, `{"op":"not"}`, `{"op":"pbleq","k":1,"args":[{"op":"true"}]}`, `null`, `[`} {
+		if _, err := ParseJSON(strings.NewReader(s)); err == nil {
+			t.Errorf("JSON %q should not have been parsed", s)
+		}
+	}
+}
+
+func ExampleFormat() {
+	f := And(Implies(Var("a"), Or(Var("b"), Var("c"))), AtMost(1, Var("b"), Var("c")), Not(Var("x#0")))
+	for _, style := range []Style{Text, Lines, SExpr, JSON} {
+		if err := Format(f, os.Stdout, style); err != nil {
+			fmt.Println(err)
+		}
+	}
+	// Output:
+	// (^a | b | c) & atmost(1, b, c) & ^"x#0"
+	// ^a | b | c;
+	// atmost(1, b, c);
+	// ^"x#0"
+	// (and (or (not a) (or b c)) (atmost 1 b c) (not "x#0"))
+	// {"op":"and","args":[{"op":"or","args":[{"op":"not","args":[{"op":"var","name":"a"}]},{"op":"or","args":[{"op":"var","name":"b"},{"op":"var","name":"c"}]}]},{"op":"atmost","k":1,"args":[{"op":"var","name":"b"},{"op":"var","name":"c"}]},{"op":"not","args":[{"op":"var","name":"x#0"}]}]}
+}
+
+func ExampleJSONFormula() {
+	type rule struct {
+		Name    string      `json:"name"`
+		Formula JSONFormula `json:"formula"`
+	}
+	data := []byte(`{"name":"r1","formula":{"op":"or","args":[{"op":"var","name":"a"},{"op":"var","name":"b"}]}}`)
+	var r rule
+	if err := json.Unmarshal(data, &r); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(r.Name, r.Formula)
+	// Output:
+	// r1 or(a, b)
+}
@@ -0,0 +1,159 @@
+package bf
+
+import "github.com/DoOR-Team/gophersat/solver"
+
+// Rename returns a copy of f where each variable is renamed by fn.
+// Subformulas that are shared in f are shared in the result too, so renaming is linear in the size of f.
+func Rename(f Formula, fn func(name string) string) Formula {
+	return renamer{fn: fn, memo: make(map[dagKey]Formula)}.rename(f)
+}
+
+type renamer struct {
+	fn   func(name string) string
+	memo map[dagKey]Formula // Renamed version of each conjunction and disjunction already visited
+}
+
+func (r renamer) rename(f Formula) Formula {
+	switch f := f.(type) {
+	case variable:
+		return r.variable(f)
+	case lit:
+		return lit{v: r.variable(f.v), signed: f.signed}
+	case not:
+		return not{r.rename(f[0])}
+	case and:
+		return r.renameJunction(f, true)
+	case or:
+		return r.renameJunction(f, false)
+	case card:
+		return card{op: f.op, k: f.k, subs: r.renameAll(f.subs)}
+	case pbLeq:
+		return pbLeq{k: f.k, weights: f.weights, subs: r.renameAll(f.subs)}
+	case leq:
+		return leq{k: f.k, weights: f.weights, subs: r.renameAll(f.subs)}
+	default: // Constants
+		return f
+	}
+}
+
+// variable renames v. Dummy variables are kept as is.
+func (r renamer) variable(v variable) variable {
+	if v.dummy {
+		return v
+	}
+	return pbVar(r.fn(v.name))
+}
+
+// renameJunction renames the conjunction or the disjunction of subs.
+func (r renamer) renameJunction(subs []Formula, isAnd bool) Formula {
+	if len(subs) == 0 {
+		return junction(subs, isAnd)
+	}
+	key := dagKey{and: isAnd, first: &subs[0], len: len(subs)}
+	if res, ok := r.memo[key]; ok {
+		return res
+	}
+	res := junction(r.renameAll(subs), isAnd)
+	r.memo[key] = res
+	return res
+}
+
+func (r renamer) renameAll(subs []Formula) []Formula {
+	res := make([]Formula, len(subs))
+	for i, sub := range subs {
+		res[i] = r.rename(sub)
+	}
+	return res
+}
+
+func junction(subs []Formula, isAnd bool) Formula {
+	if isAnd {
+		return and(subs)
+	}
+	return or(subs)
+}
+
+// An Encoder translates formulas to clauses of a single incremental solver.
+// Variables with the same name are associated with the same variable of the solver in all formulas,
+// so formulas can be added one after the other, the solver being called in between, e.g with SolveAssumptions.
+// Formulas are translated through the Tseitin transformation, and cardinality and PB constraints
+// are translated to CNF with encode.Auto.
+type Encoder struct {
+	Solver *solver.Solver
+	vars   map[string]solver.Var
+}
+
+// NewEncoder returns an encoder adding formulas to a new, empty solver.
+func NewEncoder() *Encoder {
+	return &Encoder{Solver: solver.New(solver.ParseSlice(nil)), vars: make(map[string]solver.Var)}
+}
+
+// Var returns the literal associated with the variable name, creating it if needed.
+func (e *Encoder) Var(name string) solver.Lit {
+	v, ok := e.vars[name]
+	if !ok {
+		v = e.Solver.NewVar()
+		e.vars[name] = v
+	}
+	return v.Lit()
+}
+
+// Assert adds the clauses stating f is true to the solver.
+func (e *Encoder) Assert(f Formula) {
+	e.add(f)
+}
+
+// Lit returns a literal that is equivalent to f: f is true in a model iff the literal is true.
+// The literal can then be used as an assumption, or in other clauses.
+func (e *Encoder) Lit(f Formula) solver.Lit {
+	v := dummyVar("lit")
+	return e.add(Eq(v, f))[v].Lit()
+}
+
+// add adds the clauses stating f is true to the solver, and returns the solver vars associated with
+// the dummy variables of f. Each dummy variable is associated with a new solver var.
+func (e *Encoder) add(f Formula) map[variable]solver.Var {
+	c := asCnf(f, Options{Conversion: Tseitin})
+	vars := make([]solver.Var, len(c.vars.all)+1) // Solver var associated with each var of c
+	dummies := make(map[variable]solver.Var)
+	for v, idx := range c.vars.all {
+		if v.dummy {
+			vars[idx] = e.Solver.NewVar()
+			dummies[v] = vars[idx]
+		} else {
+			vars[idx] = e.Var(v.name).Var()
+		}
+	}
+	for _, clause := range c.clauses {
+		seen := make(map[solver.Lit]bool, len(clause))
+		lits := make([]solver.Lit, 0, len(clause))
+		tautology := false
+		for _, l := range clause {
+			lit := vars[abs(l)].Lit()
+			if l < 0 {
+				lit = lit.Negation()
+			}
+			tautology = tautology || seen[lit.Negation()]
+			if !seen[lit] {
+				seen[lit] = true
+				lits = append(lits, lit)
+			}
+		}
+		if !tautology {
+			e.Solver.AppendClause(solver.NewClause(lits))
+		}
+	}
+	return dummies
+}
+
+// Model returns the binding of each variable in the last model found by the solver.
+// Variables that were simplified away during the translation, and can have any value, are not in the model.
+// Variables created after the last call to the solver are false.
+func (e *Encoder) Model() map[string]bool {
+	m := e.Solver.Model()
+	res := make(map[string]bool, len(e.vars))
+	for name, v := range e.vars {
+		res[name] = int(v) < len(m) && m[v]
+	}
+	return res
+}
@@ -0,0 +1,157 @@
+package bf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// A JSONFormula wraps a formula so that it can be encoded and decoded by the encoding/json package.
+// A formula is encoded as an object whose "op" field is one of "true", "false", "var", "not", "and", "or",
+// "atmost", "atleast", "exactly" and "pbleq". Variables have a "name" field, and a "dummy" field if they are dummy.
+// Constraints have a "k" field, and PB constraints a "weights" field. Subformulas are in the "args" field.
+// For instance, the formula a | ^b is encoded as
+//
+//	{"op":"or","args":[{"op":"var","name":"a"},{"op":"not","args":[{"op":"var","name":"b"}]}]}
+type JSONFormula struct {
+	Formula
+}
+
+type jsonNode struct {
+	Op      string     `json:"op"`
+	Name    string     `json:"name,omitempty"`
+	Dummy   bool       `json:"dummy,omitempty"`
+	K       int        `json:"k,omitempty"`
+	Weights []int      `json:"weights,omitempty"`
+	Args    []jsonNode `json:"args,omitempty"`
+}
+
+// MarshalJSON encodes the formula as JSON.
+func (f JSONFormula) MarshalJSON() ([]byte, error) {
+	if f.Formula == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(toJSON(f.Formula))
+}
+
+// UnmarshalJSON decodes the formula from JSON.
+func (f *JSONFormula) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		f.Formula = nil
+		return nil
+	}
+	var n jsonNode
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	res, err := fromJSON(n)
+	if err != nil {
+		return err
+	}
+	f.Formula = res
+	return nil
+}
+
+// ParseJSON parses a formula encoded as JSON, as written by Format with the JSON style.
+func ParseJSON(r io.Reader) (Formula, error) {
+	var f JSONFormula
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("could not parse JSON formula: %v", err)
+	}
+	if f.Formula == nil {
+		return nil, fmt.Errorf("could not parse JSON formula: null formula")
+	}
+	return f.Formula, nil
+}
+
+func toJSON(f Formula) jsonNode {
+	switch f := f.(type) {
+	case trueConst:
+		return jsonNode{Op: "true"}
+	case falseConst:
+		return jsonNode{Op: "false"}
+	case variable:
+		return jsonNode{Op: "var", Name: f.name, Dummy: f.dummy}
+	case lit:
+		v := toJSON(f.v)
+		if f.signed {
+			return jsonNode{Op: "not", Args: []jsonNode{v}}
+		}
+		return v
+	case not:
+		return jsonNode{Op: "not", Args: []jsonNode{toJSON(f[0])}}
+	case and:
+		return jsonNode{Op: "and", Args: toJSONs(f)}
+	case or:
+		return jsonNode{Op: "or", Args: toJSONs(f)}
+	case card:
+		return jsonNode{Op: f.op, K: f.k, Args: toJSONs(f.subs)}
+	case pbLeq:
+		return jsonNode{Op: "pbleq", K: f.k, Weights: f.weights, Args: toJSONs(f.subs)}
+	case leq:
+		return jsonNode{Op: "pbleq", K: f.k, Weights: f.weights, Args: toJSONs(f.subs)}
+	default:
+		panic("invalid formula type")
+	}
+}
+
+func toJSONs(subs []Formula) []jsonNode {
+	res := make([]jsonNode, len(subs))
+	for i, sub := range subs {
+		res[i] = toJSON(sub)
+	}
+	return res
+}
+
+func fromJSON(n jsonNode) (Formula, error) {
+	subs := make([]Formula, len(n.Args))
+	for i, arg := range n.Args {
+		sub, err := fromJSON(arg)
+		if err != nil {
+			return nil, err
+		}
+		subs[i] = sub
+	}
+	switch n.Op {
+	case "true", "false", "var":
+		if len(subs) != 0 {
+			return nil, fmt.Errorf("unexpected arguments for %q", n.Op)
+		}
+	}
+	switch n.Op {
+	case "true":
+		return True, nil
+	case "false":
+		return False, nil
+	case "var":
+		if n.Name == "" {
+			return nil, fmt.Errorf("variable without a name")
+		}
+		if n.Dummy {
+			return dummyVar(n.Name), nil
+		}
+		return Var(n.Name), nil
+	case "not":
+		if len(subs) != 1 {
+			return nil, fmt.Errorf("expected 1 argument for \"not\", found %d", len(subs))
+		}
+		return Not(subs[0]), nil
+	case "and":
+		return And(subs...), nil
+	case "or":
+		return Or(subs...), nil
+	case "atmost":
+		return AtMost(n.K, subs...), nil
+	case "atleast":
+		return AtLeast(n.K, subs...), nil
+	case "exactly":
+		return Exactly(n.K, subs...), nil
+	case "pbleq":
+		if len(n.Weights) != len(subs) {
+			return nil, fmt.Errorf("expected %d weights for \"pbleq\", found %d", len(subs), len(n.Weights))
+		}
+		return PBLeq(n.K, n.Weights, subs...), nil
+	default:
+		return nil, fmt.Errorf("unknown operator %q", n.Op)
+	}
+}
@@ -0,0 +1,89 @@
+package bf
+
+import (
+	"sort"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// Models enumerates the models of f and returns their number.
+// Models are projected on the named variables of f: dummy variables created when translating f to CNF
+// are not part of them, so two models only differing by the value of dummy variables are considered as a single one.
+// Variables that were simplified away during the translation can have any value, and each of these values
+// yields a different model.
+// If models is not nil, each model is written to it as soon as it is found.
+// If data is sent to stop, the function stops prematurely and returns the number of models found so far.
+// In any case, models is closed before the function returns.
+func Models(f Formula, models chan map[string]bool, stop chan struct{}) int {
+	if models != nil {
+		defer close(models)
+	}
+	cnf := asCnf(f, Options{Native: true})
+	s := solver.New(cnf.problem())
+	var (
+		projected []variable // Named vars of the problem
+		free      []variable // Named vars that are not constrained at all
+	)
+	for v, idx := range cnf.vars.pb {
+		if v.dummy {
+			continue
+		}
+		if idx <= s.NbVars() {
+			projected = append(projected, v)
+		} else {
+			free = append(free, v)
+		}
+	}
+	for _, v := range cnf.unused {
+		if !v.dummy {
+			free = append(free, v)
+		}
+	}
+	sort.Slice(projected, func(i, j int) bool { return cnf.vars.pb[projected[i]] < cnf.vars.pb[projected[j]] })
+	sort.Slice(free, func(i, j int) bool { return free[i].name < free[j].name })
+	nb := 0
+	for !stopped(stop) && s.Solve() == solver.Sat {
+		model := s.Model()
+		if models == nil {
+			nb += 1 << uint(len(free))
+		} else {
+			for m := 0; m < 1<<uint(len(free)) && !stopped(stop); m++ {
+				res := make(map[string]bool, len(projected)+len(free))
+				for _, v := range projected {
+					res[v.name] = model[cnf.vars.pb[v]-1]
+				}
+				for i, v := range free {
+					res[v.name] = m&(1<<uint(i)) != 0
+				}
+				models <- res
+				nb++
+			}
+		}
+		if len(projected) == 0 {
+			break
+		}
+		// Block the model, as far as the projected vars are concerned
+		lits := make([]solver.Lit, len(projected))
+		for i, v := range projected {
+			idx := cnf.vars.pb[v] - 1
+			lits[i] = solver.Var(idx).SignedLit(model[idx])
+		}
+		s.AppendClause(solver.NewClause(lits))
+	}
+	return nb
+}
+
+// Count returns the number of models of f, projected on its named variables, as described in Models.
+func Count(f Formula) int {
+	return Models(f, nil, nil)
+}
+
+// stopped returns true iff something was sent on stop, or it was closed.
+func stopped(stop chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
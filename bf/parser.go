@@ -4,13 +4,21 @@ import (
 	"fmt"
 	"go/token"
 	"io"
+	"strconv"
 	"text/scanner"
+
+	"github.com/DoOR-Team/gophersat/input"
 )
 
 type parser struct {
 	s     scanner.Scanner
-	eof   bool   // Have we reached eof yet?
-	token string // Last token read
+	eof   bool             // Have we reached eof yet?
+	token string           // Last token read
+	kind  rune             // Kind of the last token read, as returned by the scanner
+	pos   scanner.Position // Position of the last token read
+	err   error            // First error reported by the scanner, if any
+	defs  map[string]Formula
+	vars  map[string]bool // Names of the variables used so far
 }
 
 // Parse parses the formula from the given input Reader.
@@ -19,9 +27,9 @@ type parser struct {
 //
 // - for a conjunction of clauses ("and"), the ";" operator
 //
-// - for an equivalence, the "=" operator,
+// - for an equivalence, the "=" or "<->" operator, and for an exclusive or, the "xor" or "!=" operator,
 //
-// - for an implication, the "->" operator,
+// - for an implication, the "->" operator, or the "<-" operator for a reversed implication,
 //
 // - for a disjunction ("or"), the "|" operator,
 //
@@ -29,68 +37,212 @@ type parser struct {
 //
 // - for a negation, the "^" unary operator.
 //
+// - for constants, "true" and "false".
+//
+// - for a variable, its name, or its name as a quoted string, eg "\"x#0\"", if it is not an identifier or is a keyword.
+//
 // - for an exactly-one constraint, names of variables between curly braces, eg "{a, b, c}" to specify
 // exactly one of the variable a, b or c must be true.
 //
+// - for cardinality constraints, "atmost", "atleast" and "exactly", followed by the bound and the subformulas,
+// eg "atmost(2, a, b, c | d)" to specify at most two of a, b and c | d can be true.
+//
+// - for PB constraints, "pbleq", followed by the bound and the weighted subformulas,
+// eg "pbleq(4, 3*a, 2*b, -1*^c)" to specify the sum of the weights of the true subformulas is at most 4.
+//
 // Parentheses can be used to group subformulas.
 // Note there are two ways to write conjunctions, one with a low priority, one with a high priority.
 // The low-priority one is useful when the user wants to describe a whole formula as a set of smaller formulas
 // that must all be true.
+//
+// A clause can also be a definition, eg "let ab := a & b", after which the name ab stands for the formula a & b.
+// Names must be defined before they are used, and cannot be redefined.
+// Definitions are not constraints by themselves: "let ab := a & b; ab | c" is equivalent to "(a & b) | c".
+//
+// Comments start with "#" or "//" and end at the end of the line. "/* ... */" comments are also accepted.
+// Errors indicate the line and column of the offending token.
 func Parse(r io.Reader) (Formula, error) {
-	var s scanner.Scanner
-	s.Init(r)
-	p := parser{s: s}
-	p.scan()
-	f, err := p.parseClause()
+	r, err := input.Decompress(r)
 	if err != nil {
-		return f, err
+		return nil, err
+	}
+	p := newParser(r)
+	f, err := p.parseClauses()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if err != nil {
+		return nil, err
 	}
 	if !p.eof {
-		return nil, fmt.Errorf("expected EOF, found %q at %v", p.token, p.s.Pos())
+		return nil, p.errorf("expected EOF, found %s", p.found())
 	}
 	return f, nil
 }
 
+// newParser returns a parser reading from r, whose first token was already read.
+func newParser(r io.Reader) *parser {
+	p := &parser{defs: make(map[string]Formula), vars: make(map[string]bool)}
+	p.s.Init(r)
+	p.s.Error = func(s *scanner.Scanner, msg string) {
+		if p.err == nil {
+			pos := s.Pos()
+			p.err = fmt.Errorf("line %d, column %d: %s", pos.Line, pos.Column, msg)
+		}
+	}
+	p.scan()
+	return p
+}
+
+// isOperator returns true iff the given token is a binary operator, or the definition operator.
 func isOperator(token string) bool {
-	return token == "=" || token == "->" || token == "|" || token == "&" || token == ";"
+	switch token {
+	case "=", "<->", "xor", "!=", "->", "<-", "|", "&", ";", ":=":
+		return true
+	default:
+		return false
+	}
 }
 
-func (p *parser) scan() {
-	p.eof = p.eof || (p.s.Scan() == scanner.EOF)
-	p.token = p.s.TokenText()
+// isKeyword returns true iff the given identifier cannot be used as the name of a variable or of a definition.
+func isKeyword(name string) bool {
+	return name == "true" || name == "false" || name == "let" || name == "xor"
 }
 
-func (p *parser) parseClause() (f Formula, err error) {
-	if isOperator(p.token) {
-		return nil, fmt.Errorf("unexpected token %q at %s", p.token, p.s.Pos())
+// scan reads the next token. Comments are skipped, and multi-character operators are read as a single token.
+func (p *parser) scan() {
+	for {
+		if !p.eof {
+			p.kind = p.s.Scan()
+			p.eof = p.kind == scanner.EOF
+		}
+		p.pos = p.s.Position
+		if !p.pos.IsValid() { // EOF of an empty content
+			p.pos = p.s.Pos()
+		}
+		if p.eof {
+			p.token = ""
+			return
+		}
+		p.token = p.s.TokenText()
+		if p.token != "#" {
+			break
+		}
+		for r := p.s.Peek(); r != '\n' && r != scanner.EOF; r = p.s.Peek() {
+			p.s.Next()
+		}
 	}
-	f, err = p.parseEquiv()
-	if err != nil {
-		return nil, err
+	next := func(r rune, token string) bool {
+		if p.s.Peek() != r {
+			return false
+		}
+		p.s.Next()
+		p.token = token
+		return true
 	}
+	switch p.token {
+	case "-":
+		next('>', "->")
+	case "<":
+		if next('-', "<-") {
+			next('>', "<->")
+		}
+	case "!":
+		next('=', "!=")
+	case ":":
+		next('=', ":=")
+	}
+}
+
+// errorf returns an error indicating the position of the last token read.
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d, column %d: %s", p.pos.Line, p.pos.Column, fmt.Sprintf(format, args...))
+}
+
+// found returns a description of the last token read, for error messages.
+func (p *parser) found() string {
 	if p.eof {
-		return f, nil
+		return "EOF"
 	}
-	if p.token == ";" {
+	return strconv.Quote(p.token)
+}
+
+// parseClauses parses a list of clauses and definitions separated by ";".
+func (p *parser) parseClauses() (Formula, error) {
+	var clauses []Formula
+	for {
+		if p.token == "let" {
+			if err := p.parseLet(); err != nil {
+				return nil, err
+			}
+		} else {
+			f, err := p.parseClause()
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, f)
+		}
+		if p.eof || p.token != ";" {
+			break
+		}
 		p.scan()
 		if p.eof {
-			return f, nil
-		}
-		f2, err := p.parseClause()
-		if err != nil {
-			return nil, err
+			break
 		}
-		return And(f, f2), nil
 	}
-	return f, nil
+	if len(clauses) == 0 {
+		return True, nil
+	}
+	res := clauses[len(clauses)-1]
+	for i := len(clauses) - 2; i >= 0; i-- {
+		res = And(clauses[i], res)
+	}
+	return res, nil
+}
+
+// parseLet parses a definition, starting with the "let" keyword.
+func (p *parser) parseLet() error {
+	p.scan()
+	name, pos := p.token, p.pos
+	if p.eof || token.Lookup(name) != token.IDENT || isKeyword(name) {
+		return p.errorf("expected name after \"let\", found %s", p.found())
+	}
+	if _, ok := p.defs[name]; ok {
+		return p.errorf("%q is already defined", name)
+	}
+	if p.vars[name] {
+		return p.errorf("%q is already used as a variable", name)
+	}
+	p.scan()
+	if p.token != ":=" || p.eof {
+		return p.errorf("expected \":=\", found %s", p.found())
+	}
+	p.scan()
+	f, err := p.parseEquiv()
+	if err != nil {
+		return err
+	}
+	if p.vars[name] {
+		p.pos = pos // Report the error on the name being defined
+		return p.errorf("%q is used in its own definition", name)
+	}
+	p.defs[name] = f
+	return nil
+}
+
+func (p *parser) parseClause() (f Formula, err error) {
+	if isOperator(p.token) {
+		return nil, p.errorf("unexpected token %s", p.found())
+	}
+	return p.parseEquiv()
 }
 
 func (p *parser) parseEquiv() (f Formula, err error) {
 	if p.eof {
-		return nil, fmt.Errorf("At position %v, expected expression, found EOF", p.s.Pos())
+		return nil, p.errorf("expected expression, found EOF")
 	}
 	if isOperator(p.token) {
-		return nil, fmt.Errorf("unexpected token %q at %s", p.token, p.s.Pos())
+		return nil, p.errorf("unexpected token %s", p.found())
 	}
 	f, err = p.parseImplies()
 	if err != nil {
@@ -99,15 +251,19 @@ func (p *parser) parseEquiv() (f Formula, err error) {
 	if p.eof {
 		return f, nil
 	}
-	if p.token == "=" {
+	switch op := p.token; op {
+	case "=", "<->", "xor", "!=":
 		p.scan()
 		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
+			return nil, p.errorf("unexpected EOF after %q", op)
 		}
 		f2, err := p.parseEquiv()
 		if err != nil {
 			return nil, err
 		}
+		if op == "xor" || op == "!=" {
+			return Xor(f, f2), nil
+		}
 		return Eq(f, f2), nil
 	}
 	return f, nil
@@ -121,22 +277,19 @@ func (p *parser) parseImplies() (f Formula, err error) {
 	if p.eof {
 		return f, nil
 	}
-	if p.token == "-" {
-		p.scan()
-		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
-		}
-		if p.token != ">" {
-			return nil, fmt.Errorf("invalid token %q at %v", "-"+p.token, p.s.Pos())
-		}
+	switch op := p.token; op {
+	case "->", "<-":
 		p.scan()
 		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
+			return nil, p.errorf("unexpected EOF after %q", op)
 		}
 		f2, err := p.parseImplies()
 		if err != nil {
 			return nil, err
 		}
+		if op == "<-" {
+			return Implies(f2, f), nil
+		}
 		return Implies(f, f2), nil
 	}
 	return f, nil
@@ -153,7 +306,7 @@ func (p *parser) parseOr() (f Formula, err error) {
 	if p.token == "|" {
 		p.scan()
 		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
+			return nil, p.errorf("unexpected EOF after \"|\"")
 		}
 		f2, err := p.parseOr()
 		if err != nil {
@@ -175,7 +328,7 @@ func (p *parser) parseAnd() (f Formula, err error) {
 	if p.token == "&" {
 		p.scan()
 		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
+			return nil, p.errorf("unexpected EOF after \"&\"")
 		}
 		f2, err := p.parseAnd()
 		if err != nil {
@@ -188,12 +341,12 @@ func (p *parser) parseAnd() (f Formula, err error) {
 
 func (p *parser) parseNot() (f Formula, err error) {
 	if isOperator(p.token) {
-		return nil, fmt.Errorf("unexpected token %q at %s", p.token, p.s.Pos())
+		return nil, p.errorf("unexpected token %s", p.found())
 	}
 	if p.token == "^" {
 		p.scan()
 		if p.eof {
-			return nil, fmt.Errorf("unexpected EOF")
+			return nil, p.errorf("unexpected EOF after \"^\"")
 		}
 		f, err = p.parseNot()
 		if err != nil {
@@ -209,8 +362,11 @@ func (p *parser) parseNot() (f Formula, err error) {
 }
 
 func (p *parser) parseBasic() (f Formula, err error) {
-	if isOperator(p.token) || p.token == ")" {
-		return nil, fmt.Errorf("unexpected token %q at %s", p.token, p.s.Pos())
+	if p.eof {
+		return nil, p.errorf("expected expression, found EOF")
+	}
+	if isOperator(p.token) || p.token == ")" || p.token == "," || p.token == "let" {
+		return nil, p.errorf("unexpected token %s", p.found())
 	}
 	if p.token == "(" {
 		p.scan()
@@ -218,11 +374,8 @@ func (p *parser) parseBasic() (f Formula, err error) {
 		if err != nil {
 			return nil, err
 		}
-		if p.eof {
-			return nil, fmt.Errorf("expected closing parenthesis, found EOF at %s", p.s.Pos())
-		}
-		if p.token != ")" {
-			return nil, fmt.Errorf("expected closing parenthesis, found %q at %s", p.token, p.s.Pos())
+		if p.eof || p.token != ")" {
+			return nil, p.errorf("expected closing parenthesis, found %s", p.found())
 		}
 		p.scan()
 		return f, nil
@@ -232,23 +385,128 @@ func (p *parser) parseBasic() (f Formula, err error) {
 		for p.token != "}" {
 			p.scan()
 			if p.eof {
-				return nil, fmt.Errorf("expected identifier, found EOF at %s", p.s.Pos())
+				return nil, p.errorf("expected identifier, found EOF")
 			}
-			if token.Lookup(p.token) != token.IDENT {
-				return nil, fmt.Errorf("expected variable name, found %q at %s", p.token, p.s.Pos())
+			if token.Lookup(p.token) != token.IDENT || isKeyword(p.token) {
+				return nil, p.errorf("expected variable name, found %s", p.found())
+			}
+			if _, ok := p.defs[p.token]; ok {
+				return nil, p.errorf("expected variable name, found definition %q", p.token)
 			}
 			vars = append(vars, p.token)
+			p.vars[p.token] = true
 			p.scan()
-			if p.eof {
-				return nil, fmt.Errorf("expected comma or closing brace, found EOF at %s", p.s.Pos())
-			}
-			if p.token != "}" && p.token != "," {
-				return nil, fmt.Errorf("expected comma or closing brace, found %q at %v", p.token, p.s.Pos())
+			if p.eof || (p.token != "}" && p.token != ",") {
+				return nil, p.errorf("expected comma or closing brace, found %s", p.found())
 			}
 		}
 		p.scan()
 		return Unique(vars...), nil
 	}
-	defer p.scan()
-	return Var(p.token), nil
+	if p.kind == scanner.String || p.kind == scanner.RawString {
+		return p.parseQuotedVar()
+	}
+	name := p.token
+	p.scan()
+	switch name {
+	case "true":
+		return True, nil
+	case "false":
+		return False, nil
+	}
+	if def, ok := p.defs[name]; ok {
+		return def, nil
+	}
+	if isConstraint(name) && !p.eof && p.token == "(" {
+		return p.parseConstraint(name)
+	}
+	p.vars[name] = true
+	return Var(name), nil
+}
+
+// parseQuotedVar parses a variable whose name is a quoted string.
+// Quoted names are never keywords nor definitions.
+func (p *parser) parseQuotedVar() (Formula, error) {
+	name, err := strconv.Unquote(p.token)
+	if err != nil {
+		return nil, p.errorf("invalid quoted name %s", p.token)
+	}
+	p.scan()
+	p.vars[name] = true
+	return Var(name), nil
+}
+
+// isConstraint returns true iff the given identifier is the name of a cardinality or PB constraint.
+func isConstraint(name string) bool {
+	return name == "atmost" || name == "atleast" || name == "exactly" || name == "pbleq"
+}
+
+// parseConstraint parses the arguments of a cardinality or PB constraint, starting with the opening parenthesis.
+func (p *parser) parseConstraint(name string) (f Formula, err error) {
+	p.scan()
+	k, err := p.parseInt()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		subs    []Formula
+		weights []int
+	)
+	for p.token != ")" {
+		if p.token != "," {
+			return nil, p.errorf("expected comma or closing parenthesis, found %s", p.found())
+		}
+		p.scan()
+		if name == "pbleq" {
+			w, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			if p.token != "*" {
+				return nil, p.errorf("expected \"*\", found %s", p.found())
+			}
+			p.scan()
+			weights = append(weights, w)
+		}
+		sub, err := p.parseEquiv()
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+		if p.eof {
+			return nil, p.errorf("expected comma or closing parenthesis, found EOF")
+		}
+	}
+	p.scan()
+	switch name {
+	case "atmost":
+		return AtMost(k, subs...), nil
+	case "atleast":
+		return AtLeast(k, subs...), nil
+	case "exactly":
+		return Exactly(k, subs...), nil
+	default:
+		return PBLeq(k, weights, subs...), nil
+	}
+}
+
+// parseInt parses an integer, that can be negative.
+func (p *parser) parseInt() (int, error) {
+	if p.eof {
+		return 0, p.errorf("expected integer, found EOF")
+	}
+	sign := 1
+	if p.token == "-" {
+		sign = -1
+		p.scan()
+		if p.eof {
+			return 0, p.errorf("expected integer, found EOF")
+		}
+	}
+	n, err := strconv.Atoi(p.token)
+	if err != nil {
+		return 0, p.errorf("expected integer, found %s", p.found())
+	}
+	p.scan()
+	return sign * n, nil
 }
@@ -9,21 +9,57 @@ import (
 // To each formula, associate an expected string input.
 // An empty string means an error is expected.
 var exprToFormula = map[string]string{
-	"foo":                  "foo",
-	"^foo":                 "not(foo)",
-	"^^foo":                "not(not(foo))",
-	"(foo)":                "foo",
-	"a | b":                "or(a, b)",
-	"a & b":                "and(a, b)",
-	"a -> b":               "or(not(a), b)",
-	"a = b":                "and(or(not(a), b), or(a, not(b)))",
-	"^(a|  b)":             "not(or(a, b))",
-	"a & b & c":            "and(a, and(b, c))",
-	"a & (b & c) & d":      "and(a, and(and(b, c), d))",
-	"a = b |c -> ^(d&e)":   "and(or(not(a), or(not(or(b, c)), not(and(d, e)))), or(a, not(or(not(or(b, c)), not(and(d, e))))))",
-	"(a|^b|c) & ^(a|^b|c)": "and(or(a, or(not(b), c)), not(or(a, or(not(b), c))))",
-	"{a, b, c}":            "and(or(a, b, c), or(not(a), not(b)), or(not(a), not(c)), or(not(b), not(c)))",
-	"a | b; ^a | ^b":       "and(or(a, b), or(not(a), not(b)))",
+	"foo":                            "foo",
+	"^foo":                           "not(foo)",
+	"^^foo":                          "not(not(foo))",
+	"(foo)":                          "foo",
+	"a | b":                          "or(a, b)",
+	"a & b":                          "and(a, b)",
+	"a -> b":                         "or(not(a), b)",
+	"a = b":                          "and(or(not(a), b), or(a, not(b)))",
+	"^(a|  b)":                       "not(or(a, b))",
+	"a & b & c":                      "and(a, and(b, c))",
+	"a & (b & c) & d":                "and(a, and(and(b, c), d))",
+	"a = b |c -> ^(d&e)":             "and(or(not(a), or(not(or(b, c)), not(and(d, e)))), or(a, not(or(not(or(b, c)), not(and(d, e))))))",
+	"(a|^b|c) & ^(a|^b|c)":           "and(or(a, or(not(b), c)), not(or(a, or(not(b), c))))",
+	"{a, b, c}":                      "and(or(a, b, c), or(not(a), not(b)), or(not(a), not(c)), or(not(b), not(c)))",
+	"a | b; ^a | ^b":                 "and(or(a, b), or(not(a), not(b)))",
+	"atmost(2, a, b, c)":             "atmost(2, a, b, c)",
+	"atleast(1, a&b, ^c)":            "atleast(1, and(a, b), not(c))",
+	"exactly(0, a | b)":              "exactly(0, or(a, b))",
+	"atmost(2)":                      "atmost(2)",
+	"pbleq(3)":                       "pbleq(3)",
+	"pbleq(4, 3*a, -1*^c)":           "pbleq(4, 3*a, -1*not(c))",
+	"atmost & b":                     "and(atmost, b)",
+	"true | false":                   "or(⊤, ⊥)",
+	"a xor b":                        "and(or(not(a), not(b)), or(a, b))",
+	"a != b":                         "and(or(not(a), not(b)), or(a, b))",
+	"a <-> b":                        "and(or(not(a), b), or(a, not(b)))",
+	"a <- b":                         "or(not(b), a)",
+	"a<-b->c":                        "or(not(or(not(b), c)), a)",
+	"a # comment\n| b":               "or(a, b)",
+	"a // comment\n& b":              "and(a, b)",
+	"let x := a & b; x | c":          "or(and(a, b), c)",
+	"let x := a; let y := ^x; y = x": "and(or(not(not(a)), a), or(not(a), not(a)))",
+	"a; let x := b":                  "a",
+	"let x := a":                     "⊤",
+}
+
+// Invalid expressions, and the beginning of the expected error message.
+var invalidExprs = map[string]string{
+	"a &":                    "line 1, column 4: unexpected EOF after \"&\"",
+	"a\n& (b | c":            "line 2, column 9: expected closing parenthesis, found EOF",
+	"a\n  & | b":             "line 2, column 5: unexpected token \"|\"",
+	"let true := a":          "line 1, column 5: expected name after \"let\", found \"true\"",
+	"let x := a; let x := b": "line 1, column 17: \"x\" is already defined",
+	"x; let x := b":          "line 1, column 8: \"x\" is already used as a variable",
+	"let x = a":              "line 1, column 7: expected \":=\", found \"=\"",
+	"a b":                    "line 1, column 3: expected EOF, found \"b\"",
+	"atmost(2, a b)":         "line 1, column 13: expected comma or closing parenthesis, found \"b\"",
+	"a & let":                "line 1, column 5: unexpected token \"let\"",
+	"let a := a; a":          "line 1, column 5: \"a\" is used in its own definition",
+	"let a := b | ^a":        "line 1, column 5: \"a\" is used in its own definition",
+	"":                       "line 1, column 1: expected expression, found EOF",
 }
 
 func TestParse(t *testing.T) {
@@ -38,6 +74,17 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseErrors(t *testing.T) {
+	for expr, expected := range invalidExprs {
+		_, err := Parse(strings.NewReader(expr))
+		if err == nil {
+			t.Errorf("Expression %q should not have been parsed", expr)
+		} else if !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("For expression %q, expected error %q, got %q", expr, expected, err.Error())
+		}
+	}
+}
+
 func ExampleParse() {
 	expr := "a & ^(b -> c) & (c = d | ^a)"
 	f, err := Parse(strings.NewReader(expr))
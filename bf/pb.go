@@ -0,0 +1,284 @@
+package bf
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/DoOR-Team/gophersat/encode"
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// AtMost indicates at most k of the given subformulas can be true.
+func AtMost(k int, subs ...Formula) Formula {
+	return card{op: "atmost", k: k, subs: subs}
+}
+
+// AtLeast indicates at least k of the given subformulas must be true.
+func AtLeast(k int, subs ...Formula) Formula {
+	return card{op: "atleast", k: k, subs: subs}
+}
+
+// Exactly indicates exactly k of the given subformulas must be true.
+func Exactly(k int, subs ...Formula) Formula {
+	return card{op: "exactly", k: k, subs: subs}
+}
+
+// PBLeq indicates the sum of the weights of the true subformulas is at most k.
+// Weights can be negative.
+// Will panic if len(weights) != len(subs).
+func PBLeq(k int, weights []int, subs ...Formula) Formula {
+	if len(weights) != len(subs) {
+		panic("not as many weights as subformulas")
+	}
+	return pbLeq{k: k, weights: weights, subs: subs}
+}
+
+// A card is a cardinality constraint on subformulas.
+type card struct {
+	op   string // "atmost", "atleast" or "exactly"
+	k    int
+	subs []Formula
+}
+
+func (c card) nnf() Formula {
+	ones := make([]int, len(c.subs))
+	for i := range ones {
+		ones[i] = 1
+	}
+	atMost := newLeq(c.subs, ones, c.k)
+	atLeast := newLeq(notAll(c.subs), ones, len(c.subs)-c.k)
+	switch c.op {
+	case "atmost":
+		return atMost
+	case "atleast":
+		return atLeast
+	default:
+		return and{atMost, atLeast}.nnf()
+	}
+}
+
+func (c card) String() string {
+	if len(c.subs) == 0 {
+		return c.op + "(" + strconv.Itoa(c.k) + ")"
+	}
+	return c.op + "(" + strconv.Itoa(c.k) + ", " + formulasString(c.subs) + ")"
+}
+
+func (c card) Eval(model map[string]bool) bool {
+	nb := 0
+	for _, sub := range c.subs {
+		if sub.Eval(model) {
+			nb++
+		}
+	}
+	switch c.op {
+	case "atmost":
+		return nb <= c.k
+	case "atleast":
+		return nb >= c.k
+	default:
+		return nb == c.k
+	}
+}
+
+// A pbLeq is a PB constraint on subformulas.
+type pbLeq struct {
+	k       int
+	weights []int
+	subs    []Formula
+}
+
+func (pb pbLeq) nnf() Formula {
+	return newLeq(pb.subs, pb.weights, pb.k)
+}
+
+func (pb pbLeq) String() string {
+	strs := make([]string, len(pb.subs))
+	for i, sub := range pb.subs {
+		strs[i] = strconv.Itoa(pb.weights[i]) + "*" + sub.String()
+	}
+	return "pbleq(" + strings.Join(append([]string{strconv.Itoa(pb.k)}, strs...), ", ") + ")"
+}
+
+func (pb pbLeq) Eval(model map[string]bool) bool {
+	sum := 0
+	for i, sub := range pb.subs {
+		if sub.Eval(model) {
+			sum += pb.weights[i]
+		}
+	}
+	return sum <= pb.k
+}
+
+// A leq is the NNF version of a cardinality or PB constraint:
+// the sum of the weights of the true subformulas is at most k.
+// All weights are strictly positive, and the constraint is neither trivially true nor trivially false.
+type leq struct {
+	k       int
+	weights []int
+	subs    []Formula
+}
+
+// newLeq returns the NNF version of the constraint stating the sum of the weights of the true subformulas
+// is at most k.
+func newLeq(subs []Formula, weights []int, k int) Formula {
+	var res leq
+	sum := 0
+	for i, sub := range subs {
+		w := weights[i]
+		if w < 0 { // w.f = w + (-w).not(f)
+			sub, w = not{sub}, -w
+			k += w
+		}
+		if w != 0 {
+			res.subs = append(res.subs, sub.nnf())
+			res.weights = append(res.weights, w)
+			sum += w
+		}
+	}
+	res.k = k
+	switch {
+	case k < 0:
+		return False
+	case sum <= k:
+		return True
+	default:
+		return res
+	}
+}
+
+func (l leq) nnf() Formula {
+	return l
+}
+
+func (l leq) String() string {
+	return pbLeq(l).String()
+}
+
+func (l leq) Eval(model map[string]bool) bool {
+	return pbLeq(l).Eval(model)
+}
+
+// negation returns the NNF of the negation of l.
+func (l leq) negation() Formula {
+	sum := 0
+	for _, w := range l.weights {
+		sum += w
+	}
+	// not(sum(w.f) <= k) iff sum(w.f) >= k+1 iff sum(w.not(f)) <= sum(w) - k - 1
+	return newLeq(notAll(l.subs), l.weights, sum-l.k-1)
+}
+
+// notAll returns the negation of all given formulas.
+func notAll(subs []Formula) []Formula {
+	res := make([]Formula, len(subs))
+	for i, sub := range subs {
+		res[i] = not{sub}
+	}
+	return res
+}
+
+func formulasString(subs []Formula) string {
+	strs := make([]string, len(subs))
+	for i, sub := range subs {
+		strs[i] = sub.String()
+	}
+	return strings.Join(strs, ", ")
+}
+
+// leqClauses returns the clauses associated with l.
+// If act is not 0, the constraint only holds when the literal act is true.
+// Subformulas that are not literals are replaced by dummy variables they imply.
+// If c.native is true, the constraint is added to c.pbs and the returned clauses only define the dummy variables.
+func (c *cnf) leqClauses(l leq, act int) [][]int {
+	var res [][]int
+	var lits, weights []int
+	k := l.k
+	for i, sub := range l.subs {
+		var val int
+		switch sub := sub.(type) {
+		case lit:
+			val = c.vars.litValue(sub)
+		case trueConst:
+			k -= l.weights[i]
+			continue
+		case falseConst:
+			continue
+		default:
+			val = c.vars.dummy()
+			res = append(res, c.condClauses(not{sub}.nnf(), -val)...)
+		}
+		lits = append(lits, val)
+		weights = append(weights, l.weights[i])
+	}
+	return append(res, c.pbClauses(lits, weights, k, act)...)
+}
+
+// pbClauses returns the clauses stating sum(weights[i].lits[i]) <= k, where all weights are positive.
+// If act is not 0, the constraint only holds when the literal act is true.
+// If c.native is true, the constraint is added to c.pbs and no clause is returned.
+func (c *cnf) pbClauses(lits, weights []int, k, act int) [][]int {
+	coeffs := make(map[int]int) // For each var, the weight of its positive lit
+	var order []int             // Vars, in the order they appeared
+	for i, val := range lits {
+		v, w := val, weights[i]
+		if val < 0 { // w.not(v) = w - w.v
+			v, w = -val, -w
+			k -= weights[i]
+		}
+		if _, ok := coeffs[v]; !ok {
+			order = append(order, v)
+		}
+		coeffs[v] += w
+	}
+	lits, weights = nil, nil
+	for _, v := range order {
+		switch w := coeffs[v]; {
+		case w > 0:
+			lits = append(lits, v)
+			weights = append(weights, w)
+		case w < 0: // w.v = w + (-w).not(v)
+			lits = append(lits, -v)
+			weights = append(weights, -w)
+			k -= w
+		}
+	}
+	// sum(w.l) <= k iff sum(w.not(l)) >= sum(w) - k
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	constr := solver.PBConstr{Lits: negatedInts(lits), Weights: weights, AtLeast: sum - k}
+	if c.native {
+		if act != 0 && constr.AtLeast > 0 {
+			constr.Lits = append(constr.Lits, -act)
+			constr.Weights = append(constr.Weights, constr.AtLeast)
+		}
+		c.pbs = append(c.pbs, constr)
+		return nil
+	}
+	enc := encode.New(len(c.vars.all))
+	encoded, err := enc.PB(constr, c.enc)
+	if err != nil { // Encoding cannot deal with weighted constraints
+		encoded, _ = enc.PB(constr, encode.Auto)
+	}
+	for len(c.vars.all) < enc.NbVars {
+		c.vars.dummy()
+	}
+	res := make([][]int, 0, len(encoded.Clauses))
+	for _, clause := range encoded.Clauses {
+		if act != 0 {
+			clause = append(clause, -act)
+		}
+		res = append(res, clause)
+	}
+	return res
+}
+
+func negatedInts(lits []int) []int {
+	res := make([]int, len(lits))
+	for i, lit := range lits {
+		res[i] = -lit
+	}
+	return res
+}
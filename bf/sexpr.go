@@ -0,0 +1,233 @@
+package bf
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"text/scanner"
+)
+
+// writeSExpr writes f as an S-expression.
+func writeSExpr(b *strings.Builder, f Formula) {
+	switch f := f.(type) {
+	case trueConst:
+		b.WriteString("true")
+	case falseConst:
+		b.WriteString("false")
+	case variable:
+		writeSExprVar(b, f)
+	case lit:
+		if f.signed {
+			b.WriteString("(not ")
+			writeSExprVar(b, f.v)
+			b.WriteByte(')')
+		} else {
+			writeSExprVar(b, f.v)
+		}
+	case not:
+		b.WriteString("(not ")
+		writeSExpr(b, f[0])
+		b.WriteByte(')')
+	case and:
+		writeSExprList(b, "and", f)
+	case or:
+		writeSExprList(b, "or", f)
+	case card:
+		writeSExprList(b, f.op+" "+strconv.Itoa(f.k), f.subs)
+	case pbLeq:
+		writeSExprPB(b, f.k, f.weights, f.subs)
+	case leq:
+		writeSExprPB(b, f.k, f.weights, f.subs)
+	default:
+		panic("invalid formula type")
+	}
+}
+
+func writeSExprVar(b *strings.Builder, v variable) {
+	if v.dummy {
+		b.WriteString("(dummy " + strconv.Quote(v.name) + ")")
+	} else {
+		b.WriteString(quoteName(v.name))
+	}
+}
+
+func writeSExprList(b *strings.Builder, head string, subs []Formula) {
+	b.WriteString("(" + head)
+	for _, sub := range subs {
+		b.WriteByte(' ')
+		writeSExpr(b, sub)
+	}
+	b.WriteByte(')')
+}
+
+func writeSExprPB(b *strings.Builder, k int, weights []int, subs []Formula) {
+	b.WriteString("(pbleq " + strconv.Itoa(k))
+	for i, sub := range subs {
+		b.WriteString(" (" + strconv.Itoa(weights[i]) + " ")
+		writeSExpr(b, sub)
+		b.WriteByte(')')
+	}
+	b.WriteByte(')')
+}
+
+// ParseSExpr parses a formula written as an S-expression, as written by Format with the SExpr style.
+// The grammar is as follows:
+//
+//	sexpr ::= 'true' | 'false' | name | '(' 'dummy' string ')' | '(' 'not' sexpr ')' | '(' ('and' | 'or') { sexpr }* ')'
+//	          | '(' ('atmost' | 'atleast' | 'exactly') int { sexpr }* ')' | '(' 'pbleq' int { '(' int sexpr ')' }* ')'
+//	name  ::= ident | string
+func ParseSExpr(r io.Reader) (Formula, error) {
+	p := newParser(r)
+	f, err := p.parseSExpr()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof {
+		return nil, p.errorf("expected EOF, found %s", p.found())
+	}
+	return f, nil
+}
+
+func (p *parser) parseSExpr() (Formula, error) {
+	if p.eof {
+		return nil, p.errorf("expected expression, found EOF")
+	}
+	if p.token != "(" {
+		switch p.token {
+		case "true":
+			p.scan()
+			return True, nil
+		case "false":
+			p.scan()
+			return False, nil
+		}
+		name, err := p.parseSExprName()
+		if err != nil {
+			return nil, err
+		}
+		return Var(name), nil
+	}
+	p.scan()
+	head := p.token
+	if p.eof || p.kind != scanner.Ident {
+		return nil, p.errorf("expected operator, found %s", p.found())
+	}
+	p.scan()
+	var (
+		f   Formula
+		err error
+	)
+	switch head {
+	case "dummy":
+		if p.kind != scanner.String && p.kind != scanner.RawString {
+			return nil, p.errorf("expected quoted name, found %s", p.found())
+		}
+		var name string
+		if name, err = p.parseSExprName(); err == nil {
+			f = dummyVar(name)
+		}
+	case "not":
+		var sub Formula
+		if sub, err = p.parseSExpr(); err == nil {
+			f = Not(sub)
+		}
+	case "and", "or":
+		var subs []Formula
+		if subs, err = p.parseSExprs(); err == nil {
+			if head == "and" {
+				f = And(subs...)
+			} else {
+				f = Or(subs...)
+			}
+		}
+	case "atmost", "atleast", "exactly":
+		var (
+			k    int
+			subs []Formula
+		)
+		if k, err = p.parseInt(); err == nil {
+			if subs, err = p.parseSExprs(); err == nil {
+				f = card{op: head, k: k, subs: subs}
+			}
+		}
+	case "pbleq":
+		f, err = p.parseSExprPB()
+	default:
+		return nil, p.errorf("unknown operator %q", head)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.eof || p.token != ")" {
+		return nil, p.errorf("expected closing parenthesis, found %s", p.found())
+	}
+	p.scan()
+	return f, nil
+}
+
+// parseSExprName parses the name of a variable, either an identifier or a quoted string.
+func (p *parser) parseSExprName() (string, error) {
+	name := p.token
+	switch {
+	case p.eof:
+		return "", p.errorf("expected name, found EOF")
+	case p.kind == scanner.String || p.kind == scanner.RawString:
+		var err error
+		if name, err = strconv.Unquote(p.token); err != nil {
+			return "", p.errorf("invalid quoted name %s", p.token)
+		}
+	case p.kind != scanner.Ident:
+		return "", p.errorf("expected name, found %s", p.found())
+	}
+	p.scan()
+	return name, nil
+}
+
+// parseSExprs parses S-expressions until a closing parenthesis is found. The parenthesis is not consumed.
+func (p *parser) parseSExprs() ([]Formula, error) {
+	var res []Formula
+	for !p.eof && p.token != ")" {
+		f, err := p.parseSExpr()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, f)
+	}
+	return res, nil
+}
+
+// parseSExprPB parses the bound and the weighted subformulas of a PB constraint.
+func (p *parser) parseSExprPB() (Formula, error) {
+	k, err := p.parseInt()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		weights []int
+		subs    []Formula
+	)
+	for !p.eof && p.token != ")" {
+		if p.token != "(" {
+			return nil, p.errorf("expected weighted term, found %s", p.found())
+		}
+		p.scan()
+		w, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		sub, err := p.parseSExpr()
+		if err != nil {
+			return nil, err
+		}
+		if p.eof || p.token != ")" {
+			return nil, p.errorf("expected closing parenthesis, found %s", p.found())
+		}
+		p.scan()
+		weights = append(weights, w)
+		subs = append(subs, sub)
+	}
+	return PBLeq(k, weights, subs...), nil
+}
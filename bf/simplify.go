@@ -0,0 +1,309 @@
+package bf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Simplify returns a formula equivalent to f, in negation normal form, but simpler.
+// Constants are folded, nested conjunctions and disjunctions are flattened and duplicate subformulas are removed.
+// A conjunction (resp. disjunction) containing complementary literals is replaced by False (resp. True).
+// Literals of a conjunction (resp. disjunction) are considered true (resp. false) in its other subformulas,
+// so that, for instance, a & (^a | b) is simplified as a & b.
+// Subformulas absorbed by another one are removed, so that, for instance, (a | b) & (a | b | c) is simplified as a | b.
+// Subformulas that are identical up to the order of their operands are detected as equivalent.
+// Simplify is automatically called on formulas before they are translated to CNF by the Classic conversion.
+func Simplify(f Formula) Formula {
+	return simplify(f.nnf(), make(map[variable]bool))
+}
+
+// simplify simplifies the NNF formula f, knowing the value of the variables in assigned.
+// assigned can be modified during the call, but is restored before returning.
+func simplify(f Formula, assigned map[variable]bool) Formula {
+	switch f := f.(type) {
+	case lit:
+		if val, ok := assigned[f.v]; ok {
+			if val != f.signed {
+				return True
+			}
+			return False
+		}
+		return f
+	case and:
+		return simplifyJunction(f, true, assigned)
+	case or:
+		return simplifyJunction(f, false, assigned)
+	case leq:
+		return simplifyLeq(f, assigned)
+	default:
+		return f
+	}
+}
+
+// simplifyJunction simplifies the conjunction of subs if isAnd is true, their disjunction else.
+func simplifyJunction(subs []Formula, isAnd bool, assigned map[variable]bool) Formula {
+	neutral, absorbing := True, False
+	if !isAnd {
+		neutral, absorbing = False, True
+	}
+	// Literals are simplified first: they are then considered true (resp. false) in the other subformulas.
+	simplified := make([]Formula, len(subs))
+	var added []variable // Vars added to assigned, that must be removed before returning
+	defer func() {
+		for _, v := range added {
+			delete(assigned, v)
+		}
+	}()
+	for i, sub := range subs {
+		if l, ok := sub.(lit); ok {
+			simplified[i] = simplify(l, assigned)
+		}
+	}
+	for _, sub := range simplified {
+		if l, ok := sub.(lit); ok {
+			val := l.signed != isAnd // Value making the literal true (resp. false)
+			if prev, ok := assigned[l.v]; ok {
+				if prev != val {
+					return absorbing
+				}
+				continue
+			}
+			assigned[l.v] = val
+			added = append(added, l.v)
+		}
+	}
+	for i, sub := range subs {
+		if simplified[i] == nil {
+			simplified[i] = simplify(sub, assigned)
+		}
+	}
+	// Flattening, constant folding and removal of duplicates
+	var res []Formula
+	var keys []string
+	seen := make(map[string]bool)
+	var add func(f Formula) bool
+	add = func(f Formula) bool {
+		switch f := f.(type) {
+		case trueConst, falseConst:
+			return f != absorbing
+		case and:
+			if isAnd {
+				for _, sub := range f {
+					if !add(sub) {
+						return false
+					}
+				}
+				return true
+			}
+		case or:
+			if !isAnd {
+				for _, sub := range f {
+					if !add(sub) {
+						return false
+					}
+				}
+				return true
+			}
+		case lit:
+			if seen[key(lit{v: f.v, signed: !f.signed})] {
+				return false
+			}
+		}
+		k := key(f)
+		if !seen[k] {
+			seen[k] = true
+			res = append(res, f)
+			keys = append(keys, k)
+		}
+		return true
+	}
+	for _, sub := range simplified {
+		if !add(sub) {
+			return absorbing
+		}
+	}
+	res = absorb(res, keys, isAnd)
+	switch len(res) {
+	case 0:
+		return neutral
+	case 1:
+		return res[0]
+	}
+	if isAnd {
+		return and(res)
+	}
+	return or(res)
+}
+
+// absorb removes the subformulas absorbed by another one from a conjunction, or a disjunction if isAnd is false.
+// In a conjunction, a disjunction is absorbed by any of its disjuncts,
+// and by any other disjunction whose disjuncts are a subset of its own disjuncts.
+// keys are the keys of the subformulas.
+func absorb(subs []Formula, keys []string, isAnd bool) []Formula {
+	// The operands of each subformula, as a set of keys. For subformulas that are not
+	// dual junctions, a singleton with the subformula itself.
+	operands := make([]map[string]bool, len(subs))
+	nbDual := 0
+	for i, sub := range subs {
+		var ops []Formula
+		switch sub := sub.(type) {
+		case and:
+			if !isAnd {
+				ops = sub
+			}
+		case or:
+			if isAnd {
+				ops = sub
+			}
+		}
+		operands[i] = make(map[string]bool)
+		if ops == nil {
+			operands[i][keys[i]] = true
+		} else {
+			nbDual++
+			for _, op := range ops {
+				operands[i][key(op)] = true
+			}
+		}
+	}
+	if nbDual == 0 {
+		return subs
+	}
+	// Only the subformulas containing the rarest operand of a subformula can be absorbed by it.
+	occs := make(map[string][]int) // For each operand, the subformulas it appears in
+	for i, ops := range operands {
+		for k := range ops {
+			occs[k] = append(occs[k], i)
+		}
+	}
+	absorbed := make([]bool, len(subs))
+	for j, ops := range operands {
+		if absorbed[j] { // Whatever j absorbs is also absorbed by what absorbed j
+			continue
+		}
+		var rarest []int
+		for k := range ops {
+			if rarest == nil || len(occs[k]) < len(rarest) {
+				rarest = occs[k]
+			}
+		}
+		for _, i := range rarest {
+			// Should both sets be identical, the first subformula is kept.
+			if i != j && !absorbed[i] && (len(ops) < len(operands[i]) || (len(ops) == len(operands[i]) && j < i)) &&
+				includes(operands[i], ops) {
+				absorbed[i] = true
+			}
+		}
+	}
+	var res []Formula
+	for i, sub := range subs {
+		if !absorbed[i] {
+			res = append(res, sub)
+		}
+	}
+	return res
+}
+
+// includes returns true iff set1 includes set2.
+func includes(set1, set2 map[string]bool) bool {
+	for k := range set2 {
+		if !set1[k] {
+			return false
+		}
+	}
+	return true
+}
+
+// simplifyLeq simplifies the given constraint, knowing the value of the variables in assigned.
+func simplifyLeq(l leq, assigned map[variable]bool) Formula {
+	var res leq
+	idx := make(map[string]int) // Index of each subformula in res
+	k := l.k
+	for i, sub := range l.subs {
+		sub = simplify(sub, assigned)
+		switch sub.(type) {
+		case trueConst:
+			k -= l.weights[i]
+			continue
+		case falseConst:
+			continue
+		}
+		kSub := key(sub)
+		if j, ok := idx[kSub]; ok {
+			res.weights[j] += l.weights[i]
+			continue
+		}
+		idx[kSub] = len(res.subs)
+		res.subs = append(res.subs, sub)
+		res.weights = append(res.weights, l.weights[i])
+	}
+	sum := 0
+	for _, w := range res.weights {
+		sum += w
+	}
+	switch {
+	case k < 0:
+		return False
+	case sum <= k:
+		return True
+	}
+	res.k = k
+	// Subformulas whose weight is greater than k cannot be true.
+	var forced []Formula
+	var rest leq
+	for i, sub := range res.subs {
+		if res.weights[i] > k {
+			forced = append(forced, not{sub}.nnf())
+		} else {
+			rest.subs = append(rest.subs, sub)
+			rest.weights = append(rest.weights, res.weights[i])
+		}
+	}
+	if len(forced) == 0 {
+		return res
+	}
+	if len(rest.subs) != 0 {
+		rest.k = k
+		forced = append(forced, rest)
+	}
+	return simplify(and(forced), assigned)
+}
+
+// key returns a string identifying the NNF formula f, independently of the order of the operands
+// of conjunctions and disjunctions.
+func key(f Formula) string {
+	switch f := f.(type) {
+	case lit:
+		res := strconv.Quote(f.v.name)
+		if f.v.dummy {
+			res = "?" + res
+		}
+		if f.signed {
+			res = "^" + res
+		}
+		return res
+	case and:
+		return "&(" + sortedKeys(f) + ")"
+	case or:
+		return "|(" + sortedKeys(f) + ")"
+	case leq:
+		terms := make([]string, len(f.subs))
+		for i, sub := range f.subs {
+			terms[i] = strconv.Itoa(f.weights[i]) + "*" + key(sub)
+		}
+		sort.Strings(terms)
+		return "leq(" + strconv.Itoa(f.k) + "," + strings.Join(terms, ",") + ")"
+	default:
+		return f.String()
+	}
+}
+
+func sortedKeys(subs []Formula) string {
+	keys := make([]string, len(subs))
+	for i, sub := range subs {
+		keys[i] = key(sub)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,50 @@
+package bmc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DoOR-Team/gophersat/aiger"
+	"github.com/DoOR-Team/gophersat/bf"
+)
+
+// FromAIG returns the transition system described by aig. Its variables are named after the inputs and
+// the latches of aig, as given by aig.InputName and aig.LatchName. Latches with an undetermined reset value
+// can have any value in the initial states. The property states that no target of aig is true;
+// invariant constraints must hold in all the states of a counterexample.
+// An error is returned if the name of an input or a latch ends with "'", as it would denote a next state variable.
+func FromAIG(aig *aiger.AIG) (*System, error) {
+	for i := range aig.Inputs {
+		if name := aig.InputName(i); strings.HasSuffix(name, "'") {
+			return nil, fmt.Errorf("invalid input name %q", name)
+		}
+	}
+	for i := range aig.Latches {
+		if name := aig.LatchName(i); strings.HasSuffix(name, "'") {
+			return nil, fmt.Errorf("invalid latch name %q", name)
+		}
+	}
+	nbLatches, nbConstrs := len(aig.Latches), len(aig.Constraints)
+	lits := make([]int, 0, nbLatches+nbConstrs+len(aig.Targets()))
+	for _, l := range aig.Latches {
+		lits = append(lits, l.Next)
+	}
+	lits = append(lits, aig.Constraints...)
+	lits = append(lits, aig.Targets()...)
+	fs := aig.Formulas(lits)
+	var init, trans []bf.Formula
+	for i, l := range aig.Latches {
+		name := aig.LatchName(i)
+		switch l.Reset {
+		case 0:
+			init = append(init, bf.Not(bf.Var(name)))
+		case 1:
+			init = append(init, bf.Var(name))
+		}
+		trans = append(trans, bf.Eq(bf.Var(Next(name)), fs[i]))
+	}
+	constrs := fs[nbLatches : nbLatches+nbConstrs]
+	trans = append(trans, constrs...)
+	bad := append(append([]bf.Formula(nil), constrs...), bf.Or(fs[nbLatches+nbConstrs:]...))
+	return &System{Init: bf.And(init...), Trans: bf.And(trans...), Prop: bf.Not(bf.And(bad...))}, nil
+}
@@ -0,0 +1,120 @@
+package bmc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DoOR-Team/gophersat/bf"
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// A System is a transition system.
+// Variable names must not end with "'", as such names denote the value of a variable in the next state.
+type System struct {
+	Init  bf.Formula // Initial states
+	Trans bf.Formula // Transition relation, between the variables of a state and their Next counterparts
+	Prop  bf.Formula // Property that must be true in all reachable states
+}
+
+// Next returns the name of the variable denoting the value of the variable name in the next state.
+func Next(name string) string {
+	return name + "'"
+}
+
+// A Checker looks for counterexamples of increasing length.
+type Checker struct {
+	sys   *System
+	enc   *bf.Encoder
+	bound int                 // Length of the counterexamples looked for by the next call to Step
+	names []map[string]string // For each step, the name in enc of each variable of the system
+	done  bool                // Is there no path of the current length at all?
+}
+
+// NewChecker returns a checker for sys.
+func NewChecker(sys *System) *Checker {
+	c := &Checker{sys: sys, enc: bf.NewEncoder()}
+	c.enc.Assert(c.at(sys.Init, 0))
+	return c
+}
+
+// Bound returns the number of transitions of the counterexamples the next call to Step will look for.
+func (c *Checker) Bound() int {
+	return c.bound
+}
+
+// Step looks for a counterexample of exactly c.Bound() transitions, and then increments the bound.
+// It returns the trace of the counterexample, or nil if there is none.
+func (c *Checker) Step() []map[string]bool {
+	k := c.bound
+	c.bound++
+	if c.done {
+		return nil
+	}
+	if k > 0 {
+		c.enc.Assert(c.at(c.sys.Trans, k-1))
+	}
+	prop := c.enc.Lit(c.at(c.sys.Prop, k))
+	s := c.enc.Solver
+	if s.SolveAssumptions([]solver.Lit{prop.Negation()}) == solver.Sat {
+		return c.trace(k)
+	}
+	if len(s.FailedAssumptions()) == 0 { // No path of k transitions: longer ones do not exist either
+		c.done = true
+		return nil
+	}
+	s.AppendClause(solver.NewClause([]solver.Lit{prop}))
+	return nil
+}
+
+// Check looks for a counterexample of at most maxDepth transitions, checking each bound in turn
+// with a single incremental solver. It returns the trace of the shortest counterexample, or nil if there is none.
+func Check(sys *System, maxDepth int) []map[string]bool {
+	c := NewChecker(sys)
+	for c.Bound() <= maxDepth && !c.done {
+		if trace := c.Step(); trace != nil {
+			return trace
+		}
+	}
+	return nil
+}
+
+// at returns f where each variable denotes its value at the given step, and its Next counterpart
+// denotes its value at the following step.
+func (c *Checker) at(f bf.Formula, step int) bf.Formula {
+	return bf.Rename(f, func(name string) string {
+		if base := strings.TrimSuffix(name, "'"); base != name {
+			return c.name(base, step+1)
+		}
+		return c.name(name, step)
+	})
+}
+
+// name returns the name, in the encoder, of the given variable at the given step.
+func (c *Checker) name(name string, step int) string {
+	for len(c.names) <= step {
+		c.names = append(c.names, make(map[string]string))
+	}
+	res, ok := c.names[step][name]
+	if !ok {
+		res = fmt.Sprintf("%d:%s", step, name)
+		c.names[step][name] = res
+	}
+	return res
+}
+
+// trace returns the states of the first steps in the last model found by the solver.
+// Variables whose value does not matter are false.
+func (c *Checker) trace(lastStep int) []map[string]bool {
+	model := c.enc.Model()
+	res := make([]map[string]bool, lastStep+1)
+	for step := range res {
+		res[step] = make(map[string]bool)
+		if step >= len(c.names) {
+			continue
+		}
+		for name, full := range c.names[step] {
+			res[step][name] = model[full]
+		}
+	}
+	return res
+}
@@ -0,0 +1,205 @@
+package bmc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DoOR-Team/gophersat/aiger"
+	"github.com/DoOR-Team/gophersat/bf"
+)
+
+// counter returns a 2-bit counter starting from 0, incremented when inc is true.
+// The property states that the counter never reaches 3.
+func counter() *System {
+	b0, b1, inc := bf.Var("b0"), bf.Var("b1"), bf.Var("inc")
+	return &System{
+		Init: bf.And(bf.Not(b0), bf.Not(b1)),
+		Trans: bf.And(
+			bf.Eq(bf.Var(Next("b0")), bf.Xor(b0, inc)),
+			bf.Eq(bf.Var(Next("b1")), bf.Xor(b1, bf.And(b0, inc))),
+		),
+		Prop: bf.Not(bf.And(b0, b1)),
+	}
+}
+
+func TestCheck(t *testing.T) {
+	sys := counter()
+	if trace := Check(sys, 2); trace != nil {
+		t.Errorf("expected no counterexample of length 2, got %v", trace)
+	}
+	trace := Check(sys, 10)
+	if len(trace) != 4 {
+		t.Fatalf("expected counterexample of length 3, got %v", trace)
+	}
+	for i, state := range trace {
+		val := 0
+		if state["b0"] {
+			val++
+		}
+		if state["b1"] {
+			val += 2
+		}
+		if val != i {
+			t.Errorf("invalid state #%d in %v", i, trace)
+		}
+		if i < 3 && !state["inc"] {
+			t.Errorf("inc should be true in state #%d in %v", i, trace)
+		}
+	}
+}
+
+func TestCheckSafe(t *testing.T) {
+	// A counter that cannot be incremented.
+	sys := counter()
+	sys.Trans = bf.And(sys.Trans, bf.Not(bf.Var("inc")))
+	if trace := Check(sys, 10); trace != nil {
+		t.Errorf("expected no counterexample, got %v", trace)
+	}
+	// No initial state.
+	sys = counter()
+	sys.Init = bf.And(sys.Init, bf.Var("b0"))
+	c := NewChecker(sys)
+	for c.Bound() < 5 {
+		if trace := c.Step(); trace != nil {
+			t.Errorf("expected no counterexample, got %v", trace)
+		}
+	}
+}
+
+// holds returns whether all lits are true.
+func holds(vals []bool, lits []int) bool {
+	for _, lit := range lits {
+		if !aiger.LitValue(vals, lit) {
+			return false
+		}
+	}
+	return true
+}
+
+// anyTrue returns whether at least one of lits is true.
+func anyTrue(vals []bool, lits []int) bool {
+	for _, lit := range lits {
+		if aiger.LitValue(vals, lit) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTrace checks that trace is a counterexample of aig, by simulating it.
+func checkTrace(aig *aiger.AIG, trace []map[string]bool) error {
+	var latches []bool
+	for step, state := range trace {
+		inputs, stateLatches := aig.Assignment(state)
+		if step == 0 {
+			for i, l := range aig.Latches {
+				if l.Reset != l.Lit && stateLatches[i] != (l.Reset == 1) {
+					return fmt.Errorf("invalid initial value for latch %d", i)
+				}
+			}
+		} else {
+			for i := range latches {
+				if latches[i] != stateLatches[i] {
+					return fmt.Errorf("step %d: invalid value for latch %d", step, i)
+				}
+			}
+		}
+		latches = stateLatches
+		vals := aig.Eval(inputs, latches)
+		if !holds(vals, aig.Constraints) {
+			return fmt.Errorf("step %d: constraint violated", step)
+		}
+		if step == len(trace)-1 && !anyTrue(vals, aig.Targets()) {
+			return fmt.Errorf("no bad state reached")
+		}
+		for i, l := range aig.Latches {
+			latches[i] = aiger.LitValue(vals, l.Next)
+		}
+	}
+	return nil
+}
+
+// aigs are circuits, with the length of their shortest counterexample, or -1 if they are safe.
+var aigs = []struct {
+	name    string
+	content string
+	depth   int
+}{
+	// The output is true when the latch is true and enable is false; the latch toggles when enable is true.
+	{"toggle", "aag 5 1 1 1 3\n2\n4 11 0\n6\n10 7 9\n6 4 3\n8 5 2\n", 1},
+	// A 2-bit counter incremented when the input is true, that must not reach 3.
+	{"counter", "aag 10 1 2 0 7 1\n2\n4 12\n6 18\n20\n8 4 2\n10 5 3\n12 9 11\n14 6 8\n16 7 9\n18 15 17\n20 4 6\n", 3},
+	// The same counter, with an invariant constraint stating the input is always false.
+	{"constrained counter", "aag 10 1 2 0 7 1 1\n2\n4 12\n6 18\n20\n3\n8 4 2\n10 5 3\n12 9 11\n14 6 8\n16 7 9\n18 15 17\n20 4 6\n", -1},
+	// A shift register fed by the input, whose last latch must stay false.
+	{"shift register", "aag 4 1 3 0 0 1\n2\n4 2\n6 4\n8 6\n8\n", 3},
+	// A latch with an undetermined initial value, that must be false.
+	{"uninitialized latch", "aag 1 0 1 0 0 1\n2 2 2\n2\n", 0},
+	// A latch that is false initially, and then always false.
+	{"constant latch", "aag 1 0 1 0 0 1\n2 0\n2\n", -1},
+	// The input must be true, and the bad state is reached when it is false.
+	{"contradictory constraint", "aag 1 1 0 0 0 1 1\n2\n3\n2\n", -1},
+}
+
+func TestFromAIG(t *testing.T) {
+	for _, test := range aigs {
+		aig, err := aiger.Parse(strings.NewReader(test.content))
+		if err != nil {
+			t.Fatalf("%s: could not parse AIG: %v", test.name, err)
+		}
+		sys, err := FromAIG(aig)
+		if err != nil {
+			t.Fatalf("%s: could not build transition system: %v", test.name, err)
+		}
+		trace := Check(sys, 10)
+		if len(trace)-1 != test.depth {
+			t.Errorf("%s: expected counterexample of length %d, got %v", test.name, test.depth, trace)
+		} else if trace != nil {
+			if err := checkTrace(aig, trace); err != nil {
+				t.Errorf("%s: invalid trace %v: %v", test.name, trace, err)
+			}
+		}
+	}
+}
+
+func TestFromAIGNames(t *testing.T) {
+	aig, err := aiger.Parse(strings.NewReader("aag 2 1 1 0 0 1\n2\n4 2\n4\ni0 x\nl0 x'\n"))
+	if err != nil {
+		t.Fatalf("could not parse AIG: %v", err)
+	}
+	if _, err := FromAIG(aig); err == nil {
+		t.Errorf("expected error for latch named after the next state of an input")
+	}
+}
+
+func ExampleCheck() {
+	// A toggle flip-flop, initially false: the latch changes its value when enable is true.
+	// The bad state is reached when the latch is true and enable is false.
+	const toggle = `aag 5 1 1 0 3 1
+2
+4 11 0
+6
+10 7 9
+6 4 3
+8 5 2
+i0 enable
+l0 state
+`
+	aig, err := aiger.Parse(strings.NewReader(toggle))
+	if err != nil {
+		fmt.Printf("could not parse AIG: %v\n", err)
+		return
+	}
+	sys, err := FromAIG(aig)
+	if err != nil {
+		fmt.Printf("could not build transition system: %v\n", err)
+		return
+	}
+	for step, state := range Check(sys, 10) {
+		fmt.Printf("step %d: state=%t enable=%t\n", step, state["state"], state["enable"])
+	}
+	// Output:
+	// step 0: state=false enable=true
+	// step 1: state=true enable=false
+}
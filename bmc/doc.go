@@ -0,0 +1,15 @@
+// Package bmc checks safety properties of transition systems through bounded model checking.
+//
+// A transition system is described by three formulas of the bf package, over named variables:
+// the initial states, the transition relation, where the value of a variable x in the next state
+// is the variable named Next(x), i.e "x'", and the property that must hold in all reachable states.
+// Variables that never appear primed, such as the inputs of a circuit, can have a different value at each step.
+//
+// The system is unrolled one step at a time in a single incremental solver: at bound k, the k'th transition
+// is added to the solver, and the solver looks for a state falsifying the property after exactly k transitions,
+// under the assumption that the property is false at that step. When there is none, the property is known to
+// hold at step k, which helps the solver at the following bounds. Clauses learned at a bound are kept for the next ones.
+//
+// A counterexample is returned as a trace: the value of each variable in each state, from an initial state to
+// a state falsifying the property. FromAIG builds the transition system described by an and-inverter graph.
+package bmc
@@ -0,0 +1,238 @@
+package bv
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/DoOR-Team/gophersat/bf"
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// A Bit is a boolean term. It is represented as a DIMACS literal:
+// a positive value is a variable, a negative value is the negation of a variable.
+type Bit int
+
+// Not returns the negation of b.
+func (b Bit) Not() Bit {
+	return -b
+}
+
+// Value returns the value of b in the given model.
+func (b Bit) Value(model []bool) bool {
+	if b < 0 {
+		return !model[-b-1]
+	}
+	return model[b-1]
+}
+
+// A BV is a bit-vector term. Bits are ordered from the least significant one to the most significant one.
+type BV []Bit
+
+// Width returns the number of bits of v.
+func (v BV) Width() int {
+	return len(v)
+}
+
+// Uint64 returns the value of v in the given model, as an unsigned integer.
+// If v has more than 64 bits, only the 64 least significant ones are considered.
+func (v BV) Uint64(model []bool) uint64 {
+	var res uint64
+	for i := len(v) - 1; i >= 0; i-- {
+		res <<= 1
+		if v[i].Value(model) {
+			res |= 1
+		}
+	}
+	return res
+}
+
+// Int64 returns the value of v in the given model, as a signed integer in two's complement.
+// If v has more than 64 bits, only the 64 least significant ones are considered.
+func (v BV) Int64(model []bool) int64 {
+	res := v.Uint64(model)
+	if w := len(v); w < 64 && w > 0 && v[w-1].Value(model) { // Sign extension
+		res |= ^uint64(0) << uint(w)
+	}
+	return int64(res)
+}
+
+// Big returns the value of v in the given model, as an unsigned integer.
+func (v BV) Big(model []bool) *big.Int {
+	res := new(big.Int)
+	for i, bit := range v {
+		if bit.Value(model) {
+			res.SetBit(res, i, 1)
+		}
+	}
+	return res
+}
+
+// gate is the key of an already created gate.
+type gate struct {
+	op   byte
+	a, b Bit
+	c    Bit
+}
+
+// A Builder creates bit-vector terms and the clauses defining them.
+type Builder struct {
+	nbVars  int
+	clauses [][]int
+	names   map[string]int // Names of the bits of variables, and their associated var
+	vars    map[string]BV  // Bit-vector variables
+	gates   map[gate]Bit   // Gates already created, to avoid duplicates
+	t       Bit            // Constant true
+}
+
+// NewBuilder returns a new builder.
+func NewBuilder() *Builder {
+	b := &Builder{names: make(map[string]int), vars: make(map[string]BV), gates: make(map[gate]Bit)}
+	b.t = b.newBit()
+	b.add(b.t)
+	return b
+}
+
+func (b *Builder) newBit() Bit {
+	b.nbVars++
+	return Bit(b.nbVars)
+}
+
+func (b *Builder) add(lits ...Bit) {
+	clause := make([]int, len(lits))
+	for i, lit := range lits {
+		clause[i] = int(lit)
+	}
+	b.clauses = append(b.clauses, clause)
+}
+
+// True returns the constant true.
+func (b *Builder) True() Bit {
+	return b.t
+}
+
+// False returns the constant false.
+func (b *Builder) False() Bit {
+	return -b.t
+}
+
+// Var returns a new bit-vector variable with the given name and width.
+// In the bf formula associated with the builder, its bits are named "name#0", "name#1", etc.
+// Will panic if the name is already in use.
+func (b *Builder) Var(name string, width int) BV {
+	if _, ok := b.vars[name]; ok {
+		panic(fmt.Errorf("duplicate variable name %q", name))
+	}
+	res := make(BV, width)
+	for i := range res {
+		res[i] = b.newBit()
+		b.names[name+"#"+strconv.Itoa(i)] = int(res[i])
+	}
+	b.vars[name] = res
+	return res
+}
+
+// BoolVar returns a new boolean variable with the given name.
+// Will panic if the name is already in use.
+func (b *Builder) BoolVar(name string) Bit {
+	return b.Var(name, 1)[0]
+}
+
+// Const returns the given constant, on width bits.
+func (b *Builder) Const(val uint64, width int) BV {
+	res := make(BV, width)
+	for i := range res {
+		res[i] = b.constBit(i < 64 && val&(1<<uint(i)) != 0)
+	}
+	return res
+}
+
+// BigConst returns the given constant, on width bits.
+// If val is negative, its two's complement representation is used.
+func (b *Builder) BigConst(val *big.Int, width int) BV {
+	res := make(BV, width)
+	for i := range res {
+		res[i] = b.constBit(val.Bit(i) == 1)
+	}
+	return res
+}
+
+func (b *Builder) constBit(val bool) Bit {
+	if val {
+		return b.t
+	}
+	return -b.t
+}
+
+// Assert adds the constraint stating all given bits are true.
+func (b *Builder) Assert(bits ...Bit) {
+	for _, bit := range bits {
+		if bit != b.t {
+			b.add(bit)
+		}
+	}
+}
+
+// NbVars returns the number of boolean variables used so far.
+func (b *Builder) NbVars() int {
+	return b.nbVars
+}
+
+// Clauses returns the clauses generated so far, as DIMACS clauses.
+func (b *Builder) Clauses() [][]int {
+	return b.clauses
+}
+
+// Problem returns the problem made of the clauses generated so far.
+func (b *Builder) Problem() *solver.Problem {
+	return solver.ParseSliceNb(b.clauses, b.nbVars)
+}
+
+// Solve solves the problem made of the clauses generated so far.
+// It returns a model, or nil if the problem is unsatisfiable.
+func (b *Builder) Solve() []bool {
+	s := solver.New(b.Problem())
+	if s.Solve() != solver.Sat {
+		return nil
+	}
+	return s.Model()
+}
+
+// Formula returns the bf formula made of the clauses generated so far.
+// Bits of variables are named after their variable, other bits are named "bv#1", "bv#2", etc.
+func (b *Builder) Formula() bf.Formula {
+	names := make([]string, b.nbVars+1)
+	for i := range names {
+		names[i] = "bv#" + strconv.Itoa(i)
+	}
+	for name, v := range b.names {
+		names[v] = name
+	}
+	res := make([]bf.Formula, len(b.clauses))
+	for i, clause := range b.clauses {
+		lits := make([]bf.Formula, len(clause))
+		for j, lit := range clause {
+			if lit < 0 {
+				lits[j] = bf.Not(bf.Var(names[-lit]))
+			} else {
+				lits[j] = bf.Var(names[lit])
+			}
+		}
+		res[i] = bf.Or(lits...)
+	}
+	return bf.And(res...)
+}
+
+// Decode translates a model of the formula returned by Formula, as returned by bf.Solve,
+// to a model that can be used to get the value of bits and bit-vectors.
+// Variables that do not appear in the bf model are considered false.
+func (b *Builder) Decode(model map[string]bool) []bool {
+	res := make([]bool, b.nbVars)
+	for i := range res {
+		res[i] = model["bv#"+strconv.Itoa(i+1)]
+	}
+	for name, v := range b.names {
+		res[v-1] = model[name]
+	}
+	return res
+}
@@ -0,0 +1,165 @@
+package bv
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/DoOR-Team/gophersat/bf"
+)
+
+const testWidth = 5
+
+// signed returns the value of x, on testWidth bits, as a signed integer.
+func signed(x uint64) int64 {
+	if x&(1<<(testWidth-1)) != 0 {
+		return int64(x) - 1<<testWidth
+	}
+	return int64(x)
+}
+
+func boolToUint(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+var binOps = []struct {
+	name     string
+	op       func(b *Builder, x, y BV) BV
+	expected func(x, y uint64) uint64
+}{
+	{"add", (*Builder).Add, func(x, y uint64) uint64 { return x + y }},
+	{"sub", (*Builder).Sub, func(x, y uint64) uint64 { return x - y }},
+	{"mul", (*Builder).Mul, func(x, y uint64) uint64 { return x * y }},
+	{"and", (*Builder).And, func(x, y uint64) uint64 { return x & y }},
+	{"or", (*Builder).Or, func(x, y uint64) uint64 { return x | y }},
+	{"xor", (*Builder).Xor, func(x, y uint64) uint64 { return x ^ y }},
+	{"neg", func(b *Builder, x, y BV) BV { return b.Neg(x) }, func(x, y uint64) uint64 { return -x }},
+	{"not", func(b *Builder, x, y BV) BV { return b.Not(x) }, func(x, y uint64) uint64 { return ^x }},
+	{"shl", (*Builder).ShlVar, func(x, y uint64) uint64 { return x << y }},
+	{"lshr", (*Builder).LshrVar, func(x, y uint64) uint64 { return x >> y }},
+	{"ashr", (*Builder).AshrVar, func(x, y uint64) uint64 { return uint64(signed(x) >> y) }},
+	{"shl3", func(b *Builder, x, y BV) BV { return b.Shl(x, 3) }, func(x, y uint64) uint64 { return x << 3 }},
+	{"ashr2", func(b *Builder, x, y BV) BV { return b.Ashr(x, 2) }, func(x, y uint64) uint64 { return uint64(signed(x) >> 2) }},
+	{"ite", func(b *Builder, x, y BV) BV { return b.Ite(b.Ult(x, y), x, y) }, func(x, y uint64) uint64 {
+		if x < y {
+			return x
+		}
+		return y
+	}},
+	{"extract-concat", func(b *Builder, x, y BV) BV { return b.Concat(b.Extract(y, 1, 0), b.Extract(x, 4, 2)) }, func(x, y uint64) uint64 { return (y&3)<<3 | x>>2 }},
+	{"zeroext", func(b *Builder, x, y BV) BV { return b.Extract(b.ZeroExt(x, 2), 6, 2) }, func(x, y uint64) uint64 { return x >> 2 }},
+	{"signext", func(b *Builder, x, y BV) BV { return b.Extract(b.SignExt(x, 2), 6, 2) }, func(x, y uint64) uint64 { return uint64(signed(x) >> 2) }},
+}
+
+var cmpOps = []struct {
+	name     string
+	op       func(b *Builder, x, y BV) Bit
+	expected func(x, y uint64) bool
+}{
+	{"eq", (*Builder).Eq, func(x, y uint64) bool { return x == y }},
+	{"neq", (*Builder).Neq, func(x, y uint64) bool { return x != y }},
+	{"ult", (*Builder).Ult, func(x, y uint64) bool { return x < y }},
+	{"ule", (*Builder).Ule, func(x, y uint64) bool { return x <= y }},
+	{"ugt", (*Builder).Ugt, func(x, y uint64) bool { return x > y }},
+	{"uge", (*Builder).Uge, func(x, y uint64) bool { return x >= y }},
+	{"slt", (*Builder).Slt, func(x, y uint64) bool { return signed(x) < signed(y) }},
+	{"sle", (*Builder).Sle, func(x, y uint64) bool { return signed(x) <= signed(y) }},
+	{"sgt", (*Builder).Sgt, func(x, y uint64) bool { return signed(x) > signed(y) }},
+	{"sge", (*Builder).Sge, func(x, y uint64) bool { return signed(x) >= signed(y) }},
+}
+
+// operands returns x and y, either as constants or as variables bound to the constant values.
+func operands(b *Builder, x, y uint64, consts bool) (BV, BV) {
+	if consts {
+		return b.Const(x, testWidth), b.Const(y, testWidth)
+	}
+	xVar, yVar := b.Var("x", testWidth), b.Var("y", testWidth)
+	b.Assert(b.Eq(xVar, b.Const(x, testWidth)), b.Eq(yVar, b.Const(y, testWidth)))
+	return xVar, yVar
+}
+
+func TestOps(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	const mask = 1<<testWidth - 1
+	for iter := 0; iter < 50; iter++ {
+		x, y := uint64(r.Intn(1<<testWidth)), uint64(r.Intn(1<<testWidth))
+		for _, consts := range []bool{false, true} {
+			for _, op := range binOps {
+				b := NewBuilder()
+				xBV, yBV := operands(b, x, y, consts)
+				res := op.op(b, xBV, yBV)
+				model := b.Solve()
+				if model == nil {
+					t.Fatalf("%s(%d, %d): expected a model", op.name, x, y)
+				}
+				if got, expected := res.Uint64(model), op.expected(x, y)&mask; got != expected {
+					t.Errorf("%s(%d, %d): expected %d, got %d", op.name, x, y, expected, got)
+				}
+			}
+			for _, op := range cmpOps {
+				b := NewBuilder()
+				xBV, yBV := operands(b, x, y, consts)
+				res := op.op(b, xBV, yBV)
+				model := b.Solve()
+				if model == nil {
+					t.Fatalf("%s(%d, %d): expected a model", op.name, x, y)
+				}
+				if got, expected := res.Value(model), op.expected(x, y); got != expected {
+					t.Errorf("%s(%d, %d): expected %t, got %t", op.name, x, y, expected, got)
+				}
+			}
+		}
+	}
+}
+
+func TestFormula(t *testing.T) {
+	b := NewBuilder()
+	x, y := b.Var("x", 8), b.Var("y", 8)
+	b.Assert(b.Eq(b.Add(x, y), b.Const(200, 8)), b.Eq(b.Sub(x, y), b.Const(20, 8)), b.Ult(x, b.Const(128, 8)))
+	model := bf.Solve(b.Formula())
+	if model == nil {
+		t.Fatalf("expected a model")
+	}
+	decoded := b.Decode(model)
+	if xVal, yVal := x.Uint64(decoded), y.Uint64(decoded); xVal != 110 || yVal != 90 {
+		t.Errorf("expected x=110, y=90, got x=%d, y=%d", xVal, yVal)
+	}
+	if !model["x#1"] || model["x#0"] {
+		t.Errorf("invalid bits for x in %v", model)
+	}
+	b.Assert(b.Neq(x, b.Const(110, 8)))
+	if model := bf.Solve(b.Formula()); model != nil {
+		t.Errorf("expected no model, got %v", model)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	b := NewBuilder()
+	val, _ := new(big.Int).SetString("-12345678901234567890123", 10)
+	x := b.Var("x", 100)
+	b.Assert(b.Eq(x, b.BigConst(val, 100)))
+	model := b.Solve()
+	expected := new(big.Int).Add(val, new(big.Int).Lsh(big.NewInt(1), 100))
+	if got := x.Big(model); got.Cmp(expected) != 0 {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if got := b.SignExt(b.Extract(x, 9, 0), 20).Int64(model); got != -203 {
+		t.Errorf("expected -203, got %d", got)
+	}
+}
+
+func ExampleBuilder() {
+	b := NewBuilder()
+	x := b.Var("x", 8)
+	y := b.Var("y", 8)
+	prod := b.Mul(b.ZeroExt(x, 8), b.ZeroExt(y, 8))
+	b.Assert(b.Eq(prod, b.Const(143, 16)), b.Ult(b.Const(1, 8), x), b.Ult(x, y))
+	if model := b.Solve(); model != nil {
+		fmt.Println(x.Uint64(model), y.Uint64(model))
+	}
+	// Output: 11 13
+}
@@ -0,0 +1,25 @@
+// Package bv offers fixed-width bit-vector arithmetic, translated to SAT through bit-blasting.
+//
+// A Builder creates bit-vector terms (variables and constants) and combines them with the usual
+// operators: addition, subtraction, multiplication, shifts, bitwise operators, extraction and concatenation.
+// Comparisons return a Bit, i.e a boolean term, that can be asserted:
+//
+//	b := bv.NewBuilder()
+//	x := b.Var("x", 8)
+//	y := b.Var("y", 8)
+//	prod := b.Mul(b.ZeroExt(x, 8), b.ZeroExt(y, 8)) // 16 bits, so that the product cannot overflow
+//	b.Assert(b.Eq(prod, b.Const(143, 16)), b.Ult(b.Const(1, 8), x), b.Ult(x, y))
+//	if model := b.Solve(); model != nil {
+//		fmt.Println(x.Uint64(model), y.Uint64(model)) // 11 13
+//	}
+//
+// Each operator creates new boolean variables for its output bits, defined by clauses on its inputs
+// (the Tseitin transformation), so the size of the generated problem is linear in the size of the circuit.
+// Operations on constants are simplified away, and identical gates are shared.
+//
+// As usual in SMT-LIB, arithmetic is modular, i.e results are truncated to the width of the operands,
+// and operators whose name starts with S (Slt, Sle, Ashr...) interpret their operands as signed integers,
+// in two's complement.
+//
+// The generated clauses can be retrieved as a slice of DIMACS clauses, a solver.Problem, or a bf.Formula.
+package bv
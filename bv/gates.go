@@ -0,0 +1,153 @@
+package bv
+
+// Gate operators, used as keys in Builder.gates.
+const (
+	opAnd = iota
+	opXor
+	opIte
+)
+
+// and2 returns a bit that is true iff x and y are both true.
+func (b *Builder) and2(x, y Bit) Bit {
+	switch {
+	case x == -b.t || y == -b.t || x == -y:
+		return -b.t
+	case x == b.t || x == y:
+		return y
+	case y == b.t:
+		return x
+	}
+	if x > y {
+		x, y = y, x
+	}
+	key := gate{op: opAnd, a: x, b: y}
+	if res, ok := b.gates[key]; ok {
+		return res
+	}
+	res := b.newBit()
+	b.add(-res, x)
+	b.add(-res, y)
+	b.add(res, -x, -y)
+	b.gates[key] = res
+	return res
+}
+
+// or2 returns a bit that is true iff x or y is true.
+func (b *Builder) or2(x, y Bit) Bit {
+	return -b.and2(-x, -y)
+}
+
+// xor2 returns a bit that is true iff exactly one of x and y is true.
+func (b *Builder) xor2(x, y Bit) Bit {
+	switch {
+	case x == -b.t:
+		return y
+	case y == -b.t:
+		return x
+	case x == b.t:
+		return -y
+	case y == b.t:
+		return -x
+	case x == y:
+		return -b.t
+	case x == -y:
+		return b.t
+	}
+	// Normalize, so that equivalent gates share the same key: xor(-x, y) = -xor(x, y)
+	sign := Bit(1)
+	if x < 0 {
+		x, sign = -x, -sign
+	}
+	if y < 0 {
+		y, sign = -y, -sign
+	}
+	if x > y {
+		x, y = y, x
+	}
+	key := gate{op: opXor, a: x, b: y}
+	if res, ok := b.gates[key]; ok {
+		return sign * res
+	}
+	res := b.newBit()
+	b.add(-res, x, y)
+	b.add(-res, -x, -y)
+	b.add(res, -x, y)
+	b.add(res, x, -y)
+	b.gates[key] = res
+	return sign * res
+}
+
+// ite returns a bit that is equal to x if c is true, to y else.
+func (b *Builder) ite(c, x, y Bit) Bit {
+	switch {
+	case c == b.t || x == y:
+		return x
+	case c == -b.t:
+		return y
+	case x == b.t || c == x:
+		return b.or2(c, y)
+	case x == -b.t || c == -x:
+		return b.and2(-c, y)
+	case y == b.t || c == -y:
+		return b.or2(-c, x)
+	case y == -b.t || c == y:
+		return b.and2(c, x)
+	}
+	if c < 0 {
+		c, x, y = -c, y, x
+	}
+	key := gate{op: opIte, a: x, b: y, c: c}
+	if res, ok := b.gates[key]; ok {
+		return res
+	}
+	res := b.newBit()
+	b.add(-c, -x, res)
+	b.add(-c, x, -res)
+	b.add(c, -y, res)
+	b.add(c, y, -res)
+	b.add(-x, -y, res) // Redundant, but helps propagation
+	b.add(x, y, -res)
+	b.gates[key] = res
+	return res
+}
+
+// fullAdder returns the sum and the carry of x + y + c.
+func (b *Builder) fullAdder(x, y, c Bit) (sum, carry Bit) {
+	xy := b.xor2(x, y)
+	sum = b.xor2(xy, c)
+	carry = b.or2(b.and2(x, y), b.and2(c, xy))
+	return sum, carry
+}
+
+// All returns a bit that is true iff all given bits are true.
+func (b *Builder) All(bits ...Bit) Bit {
+	res := b.t
+	for _, bit := range bits {
+		res = b.and2(res, bit)
+	}
+	return res
+}
+
+// Any returns a bit that is true iff at least one of the given bits is true.
+func (b *Builder) Any(bits ...Bit) Bit {
+	res := -b.t
+	for _, bit := range bits {
+		res = b.or2(res, bit)
+	}
+	return res
+}
+
+// Iff returns a bit that is true iff x and y have the same value.
+func (b *Builder) Iff(x, y Bit) Bit {
+	return -b.xor2(x, y)
+}
+
+// Implies returns a bit that is true iff x implies y.
+func (b *Builder) Implies(x, y Bit) Bit {
+	return b.or2(-x, y)
+}
+
+// IteBit returns a bit that is equal to x if c is true, to y else.
+func (b *Builder) IteBit(c, x, y Bit) Bit {
+	return b.ite(c, x, y)
+}
@@ -0,0 +1,273 @@
+package bv
+
+// checkWidths panics if x and y do not have the same width.
+func checkWidths(x, y BV) {
+	if len(x) != len(y) {
+		panic("bit-vectors of different widths")
+	}
+}
+
+// Not returns the bitwise negation of x.
+func (b *Builder) Not(x BV) BV {
+	res := make(BV, len(x))
+	for i := range x {
+		res[i] = -x[i]
+	}
+	return res
+}
+
+// And returns the bitwise conjunction of x and y.
+func (b *Builder) And(x, y BV) BV {
+	checkWidths(x, y)
+	res := make(BV, len(x))
+	for i := range x {
+		res[i] = b.and2(x[i], y[i])
+	}
+	return res
+}
+
+// Or returns the bitwise disjunction of x and y.
+func (b *Builder) Or(x, y BV) BV {
+	checkWidths(x, y)
+	res := make(BV, len(x))
+	for i := range x {
+		res[i] = b.or2(x[i], y[i])
+	}
+	return res
+}
+
+// Xor returns the bitwise exclusive disjunction of x and y.
+func (b *Builder) Xor(x, y BV) BV {
+	checkWidths(x, y)
+	res := make(BV, len(x))
+	for i := range x {
+		res[i] = b.xor2(x[i], y[i])
+	}
+	return res
+}
+
+// Ite returns x if c is true, y else.
+func (b *Builder) Ite(c Bit, x, y BV) BV {
+	checkWidths(x, y)
+	res := make(BV, len(x))
+	for i := range x {
+		res[i] = b.ite(c, x[i], y[i])
+	}
+	return res
+}
+
+// addCarry returns x + y + c, and the final carry.
+func (b *Builder) addCarry(x, y BV, c Bit) (BV, Bit) {
+	checkWidths(x, y)
+	res := make(BV, len(x))
+	for i := range x {
+		res[i], c = b.fullAdder(x[i], y[i], c)
+	}
+	return res, c
+}
+
+// Add returns x + y.
+func (b *Builder) Add(x, y BV) BV {
+	res, _ := b.addCarry(x, y, -b.t)
+	return res
+}
+
+// Sub returns x - y.
+func (b *Builder) Sub(x, y BV) BV {
+	res, _ := b.addCarry(x, b.Not(y), b.t)
+	return res
+}
+
+// Neg returns -x.
+func (b *Builder) Neg(x BV) BV {
+	return b.Sub(b.Const(0, len(x)), x)
+}
+
+// Mul returns x * y.
+func (b *Builder) Mul(x, y BV) BV {
+	checkWidths(x, y)
+	res := b.Const(0, len(x))
+	for i := range y {
+		// Add (x << i) if y[i] is true. The i lowest bits are 0, so they do not change res.
+		partial := make(BV, len(x)-i)
+		for j := range partial {
+			partial[j] = b.and2(x[j], y[i])
+		}
+		sum, _ := b.addCarry(res[i:], partial, -b.t)
+		res = append(res[:i:i], sum...)
+	}
+	return res
+}
+
+// Shl returns x shifted to the left by n bits.
+func (b *Builder) Shl(x BV, n int) BV {
+	res := make(BV, len(x))
+	for i := range res {
+		if i >= n {
+			res[i] = x[i-n]
+		} else {
+			res[i] = -b.t
+		}
+	}
+	return res
+}
+
+// Lshr returns x logically shifted to the right by n bits: the most significant bits are 0.
+func (b *Builder) Lshr(x BV, n int) BV {
+	return b.shr(x, n, -b.t)
+}
+
+// Ashr returns x arithmetically shifted to the right by n bits: the most significant bits are copies of the sign bit.
+func (b *Builder) Ashr(x BV, n int) BV {
+	if len(x) == 0 {
+		return BV{}
+	}
+	return b.shr(x, n, x[len(x)-1])
+}
+
+// shr returns x shifted to the right by n bits, the most significant bits being fill.
+func (b *Builder) shr(x BV, n int, fill Bit) BV {
+	res := make(BV, len(x))
+	for i := range res {
+		if i+n < len(x) {
+			res[i] = x[i+n]
+		} else {
+			res[i] = fill
+		}
+	}
+	return res
+}
+
+// shiftVar returns x shifted by the value of n, thanks to a barrel shifter.
+// shift is the function shifting by a constant, and fill is the value of the bits
+// when n is bigger than the width of x.
+func (b *Builder) shiftVar(x, n BV, shift func(x BV, n int) BV, fill Bit) BV {
+	res := x
+	tooBig := -b.t // Is n at least len(x)?
+	for i, bit := range n {
+		if i >= 62 || 1<<uint(i) >= len(x) {
+			tooBig = b.or2(tooBig, bit)
+			continue
+		}
+		res = b.Ite(bit, shift(res, 1<<uint(i)), res)
+	}
+	filled := make(BV, len(x))
+	for i := range filled {
+		filled[i] = fill
+	}
+	return b.Ite(tooBig, filled, res)
+}
+
+// ShlVar returns x shifted to the left by the value of n, as an unsigned integer.
+func (b *Builder) ShlVar(x, n BV) BV {
+	return b.shiftVar(x, n, b.Shl, -b.t)
+}
+
+// LshrVar returns x logically shifted to the right by the value of n, as an unsigned integer.
+func (b *Builder) LshrVar(x, n BV) BV {
+	return b.shiftVar(x, n, b.Lshr, -b.t)
+}
+
+// AshrVar returns x arithmetically shifted to the right by the value of n, as an unsigned integer.
+func (b *Builder) AshrVar(x, n BV) BV {
+	if len(x) == 0 {
+		return BV{}
+	}
+	return b.shiftVar(x, n, b.Ashr, x[len(x)-1])
+}
+
+// Extract returns the bits of x between lo and hi, inclusive.
+// Will panic if the bounds are invalid.
+func (b *Builder) Extract(x BV, hi, lo int) BV {
+	if lo < 0 || hi < lo || hi >= len(x) {
+		panic("invalid bounds for extraction")
+	}
+	return append(BV{}, x[lo:hi+1]...)
+}
+
+// Concat returns the concatenation of hi and lo, hi being the most significant bits.
+func (b *Builder) Concat(hi, lo BV) BV {
+	res := make(BV, 0, len(hi)+len(lo))
+	return append(append(res, lo...), hi...)
+}
+
+// ZeroExt returns x, extended with n most significant bits that are 0.
+func (b *Builder) ZeroExt(x BV, n int) BV {
+	return b.Concat(b.Const(0, n), x)
+}
+
+// SignExt returns x, extended with n most significant bits that are copies of its sign bit.
+func (b *Builder) SignExt(x BV, n int) BV {
+	res := append(BV{}, x...)
+	for i := 0; i < n; i++ {
+		res = append(res, x[len(x)-1])
+	}
+	return res
+}
+
+// Eq returns a bit that is true iff x and y are equal.
+func (b *Builder) Eq(x, y BV) Bit {
+	checkWidths(x, y)
+	res := b.t
+	for i := range x {
+		res = b.and2(res, -b.xor2(x[i], y[i]))
+	}
+	return res
+}
+
+// Neq returns a bit that is true iff x and y are different.
+func (b *Builder) Neq(x, y BV) Bit {
+	return -b.Eq(x, y)
+}
+
+// Ult returns a bit that is true iff x < y, as unsigned integers.
+func (b *Builder) Ult(x, y BV) Bit {
+	// x - y borrows iff x < y, i.e x + not(y) + 1 does not carry.
+	_, carry := b.addCarry(x, b.Not(y), b.t)
+	return -carry
+}
+
+// Ule returns a bit that is true iff x <= y, as unsigned integers.
+func (b *Builder) Ule(x, y BV) Bit {
+	return -b.Ult(y, x)
+}
+
+// Ugt returns a bit that is true iff x > y, as unsigned integers.
+func (b *Builder) Ugt(x, y BV) Bit {
+	return b.Ult(y, x)
+}
+
+// Uge returns a bit that is true iff x >= y, as unsigned integers.
+func (b *Builder) Uge(x, y BV) Bit {
+	return -b.Ult(x, y)
+}
+
+// flipSign returns x, with its sign bit negated.
+// Comparing signed integers is the same as comparing unsigned integers after flipping the sign bits.
+func flipSign(x BV) BV {
+	res := append(BV{}, x...)
+	if len(res) > 0 {
+		res[len(res)-1] = -res[len(res)-1]
+	}
+	return res
+}
+
+// Slt returns a bit that is true iff x < y, as signed integers.
+func (b *Builder) Slt(x, y BV) Bit {
+	return b.Ult(flipSign(x), flipSign(y))
+}
+
+// Sle returns a bit that is true iff x <= y, as signed integers.
+func (b *Builder) Sle(x, y BV) Bit {
+	return -b.Slt(y, x)
+}
+
+// Sgt returns a bit that is true iff x > y, as signed integers.
+func (b *Builder) Sgt(x, y BV) Bit {
+	return b.Slt(y, x)
+}
+
+// Sge returns a bit that is true iff x >= y, as signed integers.
+func (b *Builder) Sge(x, y BV) Bit {
+	return -b.Slt(x, y)
+}
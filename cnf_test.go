@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"os"
 	"testing"
+
+	"github.com/DoOR-Team/gophersat/input"
+	"github.com/DoOR-Team/gophersat/solver"
 )
 
 var (
@@ -15,11 +18,17 @@ var (
 )
 
 func testCnf(path string) {
-	if pb, printFn, err := parse(path); err != nil {
+	f, err := input.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not open problem: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	if pb, printFn, err := parse(f, input.CNF); err != nil {
 		fmt.Fprintf(os.Stderr, "could not parse problem: %v\n", err)
 		os.Exit(1)
 	} else {
-		solve(pb, verbose, cert, printFn)
+		solve(pb, verbose, cert, solver.LinearSearch, printFn)
 	}
 }
 
@@ -0,0 +1,17 @@
+// Package dimacs reads problems in the DIMACS CNF format.
+//
+// A DIMACS CNF file starts with a header "p cnf nbVars nbClauses", followed by clauses.
+// Each clause is a list of non-null literals ended by a 0, and can span several lines.
+// Lines starting with "c" are comments.
+//
+// Clauses are read one at a time by a Reader, so that huge problems can be processed without storing them entirely,
+// or by Read, that calls a function for each clause. Parse reads the whole problem and returns it exactly as written:
+// unlike solver.ParseCNF, it does not simplify the problem, so duplicate literals, tautologies,
+// unit and empty clauses are kept in their original order.
+//
+// The Strict mode enforces the format: the header is mandatory, literals must not exceed the declared number of variables,
+// the number of clauses must be the declared one and the last clause must be ended by a 0.
+// The Lenient mode accepts all these deviations, which are common in files found in the wild, as well as
+// a "%" line marking the end of the problem, as found in the SATLIB benchmarks.
+// In both modes, errors indicate the line and column where they occurred.
+package dimacs
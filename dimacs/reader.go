@@ -0,0 +1,362 @@
+package dimacs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/DoOR-Team/gophersat/input"
+)
+
+// A Mode tells how strictly the DIMACS format is enforced.
+type Mode int
+
+const (
+	// Strict rejects any deviation from the DIMACS CNF format.
+	Strict Mode = iota
+	// Lenient accepts a missing header, literals beyond the declared number of variables,
+	// a number of clauses different from the declared one, an unterminated last clause and a final "%" line.
+	Lenient
+)
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case Strict:
+		return "strict"
+	case Lenient:
+		return "lenient"
+	default:
+		return fmt.Sprintf("Mode(%d)", int(m))
+	}
+}
+
+// An Error is an error found while reading a problem, at a given position.
+type Error struct {
+	Line   int // Line of the error, starting at 1
+	Column int // Column of the error, starting at 1
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// A token is a sequence of non-space characters.
+type token struct {
+	text      string
+	line, col int
+	first     bool // Is it the first token on its line?
+}
+
+// A Reader reads the clauses of a DIMACS CNF problem one at a time.
+type Reader struct {
+	br        *bufio.Reader
+	mode      Mode
+	line, col int    // Position of the next byte
+	tokLine   int    // Line of the last token read
+	eol       bool   // Was the last token followed by a newline?
+	pending   *token // Token read but not consumed yet
+	header    bool   // Was the header read?
+	started   bool   // Was the beginning of the problem, up to the header, read?
+	nbVars    int
+	nbClauses int
+	nbRead    int   // Number of clauses read so far
+	done      bool  // Was the end of the problem reached?
+	err       error // First error met, returned by all subsequent calls
+}
+
+// NewReader returns a reader reading a problem from r in the given mode.
+func NewReader(r io.Reader, mode Mode) *Reader {
+	return &Reader{br: bufio.NewReader(r), mode: mode, line: 1, col: 1, nbClauses: -1}
+}
+
+// NbVars returns the number of variables of the problem.
+// This is the declared number of variables, or in Lenient mode, the highest variable read so far if it is higher.
+func (r *Reader) NbVars() int {
+	return r.nbVars
+}
+
+// NbClauses returns the number of clauses declared in the header, or -1 if no header was read.
+func (r *Reader) NbClauses() int {
+	return r.nbClauses
+}
+
+// ReadHeader reads the comments and the header at the beginning of the problem, and returns the declared number
+// of variables and clauses. In Lenient mode, if there is no header, it returns 0 and -1.
+// Calling it is optional: Next reads the header if it was not read yet.
+func (r *Reader) ReadHeader() (nbVars, nbClauses int, err error) {
+	if r.started || r.err != nil {
+		return r.nbVars, r.nbClauses, r.err
+	}
+	r.started = true
+	t, err := r.next()
+	switch {
+	case err == io.EOF:
+		if r.mode == Strict {
+			r.err = r.errorf(r.line, r.col, "missing header")
+		}
+	case err != nil:
+		r.err = err
+	case t.first && t.text == "p":
+		r.err = r.parseHeader(t)
+	case r.mode == Strict:
+		r.err = r.errorf(t.line, t.col, "expected header, found %q", t.text)
+	default:
+		r.pending = &t
+	}
+	return r.nbVars, r.nbClauses, r.err
+}
+
+// parseHeader parses the fields of the header, p being the token starting it.
+func (r *Reader) parseHeader(p token) error {
+	var fields []token
+	for {
+		t, err := r.scan()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if t.line != p.line {
+			r.pending = &t
+			break
+		}
+		fields = append(fields, t)
+	}
+	if len(fields) == 0 || fields[0].text != "cnf" {
+		return r.errorf(p.line, p.col, `expected "p cnf nbVars nbClauses" header`)
+	}
+	if len(fields) < 3 {
+		return r.errorf(p.line, p.col, "missing number of %s in header", []string{"variables", "clauses"}[len(fields)-1])
+	}
+	if len(fields) > 3 {
+		return r.errorf(fields[3].line, fields[3].col, "expected end of header, found %q", fields[3].text)
+	}
+	nbVars, err := strconv.Atoi(fields[1].text)
+	if err != nil || nbVars < 0 || nbVars > math.MaxInt32 {
+		return r.errorf(fields[1].line, fields[1].col, "invalid number of variables %q", fields[1].text)
+	}
+	nbClauses, err := strconv.Atoi(fields[2].text)
+	if err != nil || nbClauses < 0 {
+		return r.errorf(fields[2].line, fields[2].col, "invalid number of clauses %q", fields[2].text)
+	}
+	r.header = true
+	r.nbVars, r.nbClauses = nbVars, nbClauses
+	return nil
+}
+
+// Next returns the next clause of the problem, as a list of literals without the final 0.
+// Literals are returned exactly as they were written, so clauses can contain duplicate literals or be tautologies.
+// At the end of the problem, it returns io.EOF.
+func (r *Reader) Next() ([]int, error) {
+	if _, _, err := r.ReadHeader(); err != nil {
+		return nil, err
+	}
+	if r.done {
+		return nil, io.EOF
+	}
+	clause, err := r.readClause()
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return clause, err
+}
+
+func (r *Reader) readClause() ([]int, error) {
+	var clause []int
+	for {
+		t, err := r.next()
+		if err == io.EOF || (err == nil && t.first && t.text == "%" && r.mode == Lenient) {
+			return r.end(clause)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if t.first && t.text == "p" {
+			if r.header {
+				return nil, r.errorf(t.line, t.col, "duplicate header")
+			}
+			return nil, r.errorf(t.line, t.col, "header after clauses")
+		}
+		lit, err := strconv.Atoi(t.text)
+		if err != nil {
+			return nil, r.errorf(t.line, t.col, "expected literal, found %q", t.text)
+		}
+		if lit == 0 {
+			r.nbRead++
+			if r.mode == Strict && r.nbRead > r.nbClauses {
+				return nil, r.errorf(t.line, t.col, "more clauses than the %d declared", r.nbClauses)
+			}
+			if clause == nil {
+				clause = []int{}
+			}
+			return clause, nil
+		}
+		v := lit
+		if v < 0 {
+			v = -v
+		}
+		if v > math.MaxInt32 {
+			return nil, r.errorf(t.line, t.col, "literal %d out of range", lit)
+		}
+		if v > r.nbVars {
+			if r.mode == Strict {
+				return nil, r.errorf(t.line, t.col, "literal %d exceeds the %d declared variables", lit, r.nbVars)
+			}
+			r.nbVars = v
+		}
+		clause = append(clause, lit)
+	}
+}
+
+// end is called when the end of the problem was reached.
+// clause holds the literals of the last clause, if it was not ended by a 0.
+func (r *Reader) end(clause []int) ([]int, error) {
+	r.done = true
+	if len(clause) != 0 {
+		if r.mode == Strict {
+			return nil, r.errorf(r.line, r.col, "missing final 0 in last clause")
+		}
+		r.nbRead++
+		return clause, nil
+	}
+	if r.mode == Strict && r.nbRead != r.nbClauses {
+		return nil, r.errorf(r.line, r.col, "expected %d clauses, found %d", r.nbClauses, r.nbRead)
+	}
+	return nil, io.EOF
+}
+
+// next returns the next token that is not part of a comment.
+func (r *Reader) next() (token, error) {
+	for {
+		t, err := r.scan()
+		if err != nil {
+			return t, err
+		}
+		if !t.first || !strings.HasPrefix(t.text, "c") {
+			return t, nil
+		}
+		if err := r.skipLine(); err != nil {
+			return t, err
+		}
+	}
+}
+
+// scan returns the next token, or the pending one if there is one.
+func (r *Reader) scan() (token, error) {
+	if r.pending != nil {
+		t := *r.pending
+		r.pending = nil
+		return t, nil
+	}
+	b, err := r.readByte()
+	for err == nil && isSpace(b) {
+		b, err = r.readByte()
+	}
+	if err != nil {
+		return token{}, err
+	}
+	t := token{line: r.line, col: r.col - 1, first: r.line != r.tokLine}
+	r.tokLine = r.line
+	var text []byte
+	for err == nil && !isSpace(b) {
+		text = append(text, b)
+		b, err = r.readByte()
+	}
+	if err != nil && err != io.EOF {
+		return token{}, err
+	}
+	r.eol = err == nil && b == '\n'
+	t.text = string(text)
+	return t, nil
+}
+
+// skipLine skips the rest of the line of the last token.
+func (r *Reader) skipLine() error {
+	if r.eol {
+		return nil
+	}
+	for {
+		b, err := r.readByte()
+		if err == io.EOF || (err == nil && b == '\n') {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// readByte reads the next byte and updates the current position.
+func (r *Reader) readByte() (byte, error) {
+	b, err := r.br.ReadByte()
+	if err == io.EOF {
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("could not read problem: %v", err)
+	}
+	if b == '\n' {
+		r.line++
+		r.col = 1
+	} else {
+		r.col++
+	}
+	return b, nil
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+func (r *Reader) errorf(line, col int, format string, args ...interface{}) error {
+	return &Error{Line: line, Column: col, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Read reads a problem from r, possibly compressed, and calls fn on each of its clauses.
+// It returns the number of variables of the problem.
+// If fn returns an error, reading stops and that error is returned.
+func Read(r io.Reader, mode Mode, fn func(clause []int) error) (nbVars int, err error) {
+	if r, err = input.Decompress(r); err != nil {
+		return 0, err
+	}
+	rd := NewReader(r, mode)
+	for {
+		clause, err := rd.Next()
+		if err == io.EOF {
+			return rd.NbVars(), nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if err := fn(clause); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// A Problem is a CNF problem, as it was written.
+type Problem struct {
+	NbVars  int     // Number of variables
+	Clauses [][]int // Clauses, in their original order, without their final 0
+}
+
+// Parse reads a whole problem from r, possibly compressed.
+// The problem is kept exactly as written: it is neither simplified nor normalized.
+func Parse(r io.Reader, mode Mode) (*Problem, error) {
+	var pb Problem
+	nbVars, err := Read(r, mode, func(clause []int) error {
+		pb.Clauses = append(pb.Clauses, clause)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	pb.NbVars = nbVars
+	return &pb, nil
+}
@@ -0,0 +1,105 @@
+package dimacs
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		content  string
+		mode     Mode
+		expected Problem
+	}{
+		{"p cnf 0 0\n", Strict, Problem{}},
+		{"c comment\np cnf 3 4\n1 -2 0 2\n3 0\nc another comment\n1 1 0 -1 1 0\n", Strict,
+			Problem{NbVars: 3, Clauses: [][]int{{1, -2}, {2, 3}, {1, 1}, {-1, 1}}}},
+		{"p cnf 5 2\r\n\t-5 0\r\n0\r\n", Strict, Problem{NbVars: 5, Clauses: [][]int{{-5}, {}}}},
+		{"c no header\n1 2 0\n-3 0\n", Lenient, Problem{NbVars: 3, Clauses: [][]int{{1, 2}, {-3}}}},
+		{"p cnf 2 5\n1 2 0\n-4 0\n", Lenient, Problem{NbVars: 4, Clauses: [][]int{{1, 2}, {-4}}}},
+		{"p cnf 2 1\n1 2 0\n2\n", Lenient, Problem{NbVars: 2, Clauses: [][]int{{1, 2}, {2}}}},
+		{"p cnf 2 1\n1 2 0\n%\n0\n", Lenient, Problem{NbVars: 2, Clauses: [][]int{{1, 2}}}},
+		{"", Lenient, Problem{}},
+	}
+	for _, test := range tests {
+		pb, err := Parse(strings.NewReader(test.content), test.mode)
+		if err != nil {
+			t.Errorf("could not parse %q in %v mode: %v", test.content, test.mode, err)
+		} else if !reflect.DeepEqual(*pb, test.expected) {
+			t.Errorf("for %q in %v mode, expected %v, got %v", test.content, test.mode, test.expected, *pb)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		mode    Mode
+		err     string
+	}{
+		{"", Strict, "line 1, column 1: missing header"},
+		{"c comment\n1 2 0\n", Strict, `line 2, column 1: expected header, found "1"`},
+		{"p dnf 2 1\n", Strict, `line 1, column 1: expected "p cnf nbVars nbClauses" header`},
+		{"p cnf 2\n", Lenient, "line 1, column 1: missing number of clauses in header"},
+		{"p cnf 2 1 3\n", Lenient, `line 1, column 11: expected end of header, found "3"`},
+		{"p cnf -2 1\n", Lenient, `line 1, column 7: invalid number of variables "-2"`},
+		{"p cnf 2 x\n", Lenient, `line 1, column 9: invalid number of clauses "x"`},
+		{"p cnf 2 2\n1 2 0\n  -3 0\n", Strict, "line 3, column 3: literal -3 exceeds the 2 declared variables"},
+		{"p cnf 2 1\n1 2 0\n1 0\n", Strict, "line 3, column 3: more clauses than the 1 declared"},
+		{"p cnf 2 2\n1 2 0\n", Strict, "line 3, column 1: expected 2 clauses, found 1"},
+		{"p cnf 2 1\n1 2", Strict, "line 2, column 4: missing final 0 in last clause"},
+		{"p cnf 2 1\n1 x2 0\n", Lenient, `line 2, column 3: expected literal, found "x2"`},
+		{"p cnf 2 1\n1 2 0\np cnf 2 1\n", Lenient, "line 3, column 1: duplicate header"},
+		{"1 2 0\np cnf 2 1\n", Lenient, "line 2, column 1: header after clauses"},
+		{"p cnf 2 1\n1 2 0\n%\n", Strict, `line 3, column 1: expected literal, found "%"`},
+		{"p cnf 2 1\n1 99999999999 0\n", Lenient, "line 2, column 3: literal 99999999999 out of range"},
+	}
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.content), test.mode)
+		if err == nil {
+			t.Errorf("%q should not have been parsed in %v mode", test.content, test.mode)
+		} else if err.Error() != test.err {
+			t.Errorf("for %q in %v mode, expected error %q, got %q", test.content, test.mode, test.err, err)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	r := NewReader(strings.NewReader("c comment\np cnf 3 2\n1 2 0\n-3 0\n"), Strict)
+	if nbVars, nbClauses, err := r.ReadHeader(); err != nil || nbVars != 3 || nbClauses != 2 {
+		t.Fatalf("expected header 3 2, got %d %d (err: %v)", nbVars, nbClauses, err)
+	}
+	var clauses [][]int
+	for {
+		clause, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("could not read clause: %v", err)
+		}
+		clauses = append(clauses, clause)
+	}
+	if expected := [][]int{{1, 2}, {-3}}; !reflect.DeepEqual(clauses, expected) {
+		t.Errorf("expected clauses %v, got %v", expected, clauses)
+	}
+	if _, err := r.Next(); err != io.EOF {
+		t.Errorf("expected EOF after last clause, got %v", err)
+	}
+}
+
+func ExampleRead() {
+	content := "p cnf 3 2\n1 -2 0\n2 3 0\n"
+	nbVars, err := Read(strings.NewReader(content), Strict, func(clause []int) error {
+		fmt.Println(clause)
+		return nil
+	})
+	fmt.Println(nbVars, err)
+	// Output:
+	// [1 -2]
+	// [2 3]
+	// 3 <nil>
+}
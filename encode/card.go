@@ -0,0 +1,126 @@
+package encode
+
+// This file contains encodings of at-most-k constraints.
+// All of them suppose 0 < k < len(lits).
+
+// pairwise forbids all subsets of k+1 lits.
+func (b *builder) pairwise(lits []int, k int) {
+	subset := make([]int, 0, k+1)
+	var rec func(from int)
+	rec = func(from int) {
+		if len(subset) == k+1 {
+			b.add(subset...)
+			return
+		}
+		for i := from; i <= len(lits)-(k+1-len(subset)); i++ {
+			subset = append(subset, -lits[i])
+			rec(i + 1)
+			subset = subset[:len(subset)-1]
+		}
+	}
+	rec(0)
+}
+
+// seqCounter encodes the constraint with a sequential counter:
+// s[i][j] is true if at least j+1 lits among the i+1 first ones are true.
+func (b *builder) seqCounter(lits []int, k int) {
+	n := len(lits)
+	s := make([][]int, n-1)
+	for i := range s {
+		s[i] = make([]int, k)
+		for j := range s[i] {
+			s[i][j] = b.newVar()
+		}
+	}
+	b.add(-lits[0], s[0][0])
+	for j := 1; j < k; j++ {
+		b.add(-s[0][j])
+	}
+	for i := 1; i < n-1; i++ {
+		b.add(-lits[i], s[i][0])
+		b.add(-s[i-1][0], s[i][0])
+		for j := 1; j < k; j++ {
+			b.add(-lits[i], -s[i-1][j-1], s[i][j])
+			b.add(-s[i-1][j], s[i][j])
+		}
+		b.add(-lits[i], -s[i-1][k-1])
+	}
+	b.add(-lits[n-1], -s[n-2][k-1])
+}
+
+// totalizer encodes the constraint with a totalizer whose outputs are limited to k+1,
+// and states the (k+1)th output is false.
+func (b *builder) totalizer(lits []int, k int) {
+	outputs := b.totalizerNode(lits, k+1)
+	b.add(-outputs[k])
+}
+
+// totalizerNode returns the outputs of the totalizer for the given lits:
+// outputs[i] is true if at least i+1 lits are true. At most max outputs are created.
+// Only the implication from the lits to the outputs is encoded.
+func (b *builder) totalizerNode(lits []int, max int) []int {
+	if len(lits) == 1 {
+		return []int{lits[0]}
+	}
+	mid := len(lits) / 2
+	left := b.totalizerNode(lits[:mid], max)
+	right := b.totalizerNode(lits[mid:], max)
+	n := len(lits)
+	if n > max {
+		n = max
+	}
+	outputs := make([]int, n)
+	for i := range outputs {
+		outputs[i] = b.newVar()
+	}
+	// If i lits are true on the left and j on the right, at least i+j lits are true.
+	for i := 0; i <= len(left); i++ {
+		for j := 0; j <= len(right) && i+j <= n; j++ {
+			if i+j == 0 {
+				continue
+			}
+			clause := make([]int, 0, 3)
+			if i > 0 {
+				clause = append(clause, -left[i-1])
+			}
+			if j > 0 {
+				clause = append(clause, -right[j-1])
+			}
+			b.add(append(clause, outputs[i+j-1])...)
+		}
+	}
+	return outputs
+}
+
+// cardNetwork sorts the lits with a sorting network, so that the ith output is true if at least i+1 lits are true,
+// and states the (k+1)th output is false.
+// The network is built with Batcher's merge-exchange algorithm (Knuth, TAOCP vol. 3, algorithm 5.2.2M).
+// Each comparator only encodes the implication from its inputs to its outputs.
+func (b *builder) cardNetwork(lits []int, k int) {
+	n := len(lits)
+	wires := make([]int, n)
+	copy(wires, lits)
+	compare := func(i, j int) {
+		max, min := b.newVar(), b.newVar()
+		b.add(-wires[i], max)
+		b.add(-wires[j], max)
+		b.add(-wires[i], -wires[j], min)
+		wires[i], wires[j] = max, min
+	}
+	t := 0
+	for 1<<uint(t) < n {
+		t++
+	}
+	for p := 1 << uint(t-1); p > 0; p >>= 1 {
+		q, r, d := 1<<uint(t-1), 0, p
+		for d > 0 {
+			for i := 0; i < n-d; i++ {
+				if i&p == r {
+					compare(i, i+d)
+				}
+			}
+			d, q, r = q-p, q>>1, p
+		}
+	}
+	b.add(-wires[k])
+}
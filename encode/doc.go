@@ -0,0 +1,28 @@
+// Package encode translates cardinality and pseudo-boolean constraints to CNF.
+//
+// The solver package deals natively with cardinality and pseudo-boolean (PB) constraints, but other tools,
+// or other parts of a program, sometimes need a pure CNF. This package provides several classical encodings,
+// each with its own trade-off between the number of generated clauses, the number of auxiliary variables
+// and how well unit propagation behaves on the result:
+//
+// - Pairwise (also known as binomial): no auxiliary variable, but a number of clauses that grows exponentially with k,
+// - SeqCounter, the sequential counter: O(n.k) clauses and variables,
+// - Totalizer: O(n.k) variables and O(n.k²) clauses, with good propagation,
+// - CardNetwork, a sorting network: O(n.log²(n)) clauses and variables,
+// - Adder, a network of binary adders: O(n.log(W)) clauses and variables, where W is the biggest weight,
+// - BDD, a binary decision diagram: pseudo-polynomial size, with good propagation,
+// - GTE, the generalized totalizer: pseudo-polynomial size, usually compact when there are few distinct weights.
+//
+// The first four ones can only encode cardinality constraints, the three others can also encode
+// weighted PB constraints.
+//
+// Literals are represented as in the DIMACS format: variables are numbered from 1, and a negative integer
+// is the negation of the corresponding variable. An Encoder keeps track of the number of variables that are
+// already in use, so that auxiliary variables never conflict with the user's ones:
+//
+//	enc := encode.New(4)
+//	cnf, err := enc.AtMost([]int{1, 2, 3, 4}, 2, encode.Totalizer)
+//
+// cnf.Clauses can then be appended to the problem's clauses, and cnf.Aux lists the auxiliary variables
+// that were created, so that they can be ignored in models.
+package encode
@@ -0,0 +1,284 @@
+package encode
+
+import (
+	"fmt"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// An Encoding is a way to translate a cardinality or PB constraint to CNF.
+type Encoding int
+
+const (
+	// Auto lets the encoder choose an encoding depending on the constraint.
+	Auto Encoding = iota
+	// Pairwise forbids every subset of k+1 true literals for an at-most-k constraint.
+	// It does not create any variable, but should only be used when k is very small, typically 1.
+	Pairwise
+	// SeqCounter is the sequential counter from Sinz (2005): a register of k bits counts,
+	// for each prefix of the literals, how many of them are true.
+	SeqCounter
+	// Totalizer is the totalizer from Bailleux and Boufkhad (2003): a binary tree where each node counts,
+	// in unary, how many of the literals below it are true. Counts are only kept up to k+1.
+	Totalizer
+	// CardNetwork is a sorting network of the literals, built with Batcher's merge-exchange algorithm.
+	// The constraint is then stated on the (k+1)th output of the network.
+	CardNetwork
+	// Adder sums the weights of the true literals with a network of binary adders, and compares
+	// the result with the bound (Eén and Sörensson, 2006).
+	Adder
+	// BDD is a binary decision diagram deciding, literal after literal, whether the bound can still be respected.
+	BDD
+	// GTE is the generalized totalizer from Joshi et al. (2015): each node of the tree has a variable
+	// for each possible sum of the weights of the true literals below it.
+	GTE
+)
+
+// String returns the name of the encoding.
+func (enc Encoding) String() string {
+	switch enc {
+	case Auto:
+		return "auto"
+	case Pairwise:
+		return "pairwise"
+	case SeqCounter:
+		return "seqcounter"
+	case Totalizer:
+		return "totalizer"
+	case CardNetwork:
+		return "cardnetwork"
+	case Adder:
+		return "adder"
+	case BDD:
+		return "bdd"
+	case GTE:
+		return "gte"
+	default:
+		return fmt.Sprintf("Encoding(%d)", int(enc))
+	}
+}
+
+// ParseEncoding returns the encoding whose name is given, as returned by Encoding.String.
+func ParseEncoding(name string) (Encoding, error) {
+	for _, enc := range []Encoding{Auto, Pairwise, SeqCounter, Totalizer, CardNetwork, Adder, BDD, GTE} {
+		if enc.String() == name {
+			return enc, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown encoding %q", name)
+}
+
+// weighted returns true iff the encoding can deal with weighted PB constraints.
+func (enc Encoding) weighted() bool {
+	return enc == Auto || enc == Adder || enc == BDD || enc == GTE
+}
+
+// A CNF is the result of the encoding of a constraint.
+type CNF struct {
+	Clauses [][]int // Clauses, in the DIMACS format. An empty clause means the constraint cannot be satisfied.
+	Aux     []int   // Auxiliary variables that were created by the encoding, by increasing order
+}
+
+// An Encoder translates constraints to CNF.
+// Auxiliary variables are numbered after all variables that were already used.
+type Encoder struct {
+	NbVars int // Number of variables in use: the next auxiliary variable will be NbVars+1
+}
+
+// New returns an encoder for a problem that already uses nbVars variables.
+func New(nbVars int) *Encoder {
+	return &Encoder{NbVars: nbVars}
+}
+
+// A builder generates the clauses encoding a single constraint.
+type builder struct {
+	enc *Encoder
+	cnf CNF
+}
+
+// newBuilder returns a builder for a constraint on the given lits.
+// If some lits are bigger than e.NbVars, it is updated accordingly.
+func (e *Encoder) newBuilder(lits []int) *builder {
+	for _, lit := range lits {
+		if lit > e.NbVars {
+			e.NbVars = lit
+		} else if -lit > e.NbVars {
+			e.NbVars = -lit
+		}
+	}
+	return &builder{enc: e}
+}
+
+// newVar returns a new auxiliary variable.
+func (b *builder) newVar() int {
+	b.enc.NbVars++
+	b.cnf.Aux = append(b.cnf.Aux, b.enc.NbVars)
+	return b.enc.NbVars
+}
+
+// add adds a clause made of the given lits.
+func (b *builder) add(lits ...int) {
+	clause := make([]int, len(lits))
+	copy(clause, lits)
+	b.cnf.Clauses = append(b.cnf.Clauses, clause)
+}
+
+// negated returns the negation of all given lits.
+func negated(lits []int) []int {
+	res := make([]int, len(lits))
+	for i, lit := range lits {
+		res[i] = -lit
+	}
+	return res
+}
+
+// AtMost returns the encoding of the constraint stating that at most k of the given lits are true.
+func (e *Encoder) AtMost(lits []int, k int, enc Encoding) (CNF, error) {
+	b := e.newBuilder(lits)
+	if err := b.atMost(lits, k, enc); err != nil {
+		return CNF{}, err
+	}
+	return b.cnf, nil
+}
+
+// AtLeast returns the encoding of the constraint stating that at least k of the given lits are true.
+func (e *Encoder) AtLeast(lits []int, k int, enc Encoding) (CNF, error) {
+	b := e.newBuilder(lits)
+	if err := b.atMost(negated(lits), len(lits)-k, enc); err != nil {
+		return CNF{}, err
+	}
+	return b.cnf, nil
+}
+
+// Exactly returns the encoding of the constraint stating that exactly k of the given lits are true.
+func (e *Encoder) Exactly(lits []int, k int, enc Encoding) (CNF, error) {
+	b := e.newBuilder(lits)
+	if err := b.atMost(lits, k, enc); err != nil {
+		return CNF{}, err
+	}
+	if err := b.atMost(negated(lits), len(lits)-k, enc); err != nil {
+		return CNF{}, err
+	}
+	return b.cnf, nil
+}
+
+// Card returns the encoding of the given cardinality constraint.
+func (e *Encoder) Card(c solver.CardConstr, enc Encoding) (CNF, error) {
+	return e.AtLeast(c.Lits, c.AtLeast, enc)
+}
+
+// PB returns the encoding of the given PB constraint.
+// Only Auto, Adder, BDD and GTE can encode constraints whose weights are not all equal to 1.
+func (e *Encoder) PB(c solver.PBConstr, enc Encoding) (CNF, error) {
+	b := e.newBuilder(c.Lits)
+	weights := c.Weights
+	if weights == nil {
+		weights = make([]int, len(c.Lits))
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	// sum(w.l) >= k iff sum(w.not(l)) <= sum(w) - k
+	if err := b.pbAtMost(negated(c.Lits), weights, c.WeightSum()-c.AtLeast, enc); err != nil {
+		return CNF{}, err
+	}
+	return b.cnf, nil
+}
+
+// atMost encodes the constraint stating at most k lits are true.
+func (b *builder) atMost(lits []int, k int, enc Encoding) error {
+	switch {
+	case k < 0:
+		b.add()
+		return nil
+	case k >= len(lits):
+		return nil
+	case k == 0:
+		for _, lit := range lits {
+			b.add(-lit)
+		}
+		return nil
+	}
+	switch enc {
+	case Auto:
+		if k == 1 && len(lits) <= 6 {
+			b.pairwise(lits, k)
+		} else {
+			b.totalizer(lits, k)
+		}
+	case Pairwise:
+		b.pairwise(lits, k)
+	case SeqCounter:
+		b.seqCounter(lits, k)
+	case Totalizer:
+		b.totalizer(lits, k)
+	case CardNetwork:
+		b.cardNetwork(lits, k)
+	case Adder, BDD, GTE:
+		weights := make([]int, len(lits))
+		for i := range weights {
+			weights[i] = 1
+		}
+		return b.pbAtMost(lits, weights, k, enc)
+	default:
+		return fmt.Errorf("unknown encoding %v", enc)
+	}
+	return nil
+}
+
+// pbAtMost encodes the constraint stating the sum of the weights of the true lits is at most k.
+func (b *builder) pbAtMost(lits, weights []int, k int, enc Encoding) error {
+	if len(lits) != len(weights) {
+		return fmt.Errorf("not as many lits as weights")
+	}
+	// Only keep positive weights that are not bigger than k
+	var lits2, weights2 []int
+	for i, lit := range lits {
+		w := weights[i]
+		if w < 0 { // w.l = w + (-w).not(l)
+			lit, w = -lit, -w
+			k += w
+		}
+		if w != 0 {
+			lits2 = append(lits2, lit)
+			weights2 = append(weights2, w)
+		}
+	}
+	if k < 0 {
+		b.add()
+		return nil
+	}
+	lits, weights = lits2[:0], weights2[:0]
+	card := true
+	for i, lit := range lits2 {
+		if w := weights2[i]; w > k {
+			b.add(-lit)
+		} else {
+			lits = append(lits, lit)
+			weights = append(weights, w)
+			card = card && w == 1
+		}
+	}
+	if card && (enc == Auto || !enc.weighted()) {
+		return b.atMost(lits, k, enc)
+	}
+	if !enc.weighted() {
+		return fmt.Errorf("encoding %v cannot be used on weighted constraints", enc)
+	}
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	if sum <= k {
+		return nil
+	}
+	switch enc {
+	case Adder:
+		b.adder(lits, weights, k)
+	case GTE:
+		b.gte(lits, weights, k)
+	default:
+		b.bdd(lits, weights, k)
+	}
+	return nil
+}
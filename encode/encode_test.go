@@ -0,0 +1,161 @@
+package encode
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+var allEncodings = []Encoding{Auto, Pairwise, SeqCounter, Totalizer, CardNetwork, Adder, BDD, GTE}
+
+// randomLits returns n lits on vars 1..n, in random order and with random signs.
+func randomLits(r *rand.Rand, n int) []int {
+	lits := make([]int, n)
+	for i, v := range r.Perm(n) {
+		lits[i] = v + 1
+		if r.Intn(2) == 0 {
+			lits[i] = -lits[i]
+		}
+	}
+	return lits
+}
+
+// checkEncoding checks that, for each assignment of the n first vars, cnf can be satisfied iff valid returns true.
+func checkEncoding(t *testing.T, n int, cnf CNF, nbVars int, valid func(model []bool) bool, desc string) {
+	for _, v := range cnf.Aux {
+		if v <= n || v > nbVars {
+			t.Fatalf("%s: invalid aux var %d", desc, v)
+		}
+	}
+	for m := 0; m < 1<<uint(n); m++ {
+		model := make([]bool, n)
+		clauses := append([][]int{}, cnf.Clauses...)
+		for v := range model {
+			model[v] = m&(1<<uint(v)) != 0
+			if model[v] {
+				clauses = append(clauses, []int{v + 1})
+			} else {
+				clauses = append(clauses, []int{-(v + 1)})
+			}
+		}
+		status := solver.New(solver.ParseSliceNb(clauses, nbVars)).Solve()
+		if expected := valid(model); expected != (status == solver.Sat) {
+			t.Fatalf("%s: model %v, expected %t, got %v", desc, model, expected, status)
+		}
+	}
+}
+
+// nbTrue returns the sum of the weights of the lits that are true in model.
+// If weights is nil, all weights are 1.
+func nbTrue(lits, weights []int, model []bool) int {
+	res := 0
+	for i, lit := range lits {
+		if (lit > 0) == model[abs(lit)-1] {
+			if weights == nil {
+				res++
+			} else {
+				res += weights[i]
+			}
+		}
+	}
+	return res
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func TestCard(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 100; iter++ {
+		n := 1 + r.Intn(6)
+		lits := randomLits(r, n)
+		k := r.Intn(n+3) - 1
+		for _, enc := range allEncodings {
+			e := New(n)
+			cnf, err := e.AtMost(lits, k, enc)
+			if err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			checkEncoding(t, n, cnf, e.NbVars, func(model []bool) bool { return nbTrue(lits, nil, model) <= k }, fmt.Sprintf("%v: at most %d of %v", enc, k, lits))
+			e = New(n)
+			cnf, err = e.AtLeast(lits, k, enc)
+			if err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			checkEncoding(t, n, cnf, e.NbVars, func(model []bool) bool { return nbTrue(lits, nil, model) >= k }, fmt.Sprintf("%v: at least %d of %v", enc, k, lits))
+			e = New(n)
+			cnf, err = e.Exactly(lits, k, enc)
+			if err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			checkEncoding(t, n, cnf, e.NbVars, func(model []bool) bool { return nbTrue(lits, nil, model) == k }, fmt.Sprintf("%v: exactly %d of %v", enc, k, lits))
+		}
+	}
+}
+
+func TestPB(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		n := 1 + r.Intn(6)
+		lits := randomLits(r, n)
+		weights := make([]int, n)
+		max := 0 // Biggest absolute value of the sum of the weights
+		for i := range weights {
+			weights[i] = r.Intn(12) - 3
+			max += abs(weights[i])
+		}
+		k := r.Intn(2*max+10) - max - 5
+		c := solver.PBConstr{Lits: lits, Weights: weights, AtLeast: k}
+		for _, enc := range []Encoding{Auto, Adder, BDD, GTE} {
+			e := New(n)
+			cnf, err := e.PB(c, enc)
+			if err != nil {
+				t.Fatalf("could not encode: %v", err)
+			}
+			checkEncoding(t, n, cnf, e.NbVars, func(model []bool) bool { return nbTrue(lits, weights, model) >= k }, fmt.Sprintf("%v: %v", enc, c))
+		}
+	}
+}
+
+func TestPBWeightedCardEncoding(t *testing.T) {
+	c := solver.PBConstr{Lits: []int{1, 2, 3}, Weights: []int{1, 2, 3}, AtLeast: 3}
+	for _, enc := range []Encoding{Pairwise, SeqCounter, Totalizer, CardNetwork} {
+		if _, err := New(3).PB(c, enc); err == nil {
+			t.Errorf("%v: expected an error when encoding a weighted constraint", enc)
+		}
+	}
+	c.Weights = []int{1, 1, 1}
+	for _, enc := range allEncodings {
+		if _, err := New(3).PB(c, enc); err != nil {
+			t.Errorf("%v: could not encode cardinality constraint: %v", enc, err)
+		}
+	}
+}
+
+func TestParseEncoding(t *testing.T) {
+	for _, enc := range allEncodings {
+		if enc2, err := ParseEncoding(enc.String()); err != nil || enc2 != enc {
+			t.Errorf("could not parse encoding %v: got %v, %v", enc, enc2, err)
+		}
+	}
+	if _, err := ParseEncoding("foo"); err == nil {
+		t.Errorf("expected an error when parsing unknown encoding")
+	}
+}
+
+func ExampleEncoder_AtMost() {
+	enc := New(3)
+	cnf, _ := enc.AtMost([]int{1, 2, 3}, 1, Pairwise)
+	fmt.Println(cnf.Clauses)
+	cnf, _ = enc.AtMost([]int{1, 2, 3}, 1, SeqCounter)
+	fmt.Println(cnf.Aux)
+	// Output:
+	// [[-1 -2] [-1 -3] [-2 -3]]
+	// [4 5]
+}
@@ -0,0 +1,181 @@
+package encode
+
+import "sort"
+
+// This file contains encodings of PB constraints stating the sum of the weights of the true lits is at most k.
+// All of them suppose weights are strictly positive and not bigger than k, and that their sum is bigger than k.
+
+// adder sums the weights of the true lits with binary adders, and compares the result with k.
+// Only the implication from the inputs to the outputs of the adders is encoded, so the sum
+// they represent is at least the actual sum.
+func (b *builder) adder(lits, weights []int, k int) {
+	var buckets [][]int // buckets[i] contains the lits whose value is 2^i
+	for i, lit := range lits {
+		for bit, w := 0, weights[i]; w != 0; bit, w = bit+1, w>>1 {
+			for len(buckets) <= bit {
+				buckets = append(buckets, nil)
+			}
+			if w&1 != 0 {
+				buckets[bit] = append(buckets[bit], lit)
+			}
+		}
+	}
+	for bit := 0; bit < len(buckets); bit++ {
+		for len(buckets[bit]) >= 2 {
+			bucket := buckets[bit]
+			var sum, carry int
+			if len(bucket) >= 3 {
+				x, y, z := bucket[0], bucket[1], bucket[2]
+				buckets[bit] = bucket[3:]
+				sum, carry = b.newVar(), b.newVar()
+				b.add(-x, -y, carry)
+				b.add(-x, -z, carry)
+				b.add(-y, -z, carry)
+				b.add(-x, y, z, sum)
+				b.add(x, -y, z, sum)
+				b.add(x, y, -z, sum)
+				b.add(-x, -y, -z, sum)
+			} else {
+				x, y := bucket[0], bucket[1]
+				buckets[bit] = bucket[2:]
+				sum, carry = b.newVar(), b.newVar()
+				b.add(-x, -y, carry)
+				b.add(-x, y, sum)
+				b.add(x, -y, sum)
+			}
+			buckets[bit] = append(buckets[bit], sum)
+			if bit+1 == len(buckets) {
+				buckets = append(buckets, nil)
+			}
+			buckets[bit+1] = append(buckets[bit+1], carry)
+		}
+	}
+	// If the sum has a 1 where k has a 0, one of the more significant bits where k has a 1 must be 0.
+	for bit, bucket := range buckets {
+		if len(bucket) == 0 || k>>uint(bit)&1 != 0 {
+			continue
+		}
+		clause := []int{-bucket[0]}
+		satisfied := false
+		for bit2 := bit + 1; bit2 < 64 && k>>uint(bit2) != 0; bit2++ {
+			if k>>uint(bit2)&1 == 0 {
+				continue
+			}
+			if bit2 >= len(buckets) || len(buckets[bit2]) == 0 { // Bit is always 0
+				satisfied = true
+				break
+			}
+			clause = append(clause, -buckets[bit2][0])
+		}
+		if !satisfied {
+			b.add(clause...)
+		}
+	}
+}
+
+// bdd encodes the constraint as a binary decision diagram. Lits are considered by decreasing weight,
+// and each node means the sum of the weights of the remaining true lits is at most a given bound.
+func (b *builder) bdd(lits, weights []int, k int) {
+	lits, weights = sortedByWeight(lits, weights)
+	rest := make([]int, len(lits)+1) // rest[i] is the sum of the weights from i
+	for i := len(lits) - 1; i >= 0; i-- {
+		rest[i] = rest[i+1] + weights[i]
+	}
+	type key struct{ idx, bound int }
+	nodes := make(map[key]int)
+	// node returns the lit that is true if the weights of the true lits from idx are at most bound,
+	// or 0 if this is always the case.
+	var node func(idx, bound int) int
+	node = func(idx, bound int) int {
+		if rest[idx] <= bound {
+			return 0
+		}
+		if v, ok := nodes[key{idx, bound}]; ok {
+			return v
+		}
+		v := b.newVar()
+		nodes[key{idx, bound}] = v
+		lit, w := lits[idx], weights[idx]
+		if w > bound { // lit must be false
+			b.add(-v, -lit)
+		} else if hi := node(idx+1, bound-w); hi != 0 {
+			b.add(-v, -lit, hi)
+		}
+		if lo := node(idx+1, bound); lo != 0 {
+			b.add(-v, lo)
+		}
+		return v
+	}
+	b.add(node(0, k))
+}
+
+// gte encodes the constraint with a generalized totalizer, and states the sum cannot be bigger than k.
+func (b *builder) gte(lits, weights []int, k int) {
+	root := b.gteNode(lits, weights, k+1)
+	if last := root[len(root)-1]; last.sum > k {
+		b.add(-last.lit)
+	}
+}
+
+// A gteOutput is an output of a node of a generalized totalizer:
+// lit is true if the sum of the weights of the true lits below the node is at least sum.
+type gteOutput struct {
+	sum int
+	lit int
+}
+
+// gteNode returns the outputs of the generalized totalizer for the given lits, by increasing sum.
+// Sums bigger than max are considered equal to max.
+// Only the implication from the lits to the outputs is encoded.
+func (b *builder) gteNode(lits, weights []int, max int) []gteOutput {
+	if len(lits) == 1 {
+		return []gteOutput{{sum: weights[0], lit: lits[0]}}
+	}
+	mid := len(lits) / 2
+	left := b.gteNode(lits[:mid], weights[:mid], max)
+	right := b.gteNode(lits[mid:], weights[mid:], max)
+	outputs := make(map[int]int)
+	var sums []int
+	output := func(sum int) int {
+		if sum > max {
+			sum = max
+		}
+		if _, ok := outputs[sum]; !ok {
+			outputs[sum] = b.newVar()
+			sums = append(sums, sum)
+		}
+		return outputs[sum]
+	}
+	for _, l := range left {
+		b.add(-l.lit, output(l.sum))
+	}
+	for _, r := range right {
+		b.add(-r.lit, output(r.sum))
+	}
+	for _, l := range left {
+		for _, r := range right {
+			b.add(-l.lit, -r.lit, output(l.sum+r.sum))
+		}
+	}
+	sort.Ints(sums)
+	res := make([]gteOutput, len(sums))
+	for i, sum := range sums {
+		res[i] = gteOutput{sum: sum, lit: outputs[sum]}
+	}
+	return res
+}
+
+// sortedByWeight returns copies of lits and weights, sorted by decreasing weight.
+func sortedByWeight(lits, weights []int) ([]int, []int) {
+	idx := make([]int, len(lits))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool { return weights[idx[i]] > weights[idx[j]] })
+	lits2 := make([]int, len(lits))
+	weights2 := make([]int, len(lits))
+	for i, j := range idx {
+		lits2[i], weights2[i] = lits[j], weights[j]
+	}
+	return lits2, weights2
+}
@@ -225,6 +225,50 @@ func TestMUSDeletion(t *testing.T) {
 	}
 }
 
+func TestMUSIncremental(t *testing.T) {
+	cnf, err := os.Open("testcnf/50.cnf")
+	if err != nil {
+		t.Errorf("could not read CNF file: %v", err)
+		return
+	}
+	defer cnf.Close()
+	pb, err := ParseCNF(cnf)
+	if err != nil {
+		t.Fatalf("could not parse cnf: %v", err)
+	}
+	mus, err := pb.MUSIncremental()
+	if err != nil {
+		t.Fatalf("could not extract subset: %v", err)
+	}
+	s := solver.New(solver.ParseSlice(mus.Clauses))
+	if s.Solve() != solver.Unsat {
+		t.Errorf("mus was satisfiable")
+	}
+	for i := range mus.Clauses {
+		subset := make([][]int, 0, len(mus.Clauses)-1)
+		subset = append(subset, mus.Clauses[:i]...)
+		subset = append(subset, mus.Clauses[i+1:]...)
+		s := solver.New(solver.ParseSliceNb(subset, mus.NbVars))
+		if s.Solve() != solver.Sat {
+			t.Errorf("mus was not minimal: subset without clause #%d was unsatisfiable", i+1)
+		}
+	}
+}
+
+func TestMUSIncrementalSat(t *testing.T) {
+	const cnf = `p cnf 3 3
+	1 2 -3 0
+	-1 -2 3 0
+	2 0`
+	pb, err := ParseCNF(strings.NewReader(cnf))
+	if err != nil {
+		t.Fatalf("could not parse cnf: %v", err)
+	}
+	if _, err := pb.MUSIncremental(); err == nil {
+		t.Errorf("could extract MUS from satisfiable problem")
+	}
+}
+
 func ExampleProblem_CNF() {
 	const cnf = `p cnf 3 3
 	c This is a simple problem
@@ -330,3 +374,19 @@ func BenchmarkMUSDeletion(b *testing.B) {
 		pb.MUSDeletion()
 	}
 }
+
+func BenchmarkMUSIncremental(b *testing.B) {
+	content, err := ioutil.ReadFile("testcnf/50.cnf")
+	if err != nil {
+		b.Errorf("could not read CNF file: %v", err)
+		return
+	}
+	for i := 0; i < b.N; i++ {
+		cnf := strings.NewReader(string(content))
+		pb, err := ParseCNF(cnf)
+		if err != nil {
+			b.Fatalf("could not parse cnf: %v", err)
+		}
+		pb.MUSIncremental()
+	}
+}
@@ -0,0 +1,227 @@
+package explain
+
+import (
+	"fmt"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// Status of a clause during incremental MUS extraction.
+const (
+	musRemoved   = iota // Clause is known not to be part of the MUS
+	musCandidate        // Clause might be part of the MUS
+	musNecessary        // Clause is known to be part of the MUS
+)
+
+// musExtractor holds the state of an incremental MUS extraction.
+type musExtractor struct {
+	s       *solver.Solver
+	clauses [][]solver.Lit // Normalized clauses, without their selector
+	sels    []solver.Lit   // For each clause, the selector activating it when assumed
+	status  []int          // For each clause, its status (musRemoved, musCandidate or musNecessary)
+	occurs  [][]int        // For each lit, the indices of the clauses it appears in
+	nbVars  int            // Number of vars in the original problem
+	verbose bool
+}
+
+// normalize returns the clause without duplicate lits, or nil if the clause is a tautology.
+func normalize(clause []int) []solver.Lit {
+	lits := make([]solver.Lit, 0, len(clause))
+	for _, val := range clause {
+		lit := solver.IntToLit(int32(val))
+		dup := false
+		for _, lit2 := range lits {
+			if lit2 == lit {
+				dup = true
+				break
+			}
+			if lit2 == lit.Negation() {
+				return nil
+			}
+		}
+		if !dup {
+			lits = append(lits, lit)
+		}
+	}
+	return lits
+}
+
+// MUSIncremental returns a Minimal Unsatisfiable Subset for the problem using a single incremental solver.
+// A MUS is an unsatisfiable subset such that, if any of its clause is removed,
+// the problem becomes satisfiable.
+// Each clause is associated with a selector literal, and the same solver is called several times
+// under different assumptions. It uses a deletion-based algorithm, with several improvements:
+// when the problem without a given clause is still UNSAT, all clauses not used to prove so are removed at once (clause-set refinement);
+// when a clause is proven necessary, the model found is used to detect other necessary clauses without
+// calling the solver (model rotation); and a clause implied by the other ones is removed
+// since its negation is assumed when testing it (redundancy removal).
+// This is usually much more efficient than the other methods, especially on big instances.
+func (pb *Problem) MUSIncremental() (mus *Problem, err error) {
+	nbVars := pb.NbVars
+	for _, clause := range pb.Clauses {
+		if len(clause) == 0 { // The empty clause alone is a MUS
+			return makeMus(pb.NbVars, [][]int{clause}), nil
+		}
+		for _, lit := range clause {
+			if lit > nbVars {
+				nbVars = lit
+			} else if -lit > nbVars {
+				nbVars = -lit
+			}
+		}
+	}
+	m := newMUSExtractor(pb.Clauses, nbVars, pb.Options.Verbose)
+	if !m.refine(m.selectors(-1, nil)) {
+		return nil, fmt.Errorf("cannot extract MUS from satisfiable problem")
+	}
+	for idx := m.nextCandidate(len(m.clauses) - 1); idx >= 0; idx = m.nextCandidate(idx) {
+		assumptions := m.selectors(idx, m.clauses[idx])
+		if m.refine(assumptions) { // Clause was not necessary
+			if m.status[idx] == musCandidate {
+				m.status[idx] = musRemoved
+			}
+			continue
+		}
+		m.status[idx] = musNecessary
+		model := m.s.Model()
+		m.rotate(idx, model)
+		if m.verbose {
+			fmt.Printf("c clause %d/%d is part of the MUS\n", idx+1, len(m.clauses))
+		}
+	}
+	var musClauses [][]int
+	for i, st := range m.status {
+		if st == musNecessary {
+			musClauses = append(musClauses, pb.Clauses[i])
+		}
+	}
+	return makeMus(pb.NbVars, musClauses), nil
+}
+
+// newMUSExtractor returns a musExtractor where each clause from clauses is associated with a new selector.
+func newMUSExtractor(clauses [][]int, nbVars int, verbose bool) *musExtractor {
+	m := &musExtractor{
+		clauses: make([][]solver.Lit, len(clauses)),
+		sels:    make([]solver.Lit, len(clauses)),
+		status:  make([]int, len(clauses)),
+		occurs:  make([][]int, 2*nbVars),
+		nbVars:  nbVars,
+		verbose: verbose,
+	}
+	relaxed := make([][]int, 0, len(clauses))
+	for i, clause := range clauses {
+		sel := nbVars + i + 1
+		m.sels[i] = solver.IntToLit(int32(sel))
+		lits := normalize(clause)
+		if lits == nil { // A tautology is never part of a MUS
+			m.status[i] = musRemoved
+			continue
+		}
+		m.clauses[i] = lits
+		m.status[i] = musCandidate
+		relaxed = append(relaxed, make([]int, 0, len(lits)+1))
+		for _, lit := range lits {
+			m.occurs[lit] = append(m.occurs[lit], i)
+			relaxed[len(relaxed)-1] = append(relaxed[len(relaxed)-1], int(lit.Int()))
+		}
+		relaxed[len(relaxed)-1] = append(relaxed[len(relaxed)-1], -sel)
+	}
+	m.s = solver.New(solver.ParseSliceNb(relaxed, nbVars+len(clauses)))
+	m.s.Verbose = verbose
+	return m
+}
+
+// nextCandidate returns the index of the last candidate clause whose index is <= idx, or -1 if there is none.
+func (m *musExtractor) nextCandidate(idx int) int {
+	for idx >= 0 && m.status[idx] != musCandidate {
+		idx--
+	}
+	return idx
+}
+
+// selectors returns the list of selectors for all clauses that are either candidate or necessary,
+// except the one whose index is excluded.
+// The negation of all lits in negated is added to the list.
+func (m *musExtractor) selectors(excluded int, negated []solver.Lit) []solver.Lit {
+	res := make([]solver.Lit, 0, len(m.clauses)+len(negated))
+	for i, st := range m.status {
+		if st != musRemoved && i != excluded {
+			res = append(res, m.sels[i])
+		}
+	}
+	for _, lit := range negated {
+		res = append(res, lit.Negation())
+	}
+	return res
+}
+
+// refine calls the solver under the given assumptions.
+// If the problem is UNSAT, all candidate clauses whose selector is not part of the failed assumptions
+// are removed, and true is returned.
+// If the problem is SAT, false is returned.
+// If some of the failed assumptions are not selectors (i.e they come from the negation of a clause being tested),
+// the failed selectors are only sufficient to imply that clause, so no clause is removed.
+func (m *musExtractor) refine(assumptions []solver.Lit) bool {
+	if m.s.SolveAssumptions(assumptions) == solver.Sat {
+		return false
+	}
+	inCore := make([]bool, len(m.clauses))
+	for _, lit := range m.s.FailedAssumptions() {
+		idx := int(lit.Var()) - m.nbVars
+		if idx < 0 {
+			return true
+		}
+		inCore[idx] = true
+	}
+	nbRemoved := 0
+	for i, st := range m.status {
+		if st == musCandidate && !inCore[i] {
+			m.status[i] = musRemoved
+			nbRemoved++
+		}
+	}
+	if m.verbose && nbRemoved > 0 {
+		fmt.Printf("c %d clause(s) removed through refinement\n", nbRemoved)
+	}
+	return true
+}
+
+// falsified returns true iff the idx'th clause is falsified by the model.
+func (m *musExtractor) falsified(idx int, model []bool) bool {
+	for _, lit := range m.clauses[idx] {
+		if model[lit.Var()] == lit.IsPositive() {
+			return false
+		}
+	}
+	return true
+}
+
+// rotate is called when model satisfies all non-removed clauses but the idx'th one, which is necessary.
+// It flips, one at a time, each var from that clause. If exactly one clause becomes falsified
+// by the new model, that clause is necessary too, and the process is repeated from it.
+func (m *musExtractor) rotate(idx int, model []bool) {
+	for _, lit := range m.clauses[idx] {
+		v := lit.Var()
+		model[v] = !model[v]
+		// lit is now true, so only clauses containing its negation can be falsified
+		unique := -1
+		nb := 0
+		for _, idx2 := range m.occurs[lit.Negation()] {
+			if m.status[idx2] != musRemoved && m.falsified(idx2, model) {
+				nb++
+				unique = idx2
+				if nb > 1 {
+					break
+				}
+			}
+		}
+		if nb == 1 && m.status[unique] == musCandidate {
+			m.status[unique] = musNecessary
+			if m.verbose {
+				fmt.Printf("c clause %d/%d is part of the MUS (model rotation)\n", unique+1, len(m.clauses))
+			}
+			m.rotate(unique, model)
+		}
+		model[v] = !model[v]
+	}
+}
@@ -206,5 +206,5 @@ func (pb *Problem) MUSDeletion() (mus *Problem, err error) {
 // The exact algorithm used to compute the MUS is not guaranteed. If you want to use a given algorithm,
 // use the relevant functions.
 func (pb *Problem) MUS() (mus *Problem, err error) {
-	return pb.MUSDeletion()
+	return pb.MUSIncremental()
 }
@@ -6,7 +6,7 @@ import (
 	"strings"
 )
 
-func ExampleInstanceIsAMUS() {
+func ExampleProblem_MUS_instanceIsAMUS() {
 	const cnf = `p cnf 1 2
 	c This is a simple problem
 	1 0
@@ -6,6 +6,8 @@ import (
 	"io"
 	"strconv"
 	"strings"
+
+	"github.com/DoOR-Team/gophersat/input"
 )
 
 // parseClause parses a line representing a clause in the DIMACS CNF syntax.
@@ -25,6 +27,10 @@ func parseClause(fields []string) ([]int, error) {
 
 // ParseCNF parses a CNF and returns the associated problem.
 func ParseCNF(r io.Reader) (*Problem, error) {
+	r, err := input.Decompress(r)
+	if err != nil {
+		return nil, err
+	}
 	sc := bufio.NewScanner(r)
 	var pb Problem
 	for sc.Scan() {
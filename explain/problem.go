@@ -1,7 +1,10 @@
 package explain
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 )
 
@@ -109,17 +112,21 @@ func (pb *Problem) unsat() bool {
 
 // CNF returns a representation of the problem using the Dimacs syntax.
 func (pb *Problem) CNF() string {
-	lines := make([]string, 1, pb.nbClauses+1)
-	lines[0] = fmt.Sprintf("p cnf %d %d", pb.NbVars, pb.nbClauses)
-	for i := 0; i < pb.nbClauses; i++ {
-		clause := pb.Clauses[i]
-		strClause := make([]string, len(clause)+1)
-		for i, lit := range clause {
-			strClause[i] = fmt.Sprintf("%d", lit)
+	var b strings.Builder
+	pb.WriteDIMACS(&b)
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// WriteDIMACS writes the problem to w in the DIMACS CNF format.
+func (pb *Problem) WriteDIMACS(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p cnf %d %d\n", pb.NbVars, pb.nbClauses)
+	for _, clause := range pb.Clauses[:pb.nbClauses] {
+		for _, lit := range clause {
+			bw.WriteString(strconv.Itoa(lit))
+			bw.WriteByte(' ')
 		}
-		strClause[len(clause)] = "0"
-		line := strings.Join(strClause, " ")
-		lines = append(lines, line)
+		bw.WriteString("0\n")
 	}
-	return strings.Join(lines, "\n")
+	return bw.Flush()
 }
@@ -0,0 +1,12 @@
+// Package input opens problem files for the parsers of gophersat.
+//
+// Files can be compressed with gzip, bzip2 or xz: compression is detected from the first bytes of the content,
+// not from the name of the file, and the content is transparently decompressed.
+// The "-" path stands for the standard input.
+//
+// Gzip and bzip2 are decompressed with the standard library. Xz is not supported by the standard library,
+// so xz content is decompressed by the external "xz" command, that must be in the PATH.
+//
+// The format of a problem (DIMACS CNF, WCNF, OPB, WBO, iCNF, QDIMACS, AIGER or bf) can also be detected from its content,
+// so that files with a missing or misleading extension are still parsed correctly.
+package input
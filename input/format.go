@@ -0,0 +1,164 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A Format is the format of a problem.
+type Format int
+
+const (
+	// Unknown means the format could not be detected.
+	Unknown Format = iota
+	// CNF is the DIMACS CNF format.
+	CNF
+	// WCNF is the weighted CNF format, for MAXSAT problems.
+	WCNF
+	// OPB is the format for pseudo-boolean problems.
+	OPB
+	// ICNF is the incremental CNF format.
+	ICNF
+	// QDIMACS is the format for quantified boolean formulas.
+	QDIMACS
+	// BF is the syntax of the bf package.
+	BF
+	// WBO is the format for weighted boolean optimization problems, i.e OPB problems with soft constraints.
+	WBO
+	// AAG is the ASCII AIGER format, for and-inverter graphs.
+	AAG
+	// AIG is the binary AIGER format.
+	AIG
+)
+
+// String returns the name of the format, which is also its usual file extension.
+func (f Format) String() string {
+	switch f {
+	case Unknown:
+		return "unknown"
+	case CNF:
+		return "cnf"
+	case WCNF:
+		return "wcnf"
+	case OPB:
+		return "opb"
+	case ICNF:
+		return "icnf"
+	case QDIMACS:
+		return "qdimacs"
+	case BF:
+		return "bf"
+	case WBO:
+		return "wbo"
+	case AAG:
+		return "aag"
+	case AIG:
+		return "aig"
+	default:
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+}
+
+// detectSize is the number of bytes read to detect the format of a content.
+const detectSize = 1 << 16
+
+// Detect returns the format of the content of r. It also returns a reader on the whole content of r.
+// The header of the content comes first: "p cnf" for CNF, or QDIMACS if quantifier lines follow it,
+// "p wcnf", "p inccnf", "* #variable=" for OPB, or WBO if "#soft=" follows it, and "aag" or "aig" for AIGER files.
+// Else, the format is deduced from the extension of name, ignoring a compression extension (".gz", ".bz2"
+// or ".xz"). If the extension is missing or unknown,
+// the format is guessed from the content: hard clauses of headerless WCNF files, comments starting with "*"
+// and objectives for OPB, a "soft:" line for WBO.
+// If nothing is conclusive, the content is considered to be a CNF problem.
+func Detect(r io.Reader, name string) (Format, io.Reader) {
+	br := bufio.NewReaderSize(r, detectSize)
+	content, _ := br.Peek(detectSize)
+	f, header := detectContent(content)
+	if header {
+		return f, br
+	}
+	if f2 := detectName(name); f2 != Unknown {
+		return f2, br
+	}
+	if f != Unknown {
+		return f, br
+	}
+	return CNF, br
+}
+
+// detectContent detects the format of a problem from the beginning of its content.
+// header is true iff the format was read from a header, rather than guessed.
+func detectContent(content []byte) (f Format, header bool) {
+	cnfHeader := false // Was a "p cnf" header found?
+	for len(content) > 0 {
+		var line []byte
+		if i := bytes.IndexByte(content, '\n'); i >= 0 {
+			line, content = content[:i], content[i+1:]
+		} else { // Last line may be truncated
+			line, content = content, nil
+		}
+		fields := strings.Fields(string(line))
+		switch {
+		case len(fields) == 0:
+			continue
+		case strings.HasPrefix(fields[0], "*"):
+			if !cnfHeader {
+				header := strings.Contains(string(line), "#variable=") // "* #variable= n #constraint= m" header
+				if strings.Contains(string(line), "#soft=") {
+					return WBO, header
+				}
+				return OPB, header
+			}
+		case fields[0] == "c":
+			continue
+		case cnfHeader:
+			if fields[0] == "e" || fields[0] == "a" {
+				return QDIMACS, true
+			}
+			return CNF, true
+		case fields[0] == "p" && len(fields) > 1:
+			switch fields[1] {
+			case "cnf":
+				cnfHeader = true
+			case "wcnf":
+				return WCNF, true
+			case "inccnf":
+				return ICNF, true
+			default:
+				return Unknown, false
+			}
+		case fields[0] == "h":
+			return WCNF, false
+		case fields[0] == "aag" && !cnfHeader:
+			return AAG, true
+		case fields[0] == "aig" && !cnfHeader:
+			return AIG, true
+		case fields[0] == "soft:":
+			return WBO, false
+		case fields[0] == "min:" || fields[0] == "max:":
+			return OPB, false
+		default:
+			return Unknown, false
+		}
+	}
+	if cnfHeader { // Header, but no clause at all
+		return CNF, true
+	}
+	return Unknown, false
+}
+
+// detectName detects the format of a problem from the extension of its file name.
+func detectName(name string) Format {
+	for _, ext := range []string{".gz", ".bz2", ".xz"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	for _, f := range []Format{CNF, WCNF, OPB, ICNF, QDIMACS, BF, WBO, AAG, AIG} {
+		if strings.HasSuffix(name, "."+f.String()) {
+			return f
+		}
+	}
+	return Unknown
+}
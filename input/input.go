@@ -0,0 +1,162 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// Magic bytes of the supported compression formats.
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	xzMagic    = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+)
+
+// Decompress returns a reader on the decompressed content of r if r is compressed with gzip, bzip2 or xz,
+// or a reader on the content of r itself else.
+// Xz content is decompressed by the external "xz" command: an error is returned if it is not in the PATH.
+func Decompress(r io.Reader) (io.Reader, error) {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	magic, err := peekMagic(br)
+	if err != nil {
+		return nil, fmt.Errorf("could not read content: %v", err)
+	}
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("could not read gzip content: %v", err)
+		}
+		return zr, nil
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return bzip2.NewReader(br), nil
+	case bytes.HasPrefix(magic, xzMagic):
+		return newCmdReader(br, "xz", "-dc")
+	default:
+		return br, nil
+	}
+}
+
+// peekMagic returns the first bytes of the content of br, without consuming them.
+// So that interactive inputs are not blocked, it only waits for more bytes than are available
+// when the bytes read so far might be the beginning of a magic number.
+func peekMagic(br *bufio.Reader) ([]byte, error) {
+	if _, err := br.Peek(1); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, err
+	}
+	n := br.Buffered()
+	if n > len(xzMagic) {
+		n = len(xzMagic)
+	}
+	magic, _ := br.Peek(n)
+	for _, m := range [][]byte{gzipMagic, bzip2Magic, xzMagic} {
+		if len(magic) < len(m) && bytes.HasPrefix(m, magic) {
+			magic, err := br.Peek(len(xzMagic))
+			if err == io.EOF {
+				err = nil
+			}
+			return magic, err
+		}
+	}
+	return magic, nil
+}
+
+// Open opens the file at the given path, or the standard input if path is "-", and decompresses its content if needed.
+func Open(path string) (io.ReadCloser, error) {
+	var f *os.File
+	if path == "-" {
+		f = os.Stdin
+	} else {
+		var err error
+		if f, err = os.Open(path); err != nil {
+			return nil, fmt.Errorf("could not open %q: %v", path, err)
+		}
+	}
+	r, err := Decompress(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("could not read %q: %v", path, err)
+	}
+	return readCloser{Reader: r, closers: []io.Closer{f}}, nil
+}
+
+// A readCloser reads from its reader, and closes all its closers when it is closed.
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (rc readCloser) Close() error {
+	var res error
+	if c, ok := rc.Reader.(io.Closer); ok {
+		res = c.Close()
+	}
+	for _, c := range rc.closers {
+		if err := c.Close(); err != nil && res == nil {
+			res = err
+		}
+	}
+	return res
+}
+
+// A cmdReader reads the output of an external command, whose input is another reader.
+type cmdReader struct {
+	out  io.ReadCloser
+	cmd  *exec.Cmd
+	errs bytes.Buffer // Error output of the command
+	done bool
+}
+
+func newCmdReader(r io.Reader, name string, args ...string) (*cmdReader, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("the %q command, needed to decompress the content, was not found in the PATH", name)
+	}
+	cr := &cmdReader{cmd: exec.Command(name, args...)}
+	cr.cmd.Stdin = r
+	cr.cmd.Stderr = &cr.errs
+	out, err := cr.cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("could not run %s: %v", name, err)
+	}
+	cr.out = out
+	if err := cr.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("could not run %s: %v", name, err)
+	}
+	return cr, nil
+}
+
+// Read reads the output of the command. Once the output was entirely read, the command's failure, if any, is reported.
+func (cr *cmdReader) Read(p []byte) (int, error) {
+	n, err := cr.out.Read(p)
+	if err == io.EOF && !cr.done {
+		cr.done = true
+		if werr := cr.cmd.Wait(); werr != nil {
+			return n, fmt.Errorf("%s failed: %v: %s", cr.cmd.Path, werr, bytes.TrimSpace(cr.errs.Bytes()))
+		}
+	}
+	return n, err
+}
+
+// Close stops the command if it is still running.
+func (cr *cmdReader) Close() error {
+	if cr.done {
+		return nil
+	}
+	cr.done = true
+	cr.out.Close()
+	cr.cmd.Process.Kill()
+	cr.cmd.Wait()
+	return nil
+}
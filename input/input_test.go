@@ -0,0 +1,108 @@
+package input
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const plainCNF = "p cnf 2 1\n1 -2 0\n"
+
+// plainCNF, compressed with bzip2.
+var bzip2CNF = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0xbd, 0x53, 0x52, 0xa6, 0x00, 0x00, 0x08, 0x59, 0x80,
+	0x00, 0x10, 0x40, 0x02, 0x70, 0x00, 0x09, 0x01, 0x40, 0x00, 0x20, 0x00, 0x22, 0x03, 0x4c, 0x9a, 0x10, 0x03, 0x00,
+	0x2b, 0x61, 0x33, 0x25, 0x5c, 0x36, 0x3c, 0x5d, 0xc9, 0x14, 0xe1, 0x42, 0x42, 0xf5, 0x4d, 0x4a, 0x98,
+}
+
+// plainCNF, compressed with xz.
+var xzCNF = []byte{
+	0xfd, 0x37, 0x7a, 0x58, 0x5a, 0x00, 0x00, 0x04, 0xe6, 0xd6, 0xb4, 0x46, 0x04, 0xc0, 0x15, 0x11, 0x21, 0x01, 0x16,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xb2, 0x18, 0xdc, 0x5f, 0x01, 0x00, 0x10, 0x70, 0x20, 0x63,
+	0x6e, 0x66, 0x20, 0x32, 0x20, 0x31, 0x0a, 0x31, 0x20, 0x2d, 0x32, 0x20, 0x30, 0x0a, 0x00, 0x00, 0x00, 0x00, 0x2b,
+	0x97, 0xff, 0x79, 0x29, 0x10, 0x05, 0x1c, 0x00, 0x01, 0x31, 0x11, 0x6b, 0x92, 0x6b, 0x8c, 0x1f, 0xb6, 0xf3, 0x7d,
+	0x01, 0x00, 0x00, 0x00, 0x00, 0x04, 0x59, 0x5a,
+}
+
+func TestDecompress(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(plainCNF))
+	w.Close()
+	inputs := map[string][]byte{"plain": []byte(plainCNF), "gzip": gz.Bytes(), "bzip2": bzip2CNF, "empty": nil}
+	if _, err := exec.LookPath("xz"); err == nil {
+		inputs["xz"] = xzCNF
+	}
+	for name, content := range inputs {
+		r, err := Decompress(bytes.NewReader(content))
+		if err != nil {
+			t.Errorf("%s: could not decompress: %v", name, err)
+			continue
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Errorf("%s: could not read: %v", name, err)
+		} else if expected := plainCNF; name != "empty" && string(data) != expected {
+			t.Errorf("%s: expected %q, got %q", name, expected, data)
+		}
+	}
+}
+
+func TestDecompressNoXz(t *testing.T) {
+	t.Setenv("PATH", "")
+	_, err := Decompress(bytes.NewReader(xzCNF))
+	if expected := `the "xz" command, needed to decompress the content, was not found in the PATH`; err == nil || err.Error() != expected {
+		t.Errorf("expected error %q, got %v", expected, err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		content, name string
+		expected      Format
+	}{
+		{plainCNF, "problem", CNF},
+		{"c comment\np cnf 3 1\ne 1 0\na 2 0\n1 2 3 0\n", "problem.cnf", QDIMACS},
+		{"p wcnf 2 1 10\n10 1 0\n", "problem.cnf", WCNF},
+		{"c new format\nh 1 2 0\n3 -1 0\n", "", WCNF},
+		{"* #variable= 2 #constraint= 1\n+1 x1 +1 x2 >= 1 ;\n", "problem.gz", OPB},
+		{"p inccnf\n1 2 0\na -1 0\n", "", ICNF},
+		{"p cnf 2 0\n", "", CNF},
+		{"a & ^b", "rules.bf", BF},
+		{"3 1 2 0\n", "problem.wcnf.xz", WCNF},
+		{"a & ^b", "rules.txt", CNF},
+		{"* #variable= 2 #constraint= 1 #soft= 1\nsoft: 3 ;\n[2] +1 x1 >= 1 ;\n", "problem", WBO},
+		{"soft: ;\n[2] +1 x1 >= 1 ;\n", "", WBO},
+		{"max: +1 x1 x2 ;\n+1 x1 >= 1 ;\n", "", OPB},
+		{"aag 3 2 0 1 1\n2\n4\n6\n6 2 4\n", "", AAG},
+		{"aig 3 2 0 1 1\n6\n\x02\x02", "circuit.aag", AIG},
+		{"", "circuit.aig.gz", AIG},
+		{"h | x;\n", "rules.bf", BF},
+		{"* comment\nmin: +1 x1 ;\n", "problem.wbo", WBO},
+		{"1 2 0\n-1 0\n", "problem.txt", CNF},
+		{"1 2 0\n-1 0\n", "-", CNF},
+		{"p wcnf 2 1 10\n10 1 0\n", "problem.opb", WCNF},
+		{"* #variable= 2 #constraint= 1\n+1 x1 +1 x2 >= 1 ;\n", "x.cnf", OPB},
+		{"* #variable= 1 #constraint= 0 #soft= 1\nsoft: 3 ;\n[2] +1 x1 >= 1 ;\n", "x.opb", WBO},
+	}
+	for _, test := range tests {
+		f, r := Detect(strings.NewReader(test.content), test.name)
+		if f != test.expected {
+			t.Errorf("for %q (%q), expected format %v, got %v", test.content, test.name, test.expected, f)
+		}
+		if data, _ := ioutil.ReadAll(r); string(data) != test.content {
+			t.Errorf("for %q, content read back as %q", test.content, data)
+		}
+	}
+}
+
+func ExampleDetect() {
+	f, _ := Detect(strings.NewReader("p wcnf 2 1 10\n10 1 0\n"), "problem.cnf")
+	fmt.Println(f)
+	// Output:
+	// wcnf
+}
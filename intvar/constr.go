@@ -0,0 +1,188 @@
+package intvar
+
+import (
+	"github.com/DoOR-Team/gophersat/bf"
+)
+
+// A Term is an integer variable multiplied by a coefficient, in a linear constraint.
+type Term struct {
+	Coeff int
+	Var   *Var
+}
+
+// ge returns the formula stating v >= val.
+func (v *Var) ge(val int) bf.Formula {
+	switch {
+	case val <= v.Min:
+		return bf.True
+	case val > v.Max:
+		return bf.False
+	case v.Encoding == Order:
+		return bf.Var(v.bits[val-v.Min-1])
+	default:
+		return LinearGeq(val, Term{1, v})
+	}
+}
+
+// Geq returns the constraint v >= val.
+func (v *Var) Geq(val int) bf.Formula {
+	return v.ge(val)
+}
+
+// Gt returns the constraint v > val.
+func (v *Var) Gt(val int) bf.Formula {
+	return v.ge(val + 1)
+}
+
+// Leq returns the constraint v <= val.
+func (v *Var) Leq(val int) bf.Formula {
+	return bf.Not(v.ge(val + 1))
+}
+
+// Lt returns the constraint v < val.
+func (v *Var) Lt(val int) bf.Formula {
+	return bf.Not(v.ge(val))
+}
+
+// Eq returns the constraint v == val.
+func (v *Var) Eq(val int) bf.Formula {
+	switch {
+	case val < v.Min || val > v.Max:
+		return bf.False
+	case v.Encoding == Order:
+		return bf.And(v.ge(val), bf.Not(v.ge(val+1)))
+	default:
+		res := make([]bf.Formula, len(v.bits))
+		for i, name := range v.bits {
+			res[i] = bf.Var(name)
+			if (val-v.Min)&(1<<uint(i)) == 0 {
+				res[i] = bf.Not(res[i])
+			}
+		}
+		return bf.And(res...)
+	}
+}
+
+// Neq returns the constraint v != val.
+func (v *Var) Neq(val int) bf.Formula {
+	return bf.Not(v.Eq(val))
+}
+
+// In returns the constraint stating v is one of the given values.
+func (v *Var) In(vals ...int) bf.Formula {
+	res := make([]bf.Formula, len(vals))
+	for i, val := range vals {
+		res[i] = v.Eq(val)
+	}
+	return bf.Or(res...)
+}
+
+// linear returns the boolean vars and the weights such that sum(terms) = offset + sum(weights[i].names[i]).
+func linear(terms []Term) (names []string, weights []int, offset int) {
+	for _, t := range terms {
+		names2, weights2, offset2 := t.Var.terms()
+		names = append(names, names2...)
+		for _, w := range weights2 {
+			weights = append(weights, t.Coeff*w)
+		}
+		offset += t.Coeff * offset2
+	}
+	return names, weights, offset
+}
+
+// LinearLeq returns the constraint stating the sum of the terms is at most k.
+func LinearLeq(k int, terms ...Term) bf.Formula {
+	names, weights, offset := linear(terms)
+	return bf.PBLeq(k-offset, weights, vars(names)...)
+}
+
+// LinearGeq returns the constraint stating the sum of the terms is at least k.
+func LinearGeq(k int, terms ...Term) bf.Formula {
+	return LinearLeq(-k, negated(terms)...)
+}
+
+// LinearEq returns the constraint stating the sum of the terms is exactly k.
+func LinearEq(k int, terms ...Term) bf.Formula {
+	return bf.And(LinearLeq(k, terms...), LinearGeq(k, terms...))
+}
+
+func negated(terms []Term) []Term {
+	res := make([]Term, len(terms))
+	for i, t := range terms {
+		res[i] = Term{-t.Coeff, t.Var}
+	}
+	return res
+}
+
+// Leq returns the constraint x <= y.
+func Leq(x, y *Var) bf.Formula {
+	return LinearLeq(0, Term{1, x}, Term{-1, y})
+}
+
+// Lt returns the constraint x < y.
+func Lt(x, y *Var) bf.Formula {
+	return LinearLeq(-1, Term{1, x}, Term{-1, y})
+}
+
+// Eq returns the constraint x == y.
+func Eq(x, y *Var) bf.Formula {
+	return LinearEq(0, Term{1, x}, Term{-1, y})
+}
+
+// Neq returns the constraint x != y.
+func Neq(x, y *Var) bf.Formula {
+	return bf.Not(Eq(x, y))
+}
+
+// AllDifferent returns the constraint stating all given variables have a different value.
+func AllDifferent(vars ...*Var) bf.Formula {
+	if len(vars) == 0 {
+		return bf.True
+	}
+	min, max := vars[0].Min, vars[0].Max
+	for _, v := range vars[1:] {
+		if v.Min < min {
+			min = v.Min
+		}
+		if v.Max > max {
+			max = v.Max
+		}
+	}
+	var res []bf.Formula
+	for val := min; val <= max; val++ {
+		var eqs []bf.Formula
+		for _, v := range vars {
+			if val >= v.Min && val <= v.Max {
+				eqs = append(eqs, v.Eq(val))
+			}
+		}
+		if len(eqs) > 1 {
+			res = append(res, bf.AtMost(1, eqs...))
+		}
+	}
+	return bf.And(res...)
+}
+
+// Element returns the constraint value == array[index].
+// index must be between 0 and len(array)-1.
+func Element(index *Var, array []*Var, value *Var) bf.Formula {
+	res := []bf.Formula{index.Geq(0), index.Lt(len(array))}
+	for i, v := range array {
+		if i >= index.Min && i <= index.Max {
+			res = append(res, bf.Implies(index.Eq(i), Eq(v, value)))
+		}
+	}
+	return bf.And(res...)
+}
+
+// ElementConst returns the constraint value == array[index], where array is a list of constants.
+// index must be between 0 and len(array)-1.
+func ElementConst(index *Var, array []int, value *Var) bf.Formula {
+	res := []bf.Formula{index.Geq(0), index.Lt(len(array))}
+	for i, val := range array {
+		if i >= index.Min && i <= index.Max {
+			res = append(res, bf.Implies(index.Eq(i), value.Eq(val)))
+		}
+	}
+	return bf.And(res...)
+}
@@ -0,0 +1,25 @@
+// Package intvar offers bounded integer variables on top of the bf package.
+//
+// Each integer variable is represented by a set of boolean variables, with one of two encodings:
+//
+// - the order encoding (Model.Int) uses a boolean variable "x>=v" for each value v of the domain
+// but the lowest one. It needs as many boolean variables as there are values in the domain,
+// but comparisons with constants are single literals, and propagation is very good,
+//
+// - the log encoding (Model.LogInt) uses the binary representation of x-min. It only needs
+// a logarithmic number of boolean variables, and is suitable for large domains.
+//
+// Constraints on variables (comparisons, linear constraints, AllDifferent, Element) are
+// bf.Formula values, so they can be freely combined with the bf connectors:
+//
+//	m := intvar.NewModel()
+//	x := m.Int("x", 0, 5)
+//	y := m.Int("y", 0, 5)
+//	m.Add(intvar.LinearEq(7, intvar.Term{Coeff: 1, Var: x}, intvar.Term{Coeff: 1, Var: y}))
+//	m.Add(bf.Or(x.Eq(1), intvar.Lt(y, x)))
+//	values := m.Solve() // e.g map[x:4 y:3]
+//
+// Linear constraints are translated to PB constraints, which are handled natively by the solver.
+// When a problem is built directly with the solver package, Model.DomainConstrs and LinearConstr
+// generate the corresponding solver.PBConstr, and Var.Decode reads the value of a variable in a solver model.
+package intvar
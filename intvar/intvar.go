@@ -0,0 +1,197 @@
+package intvar
+
+import (
+	"fmt"
+	"math/bits"
+
+	"github.com/DoOR-Team/gophersat/bf"
+)
+
+// An Encoding is the way an integer variable is represented with boolean variables.
+type Encoding int
+
+const (
+	// Order uses a boolean variable "x>=v" for each value v of the domain but the lowest one.
+	Order Encoding = iota
+	// Log uses the binary representation of x-min.
+	Log
+)
+
+// A Var is a bounded integer variable.
+type Var struct {
+	Name     string
+	Min, Max int // Bounds of the domain, inclusive
+	Encoding Encoding
+	bits     []string // Names of the boolean vars
+	indices  []int    // Indices of the boolean vars in the model
+}
+
+// A Model is a set of integer variables and of constraints on them.
+type Model struct {
+	vars    []*Var
+	names   map[string]bool
+	nbBools int // Number of boolean vars created so far
+	constrs []bf.Formula
+}
+
+// NewModel returns a new, empty model.
+func NewModel() *Model {
+	return &Model{names: make(map[string]bool)}
+}
+
+// Int creates a new integer variable with the given name, whose value is between min and max, inclusive.
+// The variable uses the order encoding.
+// Will panic if max < min or if the name is already in use.
+func (m *Model) Int(name string, min, max int) *Var {
+	v := m.newVar(name, min, max, Order)
+	for val := min + 1; val <= max; val++ {
+		m.addBool(v, fmt.Sprintf("%s>=%d", name, val))
+	}
+	return v
+}
+
+// LogInt creates a new integer variable with the given name, whose value is between min and max, inclusive.
+// The variable uses the log encoding.
+// Will panic if max < min or if the name is already in use.
+func (m *Model) LogInt(name string, min, max int) *Var {
+	v := m.newVar(name, min, max, Log)
+	for i := 0; i < bits.Len(uint(max-min)); i++ {
+		m.addBool(v, fmt.Sprintf("%s#%d", name, i))
+	}
+	return v
+}
+
+func (m *Model) newVar(name string, min, max int, enc Encoding) *Var {
+	if max < min {
+		panic(fmt.Errorf("empty domain [%d, %d] for %q", min, max, name))
+	}
+	if m.names[name] {
+		panic(fmt.Errorf("duplicate variable name %q", name))
+	}
+	m.names[name] = true
+	v := &Var{Name: name, Min: min, Max: max, Encoding: enc}
+	m.vars = append(m.vars, v)
+	return v
+}
+
+func (m *Model) addBool(v *Var, name string) {
+	m.nbBools++
+	v.bits = append(v.bits, name)
+	v.indices = append(v.indices, m.nbBools)
+}
+
+// Vars returns the variables of the model, in the order they were created.
+func (m *Model) Vars() []*Var {
+	return m.vars
+}
+
+// NbBools returns the number of boolean variables used to represent the integer variables.
+// They are numbered from 1 to NbBools in the PB constraints generated by the model.
+func (m *Model) NbBools() int {
+	return m.nbBools
+}
+
+// Add adds the given constraints to the model.
+func (m *Model) Add(constrs ...bf.Formula) {
+	m.constrs = append(m.constrs, constrs...)
+}
+
+// Formula returns the formula associated with the model: its constraints, and
+// the constraints defining the domain of its variables.
+func (m *Model) Formula() bf.Formula {
+	var res []bf.Formula
+	for _, v := range m.vars {
+		res = append(res, v.domain())
+	}
+	return bf.And(append(res, m.constrs...)...)
+}
+
+// Solve solves the model.
+// It returns the value of each variable, or nil if the model has no solution.
+func (m *Model) Solve() map[string]int {
+	model := bf.Solve(m.Formula())
+	if model == nil {
+		return nil
+	}
+	res := make(map[string]int, len(m.vars))
+	for _, v := range m.vars {
+		res[v.Name] = v.Value(model)
+	}
+	return res
+}
+
+// domain returns the formula stating the encoding of v is consistent.
+func (v *Var) domain() bf.Formula {
+	var res []bf.Formula
+	switch v.Encoding {
+	case Order:
+		// x>=val+1 -> x>=val
+		for i := 1; i < len(v.bits); i++ {
+			res = append(res, bf.Implies(bf.Var(v.bits[i]), bf.Var(v.bits[i-1])))
+		}
+	case Log:
+		if names, weights, _ := v.terms(); v.Max-v.Min != sum(weights) {
+			res = append(res, bf.PBLeq(v.Max-v.Min, weights, vars(names)...))
+		}
+	}
+	return bf.And(res...)
+}
+
+// terms returns the boolean vars and the weights such that v = offset + sum(weights[i].names[i]).
+func (v *Var) terms() (names []string, weights []int, offset int) {
+	weights = make([]int, len(v.bits))
+	for i := range weights {
+		switch v.Encoding {
+		case Order:
+			weights[i] = 1
+		case Log:
+			weights[i] = 1 << uint(i)
+		}
+	}
+	return v.bits, weights, v.Min
+}
+
+// Value returns the value of v in the given bf model.
+// Boolean variables that do not appear in the model are considered false.
+func (v *Var) Value(model map[string]bool) int {
+	names, weights, res := v.terms()
+	for i, name := range names {
+		if model[name] {
+			res += weights[i]
+		}
+	}
+	return res
+}
+
+// Decode returns the value of v in the given solver model, as returned by solver.Solver.Model.
+// Boolean variables whose index is beyond the model are considered false.
+func (v *Var) Decode(model []bool) int {
+	_, weights, res := v.terms()
+	for i, idx := range v.indices {
+		if idx <= len(model) && model[idx-1] {
+			res += weights[i]
+		}
+	}
+	return res
+}
+
+// String returns the name of the variable.
+func (v *Var) String() string {
+	return v.Name
+}
+
+func vars(names []string) []bf.Formula {
+	res := make([]bf.Formula, len(names))
+	for i, name := range names {
+		res[i] = bf.Var(name)
+	}
+	return res
+}
+
+func sum(vals []int) int {
+	res := 0
+	for _, val := range vals {
+		res += val
+	}
+	return res
+}
@@ -0,0 +1,179 @@
+package intvar
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/DoOR-Team/gophersat/bf"
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// newRandomVar creates a random var in m.
+func newRandomVar(r *rand.Rand, m *Model, name string) *Var {
+	min := r.Intn(7) - 3
+	max := min + r.Intn(6)
+	if r.Intn(2) == 0 {
+		return m.Int(name, min, max)
+	}
+	return m.LogInt(name, min, max)
+}
+
+// forAll calls f on every assignment of the vars.
+// It stops as soon as f returns true.
+func forAll(vars []*Var, values map[string]int, f func(values map[string]int) bool) bool {
+	if len(vars) == 0 {
+		return f(values)
+	}
+	v := vars[0]
+	for val := v.Min; val <= v.Max; val++ {
+		values[v.Name] = val
+		if forAll(vars[1:], values, f) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLinear(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 300; iter++ {
+		m := NewModel()
+		vars := make([]*Var, 1+r.Intn(3))
+		terms := make([]Term, len(vars))
+		for i := range vars {
+			vars[i] = newRandomVar(r, m, fmt.Sprintf("x%d", i))
+			terms[i] = Term{r.Intn(7) - 3, vars[i]}
+		}
+		k := r.Intn(11) - 5
+		val := r.Intn(7) - 3
+		m.Add(LinearLeq(k, terms...), bf.Or(vars[0].Neq(val), AllDifferent(vars...)))
+		valid := func(values map[string]int) bool {
+			sum := 0
+			for _, t := range terms {
+				sum += t.Coeff * values[t.Var.Name]
+			}
+			if sum > k {
+				return false
+			}
+			if values[vars[0].Name] != val {
+				return true
+			}
+			for i := range vars {
+				for j := i + 1; j < len(vars); j++ {
+					if values[vars[i].Name] == values[vars[j].Name] {
+						return false
+					}
+				}
+			}
+			return true
+		}
+		sat := forAll(vars, make(map[string]int), valid)
+		values := m.Solve()
+		if (values != nil) != sat {
+			t.Fatalf("iter %d: expected sat=%t, got %v", iter, sat, values)
+		}
+		if values != nil {
+			for _, v := range vars {
+				if values[v.Name] < v.Min || values[v.Name] > v.Max {
+					t.Fatalf("iter %d: value %d out of domain for %s", iter, values[v.Name], v.Name)
+				}
+			}
+			if !valid(values) {
+				t.Fatalf("iter %d: invalid solution %v", iter, values)
+			}
+		}
+	}
+}
+
+func TestComparisons(t *testing.T) {
+	for _, enc := range []Encoding{Order, Log} {
+		for val := -3; val <= 8; val++ {
+			tests := []struct {
+				constr func(x *Var) bf.Formula
+				valid  func(v int) bool
+			}{
+				{func(x *Var) bf.Formula { return x.Eq(val) }, func(v int) bool { return v == val }},
+				{func(x *Var) bf.Formula { return x.Neq(val) }, func(v int) bool { return v != val }},
+				{func(x *Var) bf.Formula { return x.Leq(val) }, func(v int) bool { return v <= val }},
+				{func(x *Var) bf.Formula { return x.Lt(val) }, func(v int) bool { return v < val }},
+				{func(x *Var) bf.Formula { return x.Geq(val) }, func(v int) bool { return v >= val }},
+				{func(x *Var) bf.Formula { return x.Gt(val) }, func(v int) bool { return v > val }},
+			}
+			for i, test := range tests {
+				for v := 0; v <= 5; v++ {
+					m := NewModel()
+					x := m.Int("x", 0, 5)
+					if enc == Log {
+						x = m.LogInt("y", 0, 5)
+					}
+					m.Add(test.constr(x), x.Eq(v))
+					if sat := m.Solve() != nil; sat != test.valid(v) {
+						t.Errorf("encoding %d, test %d, val=%d, x=%d: expected %t, got %t", enc, i, val, v, test.valid(v), sat)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestElement(t *testing.T) {
+	m := NewModel()
+	idx := m.Int("idx", -1, 4)
+	array := []*Var{m.Int("a0", 0, 3), m.LogInt("a1", 0, 3), m.Int("a2", 0, 3)}
+	val := m.LogInt("val", 0, 3)
+	m.Add(Element(idx, array, val), AllDifferent(array...), val.Eq(3), array[0].Eq(1), array[1].Neq(3))
+	values := m.Solve()
+	if values == nil {
+		t.Fatalf("expected a solution")
+	}
+	if values["idx"] != 2 || values["a2"] != 3 {
+		t.Errorf("invalid solution %v", values)
+	}
+	m.Add(array[2].Neq(3))
+	if values := m.Solve(); values != nil {
+		t.Errorf("expected no solution, got %v", values)
+	}
+}
+
+func TestPBConstrs(t *testing.T) {
+	m := NewModel()
+	x := m.Int("x", 1, 6)
+	y := m.LogInt("y", -2, 10)
+	constrs := append(m.DomainConstrs(), LinearConstr(-5, Term{2, x}, Term{-3, y}), LinearConstr(0, Term{-1, y}))
+	constrs = append(constrs, LinearConstr(8, Term{1, y}))
+	s := solver.New(solver.ParsePBConstrs(constrs))
+	if status := s.Solve(); status != solver.Sat {
+		t.Fatalf("expected sat, got %v", status)
+	}
+	model := s.Model()
+	xVal, yVal := x.Decode(model), y.Decode(model)
+	if xVal < 1 || xVal > 6 || yVal < 0 || yVal > 8 || 2*xVal-3*yVal > -5 {
+		t.Errorf("invalid solution x=%d, y=%d", xVal, yVal)
+	}
+}
+
+func ExampleModel() {
+	// Three tasks of durations 2, 3 and 1 must be scheduled on a single machine, before time 6.
+	// Task 1 must start after the end of task 0.
+	m := NewModel()
+	durations := []int{2, 3, 1}
+	starts := make([]*Var, len(durations))
+	for i, d := range durations {
+		starts[i] = m.Int(fmt.Sprintf("start%d", i), 0, 6-d)
+	}
+	m.Add(Leq(starts[0], starts[1]), LinearLeq(-durations[0], Term{1, starts[0]}, Term{-1, starts[1]}))
+	for i := range starts {
+		for j := i + 1; j < len(starts); j++ {
+			// i ends before j starts, or j ends before i starts
+			m.Add(bf.Or(
+				LinearLeq(-durations[i], Term{1, starts[i]}, Term{-1, starts[j]}),
+				LinearLeq(-durations[j], Term{1, starts[j]}, Term{-1, starts[i]}),
+			))
+		}
+	}
+	m.Add(starts[2].Eq(2))
+	values := m.Solve()
+	fmt.Println(values["start0"], values["start1"], values["start2"])
+	// Output: 0 3 2
+}
@@ -0,0 +1,39 @@
+package intvar
+
+import (
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// DomainConstrs returns the PB constraints stating the encoding of each variable of the model is consistent.
+// The boolean variables are numbered from 1 to m.NbBools().
+func (m *Model) DomainConstrs() []solver.PBConstr {
+	var res []solver.PBConstr
+	for _, v := range m.vars {
+		switch v.Encoding {
+		case Order:
+			for i := 1; i < len(v.indices); i++ {
+				res = append(res, solver.PropClause(-v.indices[i], v.indices[i-1]))
+			}
+		case Log:
+			if _, weights, _ := v.terms(); v.Max-v.Min != sum(weights) {
+				res = append(res, solver.LtEq(append([]int(nil), v.indices...), weights, v.Max-v.Min))
+			}
+		}
+	}
+	return res
+}
+
+// LinearConstr returns the PB constraint stating the sum of the terms is at most k,
+// on the boolean variables of the model.
+func LinearConstr(k int, terms ...Term) solver.PBConstr {
+	var lits, weights []int
+	for _, t := range terms {
+		_, weights2, offset := t.Var.terms()
+		k -= t.Coeff * offset
+		lits = append(lits, t.Var.indices...)
+		for _, w := range weights2 {
+			weights = append(weights, t.Coeff*w)
+		}
+	}
+	return solver.LtEq(lits, weights, k)
+}
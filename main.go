@@ -3,14 +3,21 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"runtime/debug"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/DoOR-Team/gophersat/aiger"
 	"github.com/DoOR-Team/gophersat/bf"
+	"github.com/DoOR-Team/gophersat/bmc"
 	"github.com/DoOR-Team/gophersat/explain"
+	"github.com/DoOR-Team/gophersat/input"
 	"github.com/DoOR-Team/gophersat/maxsat"
+	"github.com/DoOR-Team/gophersat/qbf"
 	"github.com/DoOR-Team/gophersat/solver"
 )
 
@@ -23,62 +30,116 @@ func main() {
 		mus     bool
 		count   bool
 		help    bool
+		algo    string
+		ls      bool
+		strat   string
+		conv    string
+		inter   bool
+		depth   int
 	)
 	flag.BoolVar(&verbose, "verbose", false, "sets verbose mode on")
 	flag.BoolVar(&cert, "certified", false, "displays RUP certificate on stdout")
 	flag.BoolVar(&mus, "mus", false, "extracts a MUS from an unsat problem")
-	flag.BoolVar(&count, "count", false, "rather than solving the problem, counts the number of models it accepts")
+	flag.BoolVar(&count, "count", false, "rather than solving the problem, counts the number of models it accepts (the number of optimal models for MAXSAT problems)")
 	flag.BoolVar(&help, "help", false, "displays help")
+	flag.StringVar(&algo, "algorithm", maxsat.LinearSearch.String(), "algorithm used to solve MAXSAT problems (linear, oll or ihs)")
+	flag.StringVar(&strat, "strategy", solver.LinearSearch.String(), "strategy used to solve pseudo-boolean optimization problems (linear, binary or core)")
+	flag.StringVar(&conv, "conversion", bf.Classic.String(), "translation of boolean formulas to CNF (classic, tseitin or pg)")
+	flag.BoolVar(&inter, "interactive", false, "reads an incremental problem in the iCNF format from stdin, and answers each query as soon as it is read")
+	flag.IntVar(&depth, "bmc", 0, "checks AIGER files through bounded model checking, looking for counterexamples of at most the given number of transitions (0: latches are free)")
+	flag.BoolVar(&ls, "local-search", false, "runs a local search alongside the exact search when solving MAXSAT problems")
 	flag.Parse()
+	if inter && !help {
+		r, err := input.Decompress(os.Stdin)
+		if err == nil {
+			err = solveICNF(r, verbose)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "could not solve incremental problem: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 	if !help && len(flag.Args()) != 1 {
 		fmt.Printf("This is gophersat version 1.3, a SAT and Pseudo-Boolean solver by Fabien Delorme.\n")
-		fmt.Fprintf(os.Stderr, "Syntax : %s [options] (file.cnf|file.wcnf|file.bf|file.opb)\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Syntax : %s [options] (file.cnf|file.wcnf|file.bf|file.opb|file.wbo|file.qdimacs|file.icnf|file.aag|file.aig|-)\n", os.Args[0])
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
 	if help {
 		fmt.Printf("This is gophersat version 1.3, a SAT and Pseudo-Boolean solver by Fabien Delorme.\n")
-		fmt.Printf("Syntax : %s [options] (file.cnf|file.wcnf|file.bf|file.opb)\n", os.Args[0])
+		fmt.Printf("Syntax : %s [options] (file.cnf|file.wcnf|file.bf|file.opb|file.wbo|file.qdimacs|file.icnf|file.aag|file.aig|-)\n", os.Args[0])
 		flag.PrintDefaults()
 		os.Exit(0)
 	}
 	// /Users/jpbirdy/Workspaces/go/src/github.com/DoOR-Team/door/par16/par16-1.cnf
 	path := flag.Args()[0]
+	f, err := input.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "could not parse problem: %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close()
+	format, r := input.Detect(f, path)
 	if mus {
-		extractMUS(path)
-	} else {
-		fmt.Printf("c solving %s\n", path)
-		if strings.HasSuffix(path, ".bf") {
-			if err := parseAndSolveBF(path); err != nil {
-				fmt.Fprintf(os.Stderr, "could not parse formula: %v\n", err)
-				os.Exit(1)
-			}
-		} else if strings.HasSuffix(path, ".wcnf") {
-			if err := parseAndSolveWCNF(path, verbose); err != nil {
-				fmt.Fprintf(os.Stderr, "could not parse MAXSAT file %q: %v", path, err)
-				os.Exit(1)
-			}
+		extractMUS(r)
+		return
+	}
+	fmt.Printf("c solving %s\n", path)
+	switch format {
+	case input.BF:
+		if conversion, err := bf.ParseConversion(conv); err != nil {
+			fmt.Fprintf(os.Stderr, "could not solve formula: %v\n", err)
+			os.Exit(1)
+		} else if err := parseAndSolveBF(r, count, conversion); err != nil {
+			fmt.Fprintf(os.Stderr, "could not parse formula in %q: %v\n", path, err)
+			os.Exit(1)
+		}
+	case input.QDIMACS:
+		if err := parseAndSolveQBF(r); err != nil {
+			fmt.Fprintf(os.Stderr, "could not parse QBF file %q: %v\n", path, err)
+			os.Exit(1)
+		}
+	case input.ICNF:
+		if err := solveICNF(r, verbose); err != nil {
+			fmt.Fprintf(os.Stderr, "could not solve incremental problem %q: %v\n", path, err)
+			os.Exit(1)
+		}
+	case input.WCNF:
+		if err := parseAndSolveWCNF(r, verbose, count, algo, ls); err != nil {
+			fmt.Fprintf(os.Stderr, "could not parse MAXSAT file %q: %v\n", path, err)
+			os.Exit(1)
+		}
+	case input.AAG, input.AIG:
+		if err := parseAndSolveAIGER(r, verbose, depth); err != nil {
+			fmt.Fprintf(os.Stderr, "could not parse AIGER file %q: %v\n", path, err)
+			os.Exit(1)
+		}
+	case input.WBO:
+		if err := parseAndSolveWBO(r, verbose, algo, ls); err != nil {
+			fmt.Fprintf(os.Stderr, "could not parse WBO file %q: %v\n", path, err)
+			os.Exit(1)
+		}
+	case input.CNF, input.OPB:
+		if pb, printFn, err := parse(r, format); err != nil {
+			fmt.Fprintf(os.Stderr, "could not parse problem %q: %v\n", path, err)
+			os.Exit(1)
+		} else if count {
+			countModels(pb, verbose)
+		} else if strategy, err := solver.ParseOptimStrategy(strat); err != nil {
+			fmt.Fprintf(os.Stderr, "could not solve problem: %v\n", err)
+			os.Exit(1)
 		} else {
-			if pb, printFn, err := parse(flag.Args()[0]); err != nil {
-				fmt.Fprintf(os.Stderr, "could not parse problem: %v\n", err)
-				os.Exit(1)
-			} else if count {
-				countModels(pb, verbose)
-			} else {
-				solve(pb, verbose, cert, printFn)
-			}
+			solve(pb, verbose, cert, strategy, printFn)
 		}
+	default:
+		fmt.Fprintf(os.Stderr, "could not detect the format of %q\n", path)
+		os.Exit(1)
 	}
 }
 
-func extractMUS(path string) {
-	f, err := os.Open(path)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "could not parse problem: %v\n", err)
-		os.Exit(1)
-	}
-	defer f.Close()
-	pb, err := explain.ParseCNF(f)
+func extractMUS(r io.Reader) {
+	pb, err := explain.ParseCNF(r)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "could not parse problem: %v\n", err)
 		os.Exit(1)
@@ -88,7 +149,10 @@ func extractMUS(path string) {
 		fmt.Fprintf(os.Stderr, "could not extract subset: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Println(pb2.CNF())
+	if err := pb2.WriteDIMACS(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "could not write subset: %v\n", err)
+		os.Exit(1)
+	}
 }
 
 func countModels(pb *solver.Problem, verbose bool) {
@@ -111,7 +175,7 @@ func countModels(pb *solver.Problem, verbose bool) {
 	fmt.Println(nb)
 }
 
-func solve(pb *solver.Problem, verbose, cert bool, printFn func(chan solver.Result)) {
+func solve(pb *solver.Problem, verbose, cert bool, strat solver.OptimStrategy, printFn func(chan solver.Result)) {
 	s := solver.New(pb)
 	if verbose {
 		fmt.Printf("c ======================================================================================\n")
@@ -120,6 +184,7 @@ func solve(pb *solver.Problem, verbose, cert bool, printFn func(chan solver.Resu
 		s.Verbose = true
 	}
 	s.Certified = cert
+	s.Strategy = strat
 	results := make(chan solver.Result)
 	go s.Optimal(results, nil)
 	printFn(results)
@@ -130,68 +195,227 @@ func solve(pb *solver.Problem, verbose, cert bool, printFn func(chan solver.Resu
 	}
 }
 
-func parseAndSolveWCNF(path string, verbose bool) error {
-	f, err := os.Open(path)
+func parseAndSolveWCNF(r io.Reader, verbose, count bool, algoName string, localSearch bool) error {
+	algo, err := maxsat.ParseAlgorithm(algoName)
 	if err != nil {
-		return fmt.Errorf("could not open %q: %v", path, err)
+		return err
 	}
-	defer f.Close()
-	s, err := maxsat.ParseWCNF(f)
+	s, err := maxsat.ParseWCNF(r)
 	if err != nil {
 		return fmt.Errorf("could not parse wcnf content: %v", err)
 	}
+	s.(*maxsat.Solver).SetAlgorithm(algo)
+	s.(*maxsat.Solver).SetLocalSearch(localSearch)
+	if count {
+		fmt.Println(s.Enumerate(nil, nil))
+		return nil
+	}
 	results := make(chan solver.Result)
 	go s.Optimal(results, nil)
-	printOptimizationResults(results)
+	printMaxSATResults(results)
 	return nil
 }
 
-func parseAndSolveBF(path string) error {
-	f, err := os.Open(path)
+// parseAndSolveWBO solves a WBO problem and prints the result in the competition format.
+// Auxiliary variables introduced by products of literals are not displayed.
+func parseAndSolveWBO(r io.Reader, verbose bool, algoName string, localSearch bool) error {
+	algo, err := maxsat.ParseAlgorithm(algoName)
 	if err != nil {
-		return fmt.Errorf("could not open %q: %v", path, err)
+		return err
 	}
-	defer f.Close()
-	form, err := bf.Parse(f)
+	pb, err := maxsat.ParseWBO(r)
 	if err != nil {
-		return fmt.Errorf("could not parse formula in %q: %v", path, err)
+		return err
 	}
-	solveBF(form)
+	pb.SetVerbose(verbose)
+	pb.SetAlgorithm(algo)
+	pb.SetLocalSearch(localSearch)
+	model, cost := pb.Solve()
+	if model == nil {
+		fmt.Println("s UNSATISFIABLE")
+		return nil
+	}
+	fmt.Printf("o %d\n", cost)
+	fmt.Println("s OPTIMUM FOUND")
+	fmt.Printf("v ")
+	for v := 1; v <= pb.NbWBOVars(); v++ { // Eliminated vars are missing from the model, and can have any value
+		if model[fmt.Sprintf("x%d", v)] {
+			fmt.Printf("x%d ", v)
+		} else {
+			fmt.Printf("-x%d ", v)
+		}
+	}
+	fmt.Println()
 	return nil
 }
 
-func parse(path string) (pb *solver.Problem, printFn func(chan solver.Result), err error) {
-	f, err := os.Open(path)
+// parseAndSolveAIGER checks whether a target of an AIG can be true, and prints the result in the AIGER witness format.
+// If depth is 0, latches are free: if no target can be true, the property holds, else the bad state that was found
+// is not necessarily reachable from the initial state, so it is only displayed in comments, and the result is unknown.
+// Else, the AIG is checked through bounded model checking, up to depth transitions.
+func parseAndSolveAIGER(r io.Reader, verbose bool, depth int) error {
+	aig, err := aiger.Parse(r)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not open %q: %v", path, err)
+		return err
 	}
-	defer f.Close()
-	if strings.HasSuffix(path, ".bf") {
-		_, err := bf.Parse(f)
-		if err != nil {
-			return nil, nil, fmt.Errorf("could not parse %q: %v", path, err)
+	if depth > 0 {
+		return checkAIGER(aig, verbose, depth)
+	}
+	cnf := aig.CNF()
+	s := solver.New(cnf.Problem)
+	s.Verbose = verbose
+	if s.Solve() != solver.Sat {
+		fmt.Printf("0\nb0\n.\n")
+		return nil
+	}
+	inputs, latches := cnf.Inputs(s.Model()), cnf.Latches(s.Model())
+	fmt.Printf("c property %d fails in a state that may not be reachable\n", satisfiedTarget(aig, inputs, latches))
+	fmt.Printf("c latches %s\nc inputs %s\n", bits(latches), bits(inputs))
+	fmt.Printf("2\nb0\n.\n")
+	return nil
+}
+
+// checkAIGER looks for a counterexample of at most depth transitions of aig, and prints it as an AIGER witness:
+// the satisfied property, the initial values of the latches and the values of the inputs at each step.
+// If there is none, the result is unknown.
+func checkAIGER(aig *aiger.AIG, verbose bool, depth int) error {
+	sys, err := bmc.FromAIG(aig)
+	if err != nil {
+		return err
+	}
+	c := bmc.NewChecker(sys)
+	for c.Bound() <= depth {
+		if verbose {
+			fmt.Printf("c checking bound %d\n", c.Bound())
+		}
+		trace := c.Step()
+		if trace == nil {
+			continue
+		}
+		inputs, latches := aig.Assignment(trace[len(trace)-1])
+		_, init := aig.Assignment(trace[0])
+		fmt.Printf("1\nb%d\n%s\n", satisfiedTarget(aig, inputs, latches), bits(init))
+		for _, state := range trace {
+			stepInputs, _ := aig.Assignment(state)
+			fmt.Printf("%s\n", bits(stepInputs))
 		}
-		panic("not yet implemented")
+		fmt.Printf(".\n")
+		return nil
 	}
-	if strings.HasSuffix(path, ".cnf") {
-		pb, err := solver.ParseCNF(f)
+	fmt.Printf("2\nb0\n.\n")
+	return nil
+}
+
+// satisfiedTarget returns the index of the first target of aig that is true, given the values of its inputs and latches.
+func satisfiedTarget(aig *aiger.AIG, inputs, latches []bool) int {
+	vals := aig.Eval(inputs, latches)
+	for i, lit := range aig.Targets() {
+		if aiger.LitValue(vals, lit) {
+			return i
+		}
+	}
+	return 0
+}
+
+// bits returns the given values as a string of 0s and 1s.
+func bits(vals []bool) string {
+	res := make([]byte, len(vals))
+	for i, val := range vals {
+		res[i] = '0'
+		if val {
+			res[i] = '1'
+		}
+	}
+	return string(res)
+}
+
+func parseAndSolveBF(r io.Reader, count bool, conv bf.Conversion) error {
+	form, err := bf.Parse(r)
+	if err != nil {
+		return err
+	}
+	if count {
+		fmt.Println(bf.Count(form))
+		return nil
+	}
+	solveBF(form, conv)
+	return nil
+}
+
+// solveICNF solves each query of the given incremental problem with a single solver, and prints its result
+// in the competition format as soon as it is known.
+// When a query is unsatisfiable because of its assumptions, the failed assumptions are displayed as a comment.
+func solveICNF(r io.Reader, verbose bool) error {
+	ir := solver.NewICNFReader(r)
+	for nb := 1; ; nb++ {
+		assumps, err := ir.Next()
+		if err == io.EOF {
+			return nil
+		}
 		if err != nil {
-			return nil, nil, fmt.Errorf("could not parse DIMACS file %q: %v", path, err)
+			return err
+		}
+		s := ir.Solver()
+		start := time.Now()
+		status := s.SolveAssumptions(assumps)
+		if verbose {
+			fmt.Printf("c query %d solved in %v\n", nb, time.Since(start))
+		}
+		res := solver.Result{Status: status}
+		if status == solver.Sat {
+			res.Model = s.Model()
+		}
+		results := make(chan solver.Result, 1)
+		results <- res
+		close(results)
+		printDecisionResults(results)
+		if failed := s.FailedAssumptions(); status == solver.Unsat && len(failed) != 0 {
+			lits := make([]string, len(failed))
+			for i, lit := range failed {
+				lits[i] = strconv.Itoa(int(lit.Int()))
+			}
+			fmt.Printf("c failed assumptions: %s\n", strings.Join(lits, " "))
 		}
-		return pb, printDecisionResults, nil
 	}
-	if strings.HasSuffix(path, ".opb") {
-		pb, err := solver.ParseOPB(f)
+}
+
+// parseAndSolveQBF solves a QBF and prints the result in the QDIMACS output format.
+// When the formula is true, the winning assignment of the outermost existential block is displayed.
+func parseAndSolveQBF(r io.Reader) error {
+	pb, err := qbf.ParseQDIMACS(r)
+	if err != nil {
+		return err
+	}
+	status, move := pb.Solve()
+	res := 0
+	if status == solver.Sat {
+		res = 1
+	}
+	fmt.Printf("s cnf %d %d %d\n", res, pb.NbVars, len(pb.Clauses))
+	for _, lit := range move {
+		fmt.Printf("V %d 0\n", lit)
+	}
+	return nil
+}
+
+func parse(r io.Reader, format input.Format) (pb *solver.Problem, printFn func(chan solver.Result), err error) {
+	if format == input.OPB {
+		pb, err := solver.ParseOPB(r)
 		if err != nil {
-			return nil, nil, fmt.Errorf("could not parse OPB file %q: %v", path, err)
+			return nil, nil, fmt.Errorf("could not parse OPB content: %v", err)
 		}
-		return pb, printOptimizationResults, nil
+		printFn := func(results chan solver.Result) { printOptimizationResults(results, pb.ObjectiveValue) }
+		return pb, printFn, nil
 	}
-	return nil, nil, fmt.Errorf("invalid file format for %q", path)
+	pb, err = solver.ParseCNF(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse DIMACS content: %v", err)
+	}
+	return pb, printDecisionResults, nil
 }
 
-func solveBF(f bf.Formula) {
-	if model := bf.Solve(f); model == nil {
+func solveBF(f bf.Formula, conv bf.Conversion) {
+	if model := bf.SolveWith(f, bf.Options{Conversion: conv, Native: true}); model == nil {
 		fmt.Println("UNSATISFIABLE")
 	} else {
 		fmt.Println("SATISFIABLE")
@@ -211,7 +435,6 @@ func printDecisionResults(results chan solver.Result) {
 	var res solver.Result
 	for res = range results {
 	}
-	return
 	switch res.Status {
 	case solver.Unsat:
 		fmt.Println("s UNSATISFIABLE")
@@ -231,8 +454,9 @@ func printDecisionResults(results chan solver.Result) {
 	}
 }
 
-// prints the result to a PB optimization problem in the competition format.
-func printOptimizationResults(results chan solver.Result) {
+// printMaxSATResults prints results using the conventions of the MaxSAT Evaluation:
+// the model is displayed as a single string of 0s and 1s, the i'th character being the value of the i'th var.
+func printMaxSATResults(results chan solver.Result) {
 	var res solver.Result
 	for res = range results {
 		if res.Status == solver.Sat {
@@ -240,6 +464,49 @@ func printOptimizationResults(results chan solver.Result) {
 		}
 	}
 	switch res.Status {
+	case solver.Unsat:
+		fmt.Println("s UNSATISFIABLE")
+	case solver.Sat:
+		fmt.Println("s OPTIMUM FOUND")
+		model := make([]byte, len(res.Model))
+		for i, binding := range res.Model {
+			if binding {
+				model[i] = '1'
+			} else {
+				model[i] = '0'
+			}
+		}
+		fmt.Printf("v %s\n", model)
+	default:
+		fmt.Println("s UNKNOWN")
+	}
+}
+
+// printOptimizationResults prints the results of a PB optimization problem in the competition format.
+// value returns the value of the i'th objective, as written in the problem, given the associated cost.
+func printOptimizationResults(results chan solver.Result, value func(i, cost int) int) {
+	var res solver.Result
+	for res = range results {
+		if res.Status == solver.Sat {
+			if len(res.Weights) > 1 { // Several cost functions: display all costs
+				vals := make([]int, len(res.Weights))
+				for i, w := range res.Weights {
+					vals[i] = value(i, w)
+				}
+				fmt.Printf("o %s\n", strings.Trim(fmt.Sprint(vals), "[]"))
+			} else {
+				fmt.Printf("o %d\n", value(0, res.Weight))
+			}
+			if res.LowerBound > 0 && res.LowerBound < res.Weight {
+				if bound := value(0, res.LowerBound); bound > value(0, res.Weight) { // Maximization problem
+					fmt.Printf("c upper bound %d\n", bound)
+				} else {
+					fmt.Printf("c lower bound %d\n", bound)
+				}
+			}
+		}
+	}
+	switch res.Status {
 	case solver.Unsat:
 		fmt.Println("s UNSATISFIABLE")
 	case solver.Sat:
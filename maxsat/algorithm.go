@@ -0,0 +1,87 @@
+package maxsat
+
+import (
+	"fmt"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// An Algorithm is a strategy used to find an optimal solution to a MAXSAT problem.
+type Algorithm int
+
+const (
+	// LinearSearch is a SAT-UNSAT search: each time a model is found, a constraint is added
+	// so that the next model must be strictly better, until the problem becomes UNSAT.
+	// It provides good solutions quickly, but proving optimality can be very long when there are many soft clauses.
+	LinearSearch Algorithm = iota
+	// OLL is a core-guided, UNSAT-SAT search (also known as RC2).
+	// Soft clauses are assumed to be satisfied, and each time this is not possible, the unsatisfiable core
+	// is relaxed through a totalizer, so that only one of its clauses is allowed to be falsified.
+	// It is usually much more efficient than LinearSearch on problems with many soft clauses.
+	OLL
+	// IHS is an implicit hitting set algorithm (also known as MaxHS).
+	// Unsatisfiable cores are extracted by the SAT solver, and a minimum-cost hitting set of these cores is computed
+	// through branch-and-bound to get a lower bound and to choose which soft clauses to relax.
+	// It is often efficient on weighted problems where weights are very diverse.
+	IHS
+)
+
+// String returns the name of the algorithm.
+func (algo Algorithm) String() string {
+	switch algo {
+	case LinearSearch:
+		return "linear"
+	case OLL:
+		return "oll"
+	case IHS:
+		return "ihs"
+	default:
+		return fmt.Sprintf("Algorithm(%d)", int(algo))
+	}
+}
+
+// ParseAlgorithm returns the algorithm whose name is given, as returned by Algorithm.String.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for _, algo := range []Algorithm{LinearSearch, OLL, IHS} {
+		if algo.String() == name {
+			return algo, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown MAXSAT algorithm %q", name)
+}
+
+// A solveFunc looks for the optimal solution to the problem in s, whose cost function is given by lits and weights.
+// If weights is nil, all weights are 1.
+// Every time a better solution is found, inc is updated. Solutions found concurrently by other searches
+// are taken into account through inc too.
+// The returned result is the optimal one, unless the search was stopped, in which case it is the best one found so far.
+type solveFunc func(s *solver.Solver, lits []solver.Lit, weights []int, inc *incumbent, stop chan struct{}) solver.Result
+
+// solveFunc returns the function implementing the algorithm,
+// or nil for LinearSearch, which is directly implemented by the solver package.
+func (algo Algorithm) solveFunc() solveFunc {
+	switch algo {
+	case OLL:
+		return solveOLL
+	case IHS:
+		return solveIHS
+	default:
+		return nil
+	}
+}
+
+// modelCost returns the cost of the given model, i.e the sum of the weights of the lits that are true.
+// If weights is nil, all weights are 1.
+func modelCost(model []bool, lits []solver.Lit, weights []int) int {
+	cost := 0
+	for i, lit := range lits {
+		if model[lit.Var()] == lit.IsPositive() {
+			if weights == nil {
+				cost++
+			} else {
+				cost += weights[i]
+			}
+		}
+	}
+	return cost
+}
@@ -34,6 +34,9 @@ type Constr struct {
 	Coeffs  []int // The coefficients associated with each literals. If nil, all coeffs are supposed to be 1.
 	AtLeast int   // Minimal cardinality for the constr to be satisfied.
 	Weight  int   // The weight of the clause, or 0 for a hard clause.
+	// For problems with several objectives, the index of the objective the constraint's weight counts in.
+	// Objectives are ranked by priority: 0 is the most important one.
+	Objective int
 }
 
 // HardClause returns a propositional clause that must be satisfied.
@@ -1,7 +1,7 @@
 // Package maxsat provides an optimization solver for SAT/PB.
 // It allows the user to provide weighted partial MAXSAT problems or weighted pseudo-booleans problems.
 //
-// Definition
+// # Definition
 //
 // A MAXSAT problem is a problem where, contrary to "plain-old" SAT decision problems,
 // the user is not looking at whether the problem can be solved at all, but, if it cannot be solved,
@@ -0,0 +1,136 @@
+package maxsat
+
+import (
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// Enumerate returns the number of optimal models for the underlying problem, i.e models with a minimal cost.
+// Relax vars are not part of the models, so two models that only differ by the value of their relax vars
+// are considered as a single one.
+// If models is not nil, each optimal model is written to it as soon as it is found.
+// If data is sent to stop, the method stops prematurely and returns the number of models found so far.
+// In any case, models is closed before the function returns.
+// Once this method was called, the solver cannot be used anymore.
+func (s *Solver) Enumerate(models chan []bool, stop chan struct{}) int {
+	if models == nil {
+		return s.enumerate(0, true, nil, stop)
+	}
+	defer close(models)
+	results := make(chan solver.Result)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for res := range results {
+			models <- res.Model
+		}
+	}()
+	nb := s.enumerate(0, true, results, stop)
+	<-done
+	return nb
+}
+
+// EnumerateBest enumerates the k best models for the underlying problem, by increasing cost.
+// If k <= 0, all models are enumerated.
+// As with Enumerate, relax vars are not part of the models.
+// If results is not nil, each model is written to it, along with its cost, as soon as it is found.
+// If data is sent to stop, the method stops prematurely.
+// In any case, results is closed before the function returns.
+// It returns the number of models that were found.
+// Once this method was called, the solver cannot be used anymore.
+func (s *Solver) EnumerateBest(k int, results chan solver.Result, stop chan struct{}) int {
+	return s.enumerate(k, false, results, stop)
+}
+
+// enumerate enumerates models by increasing cost, until k models were found (if k > 0),
+// until all optimal models were found (if optimalOnly) or until all models were found.
+// Each model is written to results, if it is not nil, and results is closed at the end of the call.
+// It returns the number of models that were found.
+// Models of a given cost are enumerated under an assumption bounding the cost,
+// and each of them is then blocked by a clause on the problem's vars.
+func (s *Solver) enumerate(k int, optimalOnly bool, results chan solver.Result, stop chan struct{}) int {
+	if results != nil {
+		defer close(results)
+	}
+	nb := 0
+	for !stopped(stop) && s.solver.Solve() == solver.Sat {
+		// Look for the cheapest model among the ones that were not enumerated yet
+		model := s.solver.Model()
+		cost := modelCost(model, s.relaxLits, s.weights)
+		for cost > 0 && !stopped(stop) {
+			sel := s.bound(cost - 1)
+			status := s.solver.SolveAssumptions([]solver.Lit{sel})
+			s.solver.Disable(sel)
+			if status != solver.Sat {
+				break
+			}
+			model = s.solver.Model()
+			cost = modelCost(model, s.relaxLits, s.weights)
+		}
+		if stopped(stop) {
+			break
+		}
+		// Enumerate all models with that cost
+		sel := s.bound(cost)
+		for {
+			nb++
+			if results != nil {
+				results <- solver.Result{Status: solver.Sat, Model: model[:s.firstRelax], Weight: cost}
+			}
+			if k > 0 && nb == k {
+				return nb
+			}
+			s.block(model)
+			if stopped(stop) || s.solver.SolveAssumptions([]solver.Lit{sel}) != solver.Sat {
+				break
+			}
+			model = s.solver.Model()
+		}
+		s.solver.Disable(sel)
+		if optimalOnly {
+			break
+		}
+	}
+	return nb
+}
+
+// bound returns a new selector lit that, when assumed, forbids models whose cost is greater than ub.
+func (s *Solver) bound(ub int) solver.Lit {
+	sel := s.solver.NewVar().Lit()
+	maxCost := 0
+	for _, w := range s.weights {
+		maxCost += w
+	}
+	card := maxCost - ub
+	if card < 1 { // Bound is trivially respected
+		return sel
+	}
+	lits := make([]solver.Lit, len(s.relaxLits)+1)
+	weights := make([]int, len(s.relaxLits)+1)
+	for i, lit := range s.relaxLits {
+		lits[i] = lit.Negation()
+		weights[i] = s.weights[i]
+	}
+	lits[len(s.relaxLits)] = sel.Negation()
+	weights[len(s.relaxLits)] = card
+	s.solver.AppendClause(solver.NewPBClause(lits, weights, card))
+	return sel
+}
+
+// block adds a clause forbidding the given model, as far as the problem's vars are concerned.
+func (s *Solver) block(model []bool) {
+	lits := make([]solver.Lit, s.firstRelax)
+	for i := range lits {
+		lits[i] = solver.Var(i).SignedLit(model[i])
+	}
+	s.solver.AppendClause(solver.NewClause(lits))
+}
+
+// stopped returns true iff something was sent on stop, or it was closed.
+func stopped(stop chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,296 @@
+package maxsat
+
+import "sort"
+
+// A hittingSet is a minimum-cost hitting set problem: given a list of sets (the cores) of weighted elements,
+// find a subset of elements of minimal cost that contains at least one element from each core.
+// Elements are identified by their index.
+type hittingSet struct {
+	weights []int   // Weight of each element
+	cores   [][]int // Sets that must be hit
+	fixed   []bool  // Elements that cannot be part of a hitting set
+}
+
+// newHittingSet returns an empty hitting set problem on elements with the given weights.
+func newHittingSet(weights []int) *hittingSet {
+	return &hittingSet{weights: weights, fixed: make([]bool, len(weights))}
+}
+
+// cost returns the total weight of the given elements.
+func (hs *hittingSet) cost(elems []int) int {
+	res := 0
+	for _, e := range elems {
+		res += hs.weights[e]
+	}
+	return res
+}
+
+// greedy returns a hitting set that is not necessarily optimal, by repeatedly choosing the element
+// that hits the most remaining cores per unit of weight. Useless elements are then removed.
+// If no hitting set exists because all elements of a core are fixed, ok is false.
+func (hs *hittingSet) greedy() (elems []int, ok bool) {
+	hit := make([]bool, len(hs.cores))
+	nbHits := make([]int, len(hs.weights))
+	for {
+		for i := range nbHits {
+			nbHits[i] = 0
+		}
+		done := true
+		for i, core := range hs.cores {
+			if hit[i] {
+				continue
+			}
+			done = false
+			for _, e := range core {
+				if !hs.fixed[e] {
+					nbHits[e]++
+				}
+			}
+		}
+		if done {
+			break
+		}
+		best := -1
+		for e, nb := range nbHits {
+			// nb/w > nbBest/wBest
+			if nb > 0 && (best == -1 || nb*hs.weights[best] > nbHits[best]*hs.weights[e]) {
+				best = e
+			}
+		}
+		if best == -1 {
+			return nil, false
+		}
+		elems = append(elems, best)
+		for i, core := range hs.cores {
+			if !hit[i] && contains(core, best) {
+				hit[i] = true
+			}
+		}
+	}
+	return hs.removeUseless(elems), true
+}
+
+// removeUseless removes, starting from the last one, the elements that are not needed to hit all cores.
+func (hs *hittingSet) removeUseless(elems []int) []int {
+	nbHits := make([]int, len(hs.cores)) // How many times each core is hit by elems
+	for i, core := range hs.cores {
+		for _, e := range elems {
+			if contains(core, e) {
+				nbHits[i]++
+			}
+		}
+	}
+	for i := len(elems) - 1; i >= 0; i-- {
+		useless := true
+		for j, core := range hs.cores {
+			if nbHits[j] == 1 && contains(core, elems[i]) {
+				useless = false
+				break
+			}
+		}
+		if useless {
+			for j, core := range hs.cores {
+				if contains(core, elems[i]) {
+					nbHits[j]--
+				}
+			}
+			elems = append(elems[:i], elems[i+1:]...)
+		}
+	}
+	return elems
+}
+
+// dual computes a feasible solution to the dual of the LP relaxation of the problem, greedily.
+// It returns the associated lower bound on the cost of any hitting set, and the reduced cost of each element:
+// any hitting set containing element e costs at least lb + reduced[e].
+func (hs *hittingSet) dual() (lb int, reduced []int) {
+	reduced = make([]int, len(hs.weights))
+	copy(reduced, hs.weights)
+	for _, core := range hs.cores {
+		min := -1
+		for _, e := range core {
+			if !hs.fixed[e] && (min == -1 || reduced[e] < min) {
+				min = reduced[e]
+			}
+		}
+		if min <= 0 {
+			continue
+		}
+		lb += min
+		for _, e := range core {
+			if !hs.fixed[e] {
+				reduced[e] -= min
+			}
+		}
+	}
+	return lb, reduced
+}
+
+// optimal returns a minimum-cost hitting set, found through branch-and-bound.
+// Only hitting sets whose cost is strictly lower than ub are considered: if there are none, ok is false.
+func (hs *hittingSet) optimal(ub int) (elems []int, ok bool) {
+	b := &bnb{
+		hs:       hs,
+		state:    make([]int8, len(hs.weights)),
+		residual: make([]int, len(hs.weights)),
+		bestCost: ub,
+	}
+	for e, fixed := range hs.fixed {
+		if fixed {
+			b.state[e] = hsOut
+		}
+	}
+	b.order = make([]int, len(hs.cores))
+	for i := range b.order {
+		b.order[i] = i
+	}
+	sort.SliceStable(b.order, func(i, j int) bool { return len(hs.cores[b.order[i]]) < len(hs.cores[b.order[j]]) })
+	if set, ok := hs.greedy(); ok && hs.cost(set) < ub { // Greedy solution is a good first upper bound
+		b.best = set
+		b.bestCost = hs.cost(set)
+		b.found = true
+	}
+	b.search()
+	return b.best, b.found
+}
+
+// Possible states of an element during branch-and-bound.
+const (
+	hsFree int8 = iota // Element was not decided yet
+	hsIn               // Element is part of the current hitting set
+	hsOut              // Element cannot be part of the current hitting set
+)
+
+// bnb holds the state of a branch-and-bound search for a minimum-cost hitting set.
+type bnb struct {
+	hs       *hittingSet
+	state    []int8 // State of each element
+	residual []int  // Scratch space used when computing lower bounds
+	cur      []int  // Current partial hitting set
+	curCost  int    // Cost of cur
+	best     []int  // Best hitting set found so far
+	bestCost int    // Cost of best, or upper bound if no hitting set was found yet
+	found    bool   // Was a hitting set found?
+	order    []int  // Indices of the cores, from the smallest to the biggest
+	fixed    []int  // Elements that were fixed through reduced costs, in order
+}
+
+// search explores all hitting sets that extend the current partial one.
+func (b *bnb) search() {
+	lb := b.lowerBound()
+	if b.curCost+lb >= b.bestCost {
+		return
+	}
+	// Reduced-cost fixing: elements that would make the cost too high cannot be part of the hitting set
+	nbFixed := 0
+	for e, st := range b.state {
+		if st == hsFree && b.curCost+lb+b.residual[e] >= b.bestCost {
+			b.state[e] = hsOut
+			b.fixed = append(b.fixed, e)
+			nbFixed++
+		}
+	}
+	defer func() {
+		for _, e := range b.fixed[len(b.fixed)-nbFixed:] {
+			b.state[e] = hsFree
+		}
+		b.fixed = b.fixed[:len(b.fixed)-nbFixed]
+	}()
+	var core []int
+	minFree := -1
+	for _, c := range b.hs.cores {
+		hit := false
+		nbFree := 0
+		for _, e := range c {
+			if b.state[e] == hsIn {
+				hit = true
+				break
+			}
+			if b.state[e] == hsFree {
+				nbFree++
+			}
+		}
+		if hit {
+			continue
+		}
+		if nbFree == 0 { // Core cannot be hit anymore
+			return
+		}
+		if minFree == -1 || nbFree < minFree {
+			core = c
+			minFree = nbFree
+		}
+	}
+	if core == nil { // All cores are hit
+		if b.curCost < b.bestCost {
+			b.best = make([]int, len(b.cur))
+			copy(b.best, b.cur)
+			b.bestCost = b.curCost
+			b.found = true
+		}
+		return
+	}
+	cands := make([]int, 0, minFree)
+	for _, e := range core {
+		if b.state[e] == hsFree {
+			cands = append(cands, e)
+		}
+	}
+	sort.Slice(cands, func(i, j int) bool { return b.hs.weights[cands[i]] < b.hs.weights[cands[j]] })
+	for _, e := range cands {
+		b.state[e] = hsIn
+		b.cur = append(b.cur, e)
+		b.curCost += b.hs.weights[e]
+		b.search()
+		b.curCost -= b.hs.weights[e]
+		b.cur = b.cur[:len(b.cur)-1]
+		b.state[e] = hsOut // Next branches must not consider e, they were already explored
+	}
+	for _, e := range cands {
+		b.state[e] = hsFree
+	}
+}
+
+// lowerBound returns a lower bound on the cost needed to hit all cores that are not hit yet,
+// using a greedy dual solution restricted to free elements.
+// Once the function returns, b.residual contains the reduced cost of each free element,
+// i.e adding element e to the hitting set costs at least lb + b.residual[e].
+func (b *bnb) lowerBound() int {
+	for e := range b.residual {
+		b.residual[e] = b.hs.weights[e]
+	}
+	lb := 0
+	for _, i := range b.order {
+		core := b.hs.cores[i]
+		min := -1
+		for _, e := range core {
+			if b.state[e] == hsIn {
+				min = -1
+				break
+			}
+			if b.state[e] == hsFree && (min == -1 || b.residual[e] < min) {
+				min = b.residual[e]
+			}
+		}
+		if min <= 0 {
+			continue
+		}
+		lb += min
+		for _, e := range core {
+			if b.state[e] == hsFree {
+				b.residual[e] -= min
+			}
+		}
+	}
+	return lb
+}
+
+// contains returns true iff elems contains e.
+func contains(elems []int, e int) bool {
+	for _, e2 := range elems {
+		if e2 == e {
+			return true
+		}
+	}
+	return false
+}
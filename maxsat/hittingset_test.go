@@ -0,0 +1,78 @@
+package maxsat
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// bruteForceHittingSet returns the cost of a minimum-cost hitting set, by enumerating all subsets of elements.
+func bruteForceHittingSet(hs *hittingSet) int {
+	best := -1
+	for mask := 0; mask < 1<<uint(len(hs.weights)); mask++ {
+		ok := true
+		for _, core := range hs.cores {
+			hit := false
+			for _, e := range core {
+				if mask&(1<<uint(e)) != 0 {
+					hit = true
+					break
+				}
+			}
+			if !hit {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		cost := 0
+		for e, w := range hs.weights {
+			if mask&(1<<uint(e)) != 0 {
+				cost += w
+			}
+		}
+		if best == -1 || cost < best {
+			best = cost
+		}
+	}
+	return best
+}
+
+func TestHittingSet(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		nbElems := 1 + r.Intn(12)
+		weights := make([]int, nbElems)
+		for e := range weights {
+			weights[e] = 1 + r.Intn(20)
+		}
+		hs := newHittingSet(weights)
+		nbCores := r.Intn(15)
+		for j := 0; j < nbCores; j++ {
+			perm := r.Perm(nbElems)
+			hs.cores = append(hs.cores, perm[:1+r.Intn(nbElems)])
+		}
+		expected := bruteForceHittingSet(hs)
+		greedy, ok := hs.greedy()
+		if !ok {
+			t.Fatalf("could not find greedy hitting set")
+		}
+		if cost := hs.cost(greedy); cost < expected {
+			t.Errorf("greedy hitting set %v has cost %d, lower than optimal cost %d", greedy, cost, expected)
+		}
+		lb, _ := hs.dual()
+		if lb > expected {
+			t.Errorf("dual lower bound %d is greater than optimal cost %d", lb, expected)
+		}
+		if _, ok := hs.optimal(expected); ok {
+			t.Errorf("found hitting set with cost lower than optimal cost %d", expected)
+		}
+		set, ok := hs.optimal(expected + 1)
+		if !ok {
+			t.Errorf("could not find optimal hitting set of cost %d", expected)
+		} else if cost := hs.cost(set); cost != expected {
+			t.Errorf("invalid optimal hitting set %v: expected cost %d, got %d", set, expected, cost)
+		}
+	}
+}
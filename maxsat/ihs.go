@@ -0,0 +1,187 @@
+package maxsat
+
+import (
+	"fmt"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// ihs holds the state of the implicit hitting set algorithm.
+// Soft constraint i is represented by the assumption assumps[i], i.e the negation of its blocking literal.
+type ihs struct {
+	s        *solver.Solver
+	nbVars   int                // Number of vars in the original problem
+	costLits []solver.Lit       // Blocking lits of the original problem
+	costWs   []int              // Weight of each blocking lit
+	assumps  []solver.Lit       // For each soft constraint, the lit that is ideally true
+	idx      map[solver.Lit]int // For each assumption, the index of its soft constraint
+	hs       *hittingSet        // The cores found so far, as sets of soft constraint indices
+	inc      *incumbent         // Best solution found so far
+}
+
+// solveIHS is the solveFunc for the implicit hitting set algorithm.
+// Cores are extracted by the SAT solver and a minimum-cost hitting set of all cores found so far is computed,
+// yielding a lower bound. The solver is then called again, assuming all soft constraints that are not part of the
+// hitting set, until either a model is found (it is then optimal) or a new core is extracted.
+// Hitting sets are first computed greedily, and optimal ones are only computed when greedy ones do not yield new cores.
+func solveIHS(s *solver.Solver, lits []solver.Lit, weights []int, inc *incumbent, stop chan struct{}) solver.Result {
+	h := &ihs{
+		s:        s,
+		nbVars:   s.NbVars(),
+		costLits: lits,
+		costWs:   weights,
+		idx:      make(map[solver.Lit]int),
+		inc:      inc,
+	}
+	var ws []int
+	for i, lit := range lits {
+		w := 1
+		if weights != nil {
+			w = weights[i]
+		}
+		if w <= 0 {
+			continue
+		}
+		lit = lit.Negation()
+		if j, ok := h.idx[lit]; ok { // Duplicate blocking lit: merge weights
+			ws[j] += w
+			continue
+		}
+		h.idx[lit] = len(h.assumps)
+		h.assumps = append(h.assumps, lit)
+		ws = append(ws, w)
+	}
+	h.hs = newHittingSet(ws)
+	if s.SolveAssumptions(nil) != solver.Sat {
+		inc.setUnsat()
+		return inc.result()
+	}
+	h.updateBest(s.Model())
+	if nbCores, ok := h.extractCores(h.assumptions(nil)); !ok || nbCores == 0 {
+		return inc.result()
+	}
+	optimal := false // Should the next hitting set be optimal rather than greedy?
+	for {
+		if stopped(stop) {
+			return inc.result()
+		}
+		if lb := h.fixReducedCosts(); lb >= inc.cost() {
+			return inc.result()
+		}
+		var (
+			set []int
+			ok  bool
+		)
+		if optimal {
+			set, ok = h.hs.optimal(inc.cost())
+		} else {
+			set, ok = h.hs.greedy()
+		}
+		if !ok { // No solution better than the current one
+			return inc.result()
+		}
+		if optimal && s.Verbose {
+			fmt.Printf("c lower bound %d\n", h.hs.cost(set))
+		}
+		nbCores, ok := h.extractCores(h.assumptions(set))
+		if !ok {
+			return inc.result()
+		}
+		if nbCores == 0 {
+			if optimal { // The model's cost is at most the cost of the hitting set, which is a lower bound
+				return inc.result()
+			}
+			optimal = true
+		} else {
+			optimal = false
+		}
+	}
+}
+
+// extractCores calls the solver, assuming the given lits. As long as the problem is UNSAT,
+// the core is added to the hitting set problem and its soft constraints are removed from the assumptions.
+// When the problem becomes SAT, the best solution is updated.
+// It returns the number of cores that were found, and false if the problem is UNSAT even without any
+// assumption, i.e the current best solution is optimal.
+func (h *ihs) extractCores(assumps []solver.Lit) (nbCores int, ok bool) {
+	for h.s.SolveAssumptions(assumps) != solver.Sat {
+		core := h.s.FailedAssumptions()
+		if len(core) == 0 {
+			return nbCores, false
+		}
+		core = trimCore(h.s, core)
+		h.addCore(core)
+		nbCores++
+		kept := assumps[:0]
+		for _, lit := range assumps {
+			if !containsLit(core, lit) {
+				kept = append(kept, lit)
+			}
+		}
+		assumps = kept
+	}
+	h.updateBest(h.s.Model())
+	return nbCores, true
+}
+
+// assumptions returns the assumptions for all soft constraints, except the ones in set and the ones that were fixed.
+func (h *ihs) assumptions(set []int) []solver.Lit {
+	inSet := make([]bool, len(h.assumps))
+	for _, e := range set {
+		inSet[e] = true
+	}
+	var assumps []solver.Lit
+	for i, lit := range h.assumps {
+		if !inSet[i] && !h.hs.fixed[i] {
+			assumps = append(assumps, lit)
+		}
+	}
+	return assumps
+}
+
+// addCore adds the given core, expressed as a list of assumptions, to the hitting set problem.
+func (h *ihs) addCore(core []solver.Lit) {
+	elems := make([]int, len(core))
+	for i, lit := range core {
+		elems[i] = h.idx[lit]
+	}
+	h.hs.cores = append(h.hs.cores, elems)
+}
+
+// fixReducedCosts hardens all soft constraints whose reduced cost proves that any solution
+// falsifying them cannot be better than the best solution found so far.
+// It returns the lower bound associated with the reduced costs.
+func (h *ihs) fixReducedCosts() (lb int) {
+	lb, reduced := h.hs.dual()
+	ub := h.inc.cost()
+	nbFixed := 0
+	for i, lit := range h.assumps {
+		if !h.hs.fixed[i] && lb+reduced[i] >= ub {
+			h.hs.fixed[i] = true
+			h.s.AppendClause(solver.NewClause([]solver.Lit{lit}))
+			nbFixed++
+		}
+	}
+	if h.s.Verbose && nbFixed > 0 {
+		fmt.Printf("c %d soft constraint(s) hardened through reduced-cost fixing\n", nbFixed)
+	}
+	return lb
+}
+
+// updateBest computes the cost of the given model, and updates the best solution if it is better.
+func (h *ihs) updateBest(model []bool) {
+	cost := modelCost(model, h.costLits, h.costWs)
+	if h.inc.update(model[:h.nbVars], cost) && h.s.Verbose {
+		fmt.Printf("c upper bound %d\n", cost)
+	}
+}
+
+// containsLit returns true iff lits contains lit.
+func containsLit(lits []solver.Lit, lit solver.Lit) bool {
+	for _, l := range lits {
+		if l == lit {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,335 @@
+package maxsat
+
+import (
+	"math/rand"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// Parameters of the local search.
+const (
+	lsNbSamples   = 15      // Number of candidate vars sampled before each flip
+	lsSoftLimit   = 1000    // Maximal dynamic weight of the heaviest soft constraint
+	lsCheckPeriod = 1024    // Number of flips between two checks of the incumbent and of the done channel
+	lsMaxNoImprov = 1000000 // Number of flips without improvement before restarting from the incumbent
+)
+
+// A lsConstr is a constraint, as seen by the local search: the sum of the coeffs of the true lits
+// must be at least atLeast. If weight is not 0, the constraint is soft, and blockLit is the
+// literal that must be true in the exact solver when the constraint is falsified.
+type lsConstr struct {
+	lits     []solver.Lit
+	coeffs   []int // If nil, all coeffs are 1
+	atLeast  int
+	weight   int
+	blockLit solver.Lit
+}
+
+// newLSClause returns the clause made of the given lits, in the DIMACS format.
+// If weight is not 0, the clause is soft, and blockLit is its blocking literal.
+func newLSClause(lits []int, weight, blockLit int) lsConstr {
+	c := lsConstr{lits: make([]solver.Lit, len(lits)), atLeast: 1, weight: weight}
+	for i, lit := range lits {
+		c.lits[i] = solver.IntToLit(int32(lit))
+	}
+	if weight != 0 {
+		c.blockLit = solver.IntToLit(int32(blockLit))
+	}
+	return c
+}
+
+// coeff returns the coefficient of the i'th lit of c.
+func (c *lsConstr) coeff(i int) int {
+	if c.coeffs == nil {
+		return 1
+	}
+	return c.coeffs[i]
+}
+
+// An occurrence is the appearance of a var in a constraint.
+type occurrence struct {
+	constr   int  // Index of the constraint
+	coeff    int  // Coefficient of the var's lit
+	positive bool // Is the lit positive?
+}
+
+// localSearch holds the state of a stochastic local search with dynamic constraint weighting, inspired by SATLike.
+// Each constraint has a dynamic weight, that is increased every time the search is stuck in a local optimum while
+// the constraint is falsified. Hard constraints' weights grow without limit, so that they end up being satisfied,
+// while soft constraints' weights are bounded by a value proportional to their actual weight.
+type localSearch struct {
+	nbVars   int
+	constrs  []lsConstr
+	occurs   [][]occurrence // For each var, the constraints it appears in
+	assign   []bool         // Current assignment
+	degrees  []int          // For each constraint, the sum of the coeffs of its true lits
+	dynWs    []int          // For each constraint, its dynamic weight
+	maxWs    []int          // For each soft constraint, the maximal value of its dynamic weight
+	unsat    []int          // Indices of the falsified constraints
+	unsatPos []int          // For each constraint, its position in unsat, or -1
+	nbHard   int            // Number of falsified hard constraints
+	cost     int            // Sum of the weights of the falsified soft constraints
+	lastFlip []int          // For each var, the step it was last flipped at
+	step     int
+	rand     *rand.Rand
+}
+
+// newLocalSearch returns a local search on the given constraints.
+// Models it provides have nbVars vars, or more if some constraints contain bigger vars.
+func newLocalSearch(nbVars int, constrs []lsConstr) *localSearch {
+	for _, c := range constrs {
+		for _, lit := range c.lits {
+			if int(lit.Var()) >= nbVars {
+				nbVars = int(lit.Var()) + 1
+			}
+		}
+		if c.weight != 0 && int(c.blockLit.Var()) >= nbVars {
+			nbVars = int(c.blockLit.Var()) + 1
+		}
+	}
+	ls := &localSearch{
+		nbVars:   nbVars,
+		constrs:  constrs,
+		occurs:   make([][]occurrence, nbVars),
+		assign:   make([]bool, nbVars),
+		degrees:  make([]int, len(constrs)),
+		dynWs:    make([]int, len(constrs)),
+		maxWs:    make([]int, len(constrs)),
+		unsatPos: make([]int, len(constrs)),
+		lastFlip: make([]int, nbVars),
+		rand:     rand.New(rand.NewSource(1)),
+	}
+	maxWeight := 0
+	for _, c := range constrs {
+		if c.weight > maxWeight {
+			maxWeight = c.weight
+		}
+	}
+	for i, c := range constrs {
+		for j, lit := range c.lits {
+			v := lit.Var()
+			ls.occurs[v] = append(ls.occurs[v], occurrence{constr: i, coeff: c.coeff(j), positive: lit.IsPositive()})
+		}
+		if c.weight != 0 {
+			ls.maxWs[i] = int(float64(c.weight) * lsSoftLimit / float64(maxWeight)) // Weights can be as large as 2^63-1
+			if ls.maxWs[i] < 1 {
+				ls.maxWs[i] = 1
+			}
+		}
+	}
+	ls.reset(nil)
+	return ls
+}
+
+// reset sets the current assignment to the given model, or to a random one if model is nil.
+func (ls *localSearch) reset(model []bool) {
+	for v := range ls.assign {
+		if model != nil {
+			ls.assign[v] = model[v]
+		} else {
+			ls.assign[v] = ls.rand.Intn(2) == 0
+		}
+	}
+	ls.unsat = ls.unsat[:0]
+	ls.nbHard = 0
+	ls.cost = 0
+	for i, c := range ls.constrs {
+		ls.degrees[i] = 0
+		for j, lit := range c.lits {
+			if ls.assign[lit.Var()] == lit.IsPositive() {
+				ls.degrees[i] += c.coeff(j)
+			}
+		}
+		ls.dynWs[i] = 1
+		ls.unsatPos[i] = -1
+		if ls.degrees[i] < c.atLeast {
+			ls.setUnsat(i)
+		}
+	}
+}
+
+// violation returns how far constraint i is from being satisfied, given its degree.
+func (ls *localSearch) violation(i, degree int) int {
+	if v := ls.constrs[i].atLeast - degree; v > 0 {
+		return v
+	}
+	return 0
+}
+
+// setUnsat marks constraint i as falsified.
+func (ls *localSearch) setUnsat(i int) {
+	ls.unsatPos[i] = len(ls.unsat)
+	ls.unsat = append(ls.unsat, i)
+	if w := ls.constrs[i].weight; w == 0 {
+		ls.nbHard++
+	} else {
+		ls.cost += w
+	}
+}
+
+// setSat marks constraint i as satisfied.
+func (ls *localSearch) setSat(i int) {
+	pos := ls.unsatPos[i]
+	last := ls.unsat[len(ls.unsat)-1]
+	ls.unsat[pos] = last
+	ls.unsatPos[last] = pos
+	ls.unsat = ls.unsat[:len(ls.unsat)-1]
+	ls.unsatPos[i] = -1
+	if w := ls.constrs[i].weight; w == 0 {
+		ls.nbHard--
+	} else {
+		ls.cost -= w
+	}
+}
+
+// score returns how much the weighted violation of the constraints would decrease if v was flipped.
+func (ls *localSearch) score(v int) int {
+	res := 0
+	for _, occ := range ls.occurs[v] {
+		deg := ls.degrees[occ.constr]
+		newDeg := deg + occ.coeff
+		if ls.assign[v] == occ.positive { // Lit will become false
+			newDeg = deg - occ.coeff
+		}
+		res += ls.dynWs[occ.constr] * (ls.violation(occ.constr, deg) - ls.violation(occ.constr, newDeg))
+	}
+	return res
+}
+
+// flip flips the value of v and updates the state of the constraints accordingly.
+func (ls *localSearch) flip(v int) {
+	for _, occ := range ls.occurs[v] {
+		i := occ.constr
+		if ls.assign[v] == occ.positive {
+			ls.degrees[i] -= occ.coeff
+		} else {
+			ls.degrees[i] += occ.coeff
+		}
+		unsat := ls.degrees[i] < ls.constrs[i].atLeast
+		if unsat && ls.unsatPos[i] == -1 {
+			ls.setUnsat(i)
+		} else if !unsat && ls.unsatPos[i] != -1 {
+			ls.setSat(i)
+		}
+	}
+	ls.assign[v] = !ls.assign[v]
+	ls.lastFlip[v] = ls.step
+}
+
+// falseVar returns a random var whose flip would make the given constraint closer to being satisfied.
+func (ls *localSearch) falseVar(c *lsConstr) int {
+	v := -1
+	nb := 0
+	for j, lit := range c.lits {
+		// Flipping the var makes the lit true if it has a positive coeff, or false if it has a negative one.
+		if (ls.assign[lit.Var()] == lit.IsPositive()) == (c.coeff(j) < 0) {
+			nb++
+			if ls.rand.Intn(nb) == 0 {
+				v = int(lit.Var())
+			}
+		}
+	}
+	return v
+}
+
+// pickVar returns the var to flip next, or -1 if the search is stuck in a local optimum.
+// It samples vars appearing in random falsified constraints, and chooses the best one among them.
+func (ls *localSearch) pickVar() int {
+	best := -1
+	bestScore := 0
+	for i := 0; i < lsNbSamples; i++ {
+		c := &ls.constrs[ls.unsat[ls.rand.Intn(len(ls.unsat))]]
+		v := ls.falseVar(c)
+		if v == -1 || v == best || ls.lastFlip[v] == ls.step-1 {
+			continue
+		}
+		if sc := ls.score(v); sc > bestScore || (sc == bestScore && best != -1 && ls.lastFlip[v] < ls.lastFlip[best]) {
+			best, bestScore = v, sc
+		}
+	}
+	return best
+}
+
+// escape is called when the search is stuck in a local optimum. Weights of falsified constraints are increased,
+// and a var from a falsified constraint, hard if possible, is chosen.
+func (ls *localSearch) escape() int {
+	for _, i := range ls.unsat {
+		if ls.constrs[i].weight == 0 || ls.dynWs[i] < ls.maxWs[i] {
+			ls.dynWs[i]++
+		}
+	}
+	idx := ls.unsat[ls.rand.Intn(len(ls.unsat))]
+	for i := 0; i < lsNbSamples && ls.nbHard > 0 && ls.constrs[idx].weight != 0; i++ {
+		idx = ls.unsat[ls.rand.Intn(len(ls.unsat))]
+	}
+	c := &ls.constrs[idx]
+	best := -1
+	bestScore := 0
+	for j, lit := range c.lits {
+		v := int(lit.Var())
+		if (ls.assign[v] == lit.IsPositive()) != (c.coeff(j) < 0) {
+			continue
+		}
+		if sc := ls.score(v); best == -1 || sc > bestScore || (sc == bestScore && ls.lastFlip[v] < ls.lastFlip[best]) {
+			best, bestScore = v, sc
+		}
+	}
+	return best
+}
+
+// model returns the current assignment as a model for the exact solver, i.e blocking lits are true
+// iff their constraint is falsified.
+func (ls *localSearch) model() []bool {
+	model := make([]bool, ls.nbVars)
+	copy(model, ls.assign)
+	for i, c := range ls.constrs {
+		if c.weight != 0 {
+			model[c.blockLit.Var()] = (ls.unsatPos[i] != -1) == c.blockLit.IsPositive()
+		}
+	}
+	return model
+}
+
+// run searches for better solutions until done is closed, updating inc every time one is found.
+// If the search does not make progress for a long time, it restarts from the best solution found so far,
+// which might have been found by another search.
+func (ls *localSearch) run(inc *incumbent, done chan struct{}) {
+	lastImprov := 0
+	for ls.step = 1; ; ls.step++ {
+		if ls.step%lsCheckPeriod == 0 {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if ls.step-lastImprov > lsMaxNoImprov {
+				if res := inc.result(); res.Status == solver.Sat {
+					ls.reset(res.Model)
+				} else if res.Status == solver.Unsat {
+					return
+				} else {
+					ls.reset(nil)
+				}
+				lastImprov = ls.step
+			}
+		}
+		if ls.nbHard == 0 {
+			if ub := inc.cost(); ub == -1 || ls.cost < ub {
+				if inc.update(ls.model(), ls.cost) {
+					lastImprov = ls.step
+				}
+			}
+			if ls.cost == 0 {
+				<-done
+				return
+			}
+		}
+		v := ls.pickVar()
+		if v == -1 {
+			v = ls.escape()
+		}
+		if v != -1 {
+			ls.flip(v)
+		}
+	}
+}
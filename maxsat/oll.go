@@ -0,0 +1,200 @@
+package maxsat
+
+import (
+	"fmt"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// maxTrims is the maximal number of times a core is trimmed before being relaxed.
+const maxTrims = 5
+
+// oll holds the state of the OLL algorithm.
+// Each soft constraint is represented by an assumption: either the negation of the blocking literal of
+// a soft constraint of the original problem, or the negation of an output of a totalizer
+// (i.e a literal meaning "at most k inputs of that totalizer are true").
+type oll struct {
+	s        *solver.Solver
+	nbVars   int                // Number of vars in the original problem: new vars are removed from models
+	costLits []solver.Lit       // Blocking lits of the original problem
+	costWs   []int              // Weight of each blocking lit
+	assumps  []solver.Lit       // For each soft constraint, the lit that is ideally true
+	weights  []int              // For each soft constraint, its remaining weight
+	sums     []*totalizer       // For each soft constraint, the totalizer it is an output of, or nil
+	bounds   []int              // For each soft constraint that is a totalizer output, the index of that output
+	idx      map[solver.Lit]int // For each assumption, the index of its soft constraint
+	lb       int                // Lower bound on the optimal cost
+	inc      *incumbent         // Best solution found so far
+}
+
+// solveOLL is the solveFunc for the OLL algorithm.
+func solveOLL(s *solver.Solver, lits []solver.Lit, weights []int, inc *incumbent, stop chan struct{}) solver.Result {
+	o := &oll{
+		s:        s,
+		nbVars:   s.NbVars(),
+		costLits: lits,
+		costWs:   weights,
+		idx:      make(map[solver.Lit]int),
+		inc:      inc,
+	}
+	for i, lit := range lits {
+		w := 1
+		if weights != nil {
+			w = weights[i]
+		}
+		if w > 0 {
+			o.addSoft(lit.Negation(), w, nil, 0)
+		}
+	}
+	thr := o.nextThreshold(-1)
+	for {
+		if stopped(stop) {
+			return inc.result()
+		}
+		assumps := o.assumptions(thr)
+		if s.SolveAssumptions(assumps) == solver.Sat {
+			o.updateBest(s.Model())
+			if inc.cost() == o.lb {
+				return inc.result()
+			}
+			if thr = o.nextThreshold(thr); thr == 0 {
+				// All soft constraints were satisfied: model is optimal, although the lb was not updated accordingly
+				return inc.result()
+			}
+			continue
+		}
+		core := s.FailedAssumptions()
+		if len(core) == 0 { // Hard constraints cannot be satisfied
+			inc.setUnsat()
+			return inc.result()
+		}
+		o.relax(trimCore(s, core))
+		if o.s.Verbose {
+			fmt.Printf("c lower bound %d\n", o.lb)
+		}
+		if inc.cost() == o.lb {
+			return inc.result()
+		}
+	}
+}
+
+// addSoft adds a new soft constraint, whose assumption is lit.
+// If sum is not nil, lit is the negation of the bound'th output of sum.
+func (o *oll) addSoft(lit solver.Lit, weight int, sum *totalizer, bound int) {
+	if i, ok := o.idx[lit]; ok { // Already known, typically a duplicate blocking lit: merge weights
+		o.weights[i] += weight
+		return
+	}
+	o.idx[lit] = len(o.assumps)
+	o.assumps = append(o.assumps, lit)
+	o.weights = append(o.weights, weight)
+	o.sums = append(o.sums, sum)
+	o.bounds = append(o.bounds, bound)
+}
+
+// nextThreshold returns the biggest weight of a soft constraint that is strictly lower than thr,
+// or 0 if there is none.
+// If thr is negative, the biggest weight is returned.
+// This allows stratification: soft constraints with a big weight are considered first.
+func (o *oll) nextThreshold(thr int) int {
+	res := 0
+	for _, w := range o.weights {
+		if w > res && (thr < 0 || w < thr) {
+			res = w
+		}
+	}
+	return res
+}
+
+// assumptions returns the list of assumptions for all soft constraints whose weight is at least thr.
+func (o *oll) assumptions(thr int) []solver.Lit {
+	var res []solver.Lit
+	for i, lit := range o.assumps {
+		if w := o.weights[i]; w > 0 && w >= thr {
+			res = append(res, lit)
+		}
+	}
+	return res
+}
+
+// updateBest computes the cost of the given model, and updates the best solution if it is better.
+func (o *oll) updateBest(model []bool) {
+	o.inc.update(model[:o.nbVars], modelCost(model, o.costLits, o.costWs))
+}
+
+// trimCore tries to reduce the size of the core by calling the solver on the core only,
+// until its size is stable.
+func trimCore(s *solver.Solver, core []solver.Lit) []solver.Lit {
+	for i := 0; i < maxTrims && len(core) > 1; i++ {
+		assumps := make([]solver.Lit, len(core))
+		copy(assumps, core)
+		if s.SolveAssumptions(assumps) == solver.Sat { // Should not happen
+			break
+		}
+		core2 := s.FailedAssumptions()
+		if len(core2) == 0 || len(core2) >= len(core) {
+			break
+		}
+		core = core2
+	}
+	return core
+}
+
+// relax relaxes the given core: its minimal weight is added to the lower bound and removed from
+// all its soft constraints, and a totalizer is created so that at most one of them can be falsified.
+func (o *oll) relax(core []solver.Lit) {
+	minW := -1
+	for _, lit := range core {
+		if w := o.weights[o.idx[lit]]; minW == -1 || w < minW {
+			minW = w
+		}
+	}
+	o.lb += minW
+	inputs := make([]solver.Lit, len(core))
+	for i, lit := range core {
+		idx := o.idx[lit]
+		o.weights[idx] -= minW
+		inputs[i] = lit.Negation()
+		if sum := o.sums[idx]; sum != nil { // At least bound+1 inputs of that sum will have to be true
+			o.extendSum(sum, o.bounds[idx]+1, minW)
+		}
+	}
+	if len(core) == 1 { // Soft constraint can never be satisfied
+		o.s.AppendClause(solver.NewClause(inputs))
+		return
+	}
+	sum := newTotalizer(o.s, inputs, 2)
+	o.s.AppendClause(solver.NewClause([]solver.Lit{sum.output(1)}))
+	o.exhaust(sum, minW)
+}
+
+// extendSum creates a new soft constraint meaning at most bound-1 inputs of sum are true.
+// If all inputs can be true, nothing is done.
+func (o *oll) extendSum(sum *totalizer, bound, weight int) {
+	if bound > sum.nbInputs {
+		return
+	}
+	sum.extend(o.s, bound)
+	o.addSoft(sum.output(bound).Negation(), weight, sum, bound)
+}
+
+// exhaust increases the bound of a new totalizer as long as the problem is provably UNSAT
+// when the number of true inputs is restricted to that bound.
+// The lower bound is updated accordingly, and a new soft constraint is created for the first possible bound.
+func (o *oll) exhaust(sum *totalizer, weight int) {
+	bound := 2
+	for ; bound <= sum.nbInputs; bound++ {
+		sum.extend(o.s, bound)
+		lit := sum.output(bound).Negation()
+		if o.s.SolveAssumptions([]solver.Lit{lit}) == solver.Sat {
+			o.updateBest(o.s.Model())
+			break
+		}
+		if len(o.s.FailedAssumptions()) == 0 { // Hard constraints cannot be satisfied
+			return
+		}
+		o.s.AppendClause(solver.NewClause([]solver.Lit{lit.Negation()}))
+		o.lb += weight
+	}
+	o.extendSum(sum, bound, weight)
+}
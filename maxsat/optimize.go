@@ -0,0 +1,151 @@
+package maxsat
+
+import (
+	"sync"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// An incumbent holds the best solution found so far.
+// It is safe for concurrent use, so that several searches running at the same time
+// (typically, an exact search and a local search) can share their upper bounds.
+type incumbent struct {
+	mu      sync.Mutex
+	best    solver.Result      // Best solution found so far
+	results chan solver.Result // If not nil, each new best solution is written to it
+	sendMu  sync.Mutex         // Held while writing to results, so that mu is not held then
+	sent    solver.Result      // Last solution written to results
+}
+
+// newIncumbent returns an incumbent with no solution yet.
+func newIncumbent(results chan solver.Result) *incumbent {
+	return &incumbent{best: solver.Result{Status: solver.Indet, Weight: -1}, results: results}
+}
+
+// update records the given model if its cost is lower than the best one so far, and returns true in that case.
+// The model is not copied, so it must not be modified afterwards.
+func (inc *incumbent) update(model []bool, cost int) bool {
+	inc.mu.Lock()
+	if inc.best.Status == solver.Sat && cost >= inc.best.Weight {
+		inc.mu.Unlock()
+		return false
+	}
+	inc.best = solver.Result{Status: solver.Sat, Model: model, Weight: cost}
+	best := inc.best
+	inc.mu.Unlock()
+	inc.send(best)
+	return true
+}
+
+// setUnsat records that the problem has no solution at all.
+func (inc *incumbent) setUnsat() {
+	inc.mu.Lock()
+	inc.best = solver.Result{Status: solver.Unsat}
+	best := inc.best
+	inc.mu.Unlock()
+	inc.send(best)
+}
+
+// send writes res to inc.results, if any, unless a better solution was written meanwhile.
+func (inc *incumbent) send(res solver.Result) {
+	if inc.results == nil {
+		return
+	}
+	inc.sendMu.Lock()
+	defer inc.sendMu.Unlock()
+	if res.Status == solver.Sat && inc.sent.Status == solver.Sat && res.Weight >= inc.sent.Weight {
+		return
+	}
+	inc.sent = res
+	inc.results <- res
+}
+
+// cost returns the cost of the best solution found so far, or -1 if no solution was found yet.
+func (inc *incumbent) cost() int {
+	inc.mu.Lock()
+	defer inc.mu.Unlock()
+	if inc.best.Status != solver.Sat {
+		return -1
+	}
+	return inc.best.Weight
+}
+
+// result returns the best solution found so far.
+func (inc *incumbent) result() solver.Result {
+	inc.mu.Lock()
+	defer inc.mu.Unlock()
+	return inc.best
+}
+
+// optimize looks for the optimal solution to the problem in s, whose cost function is given by lits and weights,
+// with the given algorithm.
+// If constrs is not nil, a local search is run on these constraints at the same time,
+// and both searches share the best solution they found.
+// If results is not nil, every new best solution is written to it, and it is closed at the end of the call.
+func optimize(s *solver.Solver, lits []solver.Lit, weights []int, algo Algorithm, constrs []lsConstr, results chan solver.Result, stop chan struct{}) solver.Result {
+	solve := algo.solveFunc()
+	if solve == nil && constrs == nil { // Plain linear search is directly handled by the solver
+		return s.Optimal(results, stop)
+	}
+	if results != nil {
+		defer close(results)
+	}
+	if solve == nil {
+		solve = solveLinear
+	}
+	inc := newIncumbent(results)
+	if constrs != nil {
+		ls := newLocalSearch(s.NbVars(), constrs)
+		done := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ls.run(inc, done)
+		}()
+		defer func() {
+			close(done)
+			wg.Wait()
+		}()
+	}
+	return solve(s, lits, weights, inc, stop)
+}
+
+// solveLinear is the solveFunc for the linear search.
+// Contrary to the linear search provided by the solver, the bound on the cost of the next solution
+// takes into account the solutions found by concurrent searches.
+func solveLinear(s *solver.Solver, lits []solver.Lit, weights []int, inc *incumbent, stop chan struct{}) solver.Result {
+	maxCost := 0
+	if weights == nil {
+		maxCost = len(lits)
+	} else {
+		for _, w := range weights {
+			maxCost += w
+		}
+	}
+	for s.Solve() == solver.Sat {
+		inc.update(s.Model(), modelCost(s.Model(), lits, weights))
+		ub := inc.cost()
+		if ub == 0 {
+			return inc.result()
+		}
+		if stopped(stop) {
+			return inc.result()
+		}
+		// Add a constraint so that the next solution is strictly better
+		hyp := make([]solver.Lit, len(lits))
+		for i, lit := range lits {
+			hyp[i] = lit.Negation()
+		}
+		var ws []int
+		if weights != nil {
+			ws = make([]int, len(weights))
+			copy(ws, weights)
+		}
+		s.AppendClause(solver.NewPBClause(hyp, ws, maxCost-ub+1))
+	}
+	if inc.cost() == -1 {
+		inc.setUnsat()
+	}
+	return inc.result()
+}
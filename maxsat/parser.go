@@ -7,58 +7,99 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/DoOR-Team/gophersat/input"
 	"github.com/DoOR-Team/gophersat/solver"
 )
 
 // A Solver is a [partial][weighted] MAXSAT solver.
 // It implements solver.Interface.
 type Solver struct {
-	solver     *solver.Solver
-	firstRelax int // Identifier of first relax variable: those must not be provided as part of the actual result
+	solver      *solver.Solver
+	firstRelax  int          // Identifier of first relax variable: those must not be provided as part of the actual result
+	relaxLits   []solver.Lit // All relax lits
+	weights     []int        // Weight of each relax lit
+	algo        Algorithm    // Algorithm used to solve the problem
+	constrs     []lsConstr   // Constraints, as seen by the local search
+	localSearch bool         // Should a local search be run alongside the exact search?
+}
+
+// SetAlgorithm sets the algorithm used when calling s.Optimal.
+// By default, LinearSearch is used.
+func (s *Solver) SetAlgorithm(algo Algorithm) {
+	s.algo = algo
+}
+
+// SetLocalSearch sets whether a stochastic local search is run alongside the exact search when calling s.Optimal.
+// The local search usually finds good solutions much faster than the exact search, and both searches
+// share the best solution they found so far. It is disabled by default.
+func (s *Solver) SetLocalSearch(enabled bool) {
+	s.localSearch = enabled
 }
 
 // Optimal looks for the optimal solution to the underlying problem.
 // If results is not nil, it writes a suboptimal solution every time it finds a new, better one.
 // In any case, it returns the optimal solution to the problem, or UNSAT if the problem cannot be found.
 func (s *Solver) Optimal(results chan solver.Result, stop chan struct{}) solver.Result {
-	if results == nil {
-		res := s.solver.Optimal(nil, stop)
-		return res
+	var constrs []lsConstr
+	if s.localSearch {
+		constrs = s.constrs
 	}
-	localRes := make(chan solver.Result)
-	defer close(results)
-	go s.solver.Optimal(localRes, stop)
-	var res solver.Result
-	for res = range localRes {
-		if res.Status == solver.Sat {
-			res.Model = res.Model[:s.firstRelax] // Remove relax vars from the model
-		}
-		results <- res
+	var (
+		localRes chan solver.Result
+		done     chan struct{}
+	)
+	if results != nil {
+		localRes = make(chan solver.Result)
+		done = make(chan struct{})
+		go func() {
+			defer close(done)
+			defer close(results)
+			for res := range localRes {
+				if res.Status == solver.Sat {
+					res.Model = res.Model[:s.firstRelax] // Remove relax vars from the model
+				}
+				results <- res
+			}
+		}()
+	}
+	res := optimize(s.solver, s.relaxLits, s.weights, s.algo, constrs, localRes, stop)
+	if results != nil {
+		<-done
 	}
-	return res // Last result is returned
+	if res.Status == solver.Sat {
+		res.Model = res.Model[:s.firstRelax] // Remove relax vars from the model
+	}
+	return res
 }
 
-// Enumerate does not make sense for a MAXSAT problem, so it will panic when called.
-// This might change in later versions.
-func (s *Solver) Enumerate(models chan []bool, stop chan struct{}) int {
-	panic("trying to call Enumerate on a MAXSAT problem")
+// A wcnfClause is a clause, as read in a WCNF file.
+type wcnfClause struct {
+	lits   []int
+	weight int
+	hard   bool
 }
 
-// ParseWCNF parses a CNF file and returns the corresponding solver.Interface.
+// ParseWCNF parses a WCNF file and returns the corresponding solver.Interface.
+// Two formats are supported, and automatically detected:
+//   - the classical format, starting with a "p wcnf nbvars nbclauses [top]" header,
+//     where each clause starts with its weight, and clauses whose weight is at least top are hard;
+//   - the format used since the 2022 MaxSAT Evaluation, that has no header,
+//     where hard clauses start with "h" and soft clauses start with their weight.
 func ParseWCNF(f io.Reader) (solver.Interface, error) {
+	f, err := input.Decompress(f)
+	if err != nil {
+		return nil, err
+	}
 	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineLen)
 	var (
 		nbVars    int
-		nbClauses int
 		topWeight int // weight of hard clauses
-		clauses   [][]int
-		weights   []int
-		maxWeight int
-		relaxLit  int // index of current relax lit
+		clauses   []wcnfClause
 	)
 	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == 'c' {
 			continue
 		}
 		if line[0] == 'p' {
@@ -71,65 +112,89 @@ func ParseWCNF(f io.Reader) (solver.Interface, error) {
 			if err != nil {
 				return nil, fmt.Errorf("nbvars not an int: %q", fields[2])
 			}
-			nbClauses, err = strconv.Atoi(fields[3])
+			nbClauses, err := strconv.Atoi(fields[3])
 			if err != nil {
 				return nil, fmt.Errorf("nbClauses not an int: %q", fields[3])
 			}
-			relaxLit = nbVars + 1
-			clauses = make([][]int, 0, nbClauses)
-			weights = make([]int, 0, nbClauses)
+			clauses = make([]wcnfClause, 0, nbClauses)
 			if len(fields) == 5 {
 				topWeight, err = strconv.Atoi(fields[4])
 				if err != nil {
 					return nil, fmt.Errorf("top weight not an int: %q", fields[4])
 				}
 			}
-		} else if line[0] != 'c' { // Not a header, not a comment : a clause
-			clause, weight, err := parseWCNFClause(line, topWeight, relaxLit)
+		} else { // Not a header, not a comment : a clause
+			clause, err := parseWCNFClause(line, topWeight)
 			if err != nil {
 				return nil, err
 			}
-			clauses = append(clauses, clause)
-			if topWeight == 0 || weight < topWeight {
-				weights = append(weights, weight)
-				maxWeight += weight
-				relaxLit++
+			for _, lit := range clause.lits {
+				if lit > nbVars {
+					nbVars = lit
+				} else if -lit > nbVars {
+					nbVars = -lit
+				}
 			}
+			clauses = append(clauses, clause)
 		}
 	}
-	relaxLits := make([]solver.Lit, relaxLit-nbVars-1)
-	for i := range relaxLits {
-		relaxLits[i] = solver.IntToLit(int32(nbVars + i + 1))
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("could not read WCNF file: %v", err)
 	}
-	prob := solver.ParseSlice(clauses)
+	var (
+		cnf       = make([][]int, 0, len(clauses))
+		relaxLits []solver.Lit
+		weights   []int
+		constrs   = make([]lsConstr, 0, len(clauses))
+		relaxLit  = nbVars + 1 // index of current relax lit
+	)
+	for _, clause := range clauses {
+		if clause.hard {
+			cnf = append(cnf, clause.lits)
+			constrs = append(constrs, newLSClause(clause.lits, 0, 0))
+		} else if clause.weight > 0 { // Soft clauses with a null weight are useless
+			cnf = append(cnf, append(clause.lits, relaxLit))
+			relaxLits = append(relaxLits, solver.IntToLit(int32(relaxLit)))
+			weights = append(weights, clause.weight)
+			constrs = append(constrs, newLSClause(clause.lits, clause.weight, relaxLit))
+			relaxLit++
+		}
+	}
+	prob := solver.ParseSliceNb(cnf, relaxLit-1) // Vars that only appear in the header are part of the problem too
 	prob.SetCostFunc(relaxLits, weights)
 	s := solver.New(prob)
-	return &Solver{solver: s, firstRelax: nbVars}, nil
+	return &Solver{solver: s, firstRelax: nbVars, relaxLits: relaxLits, weights: weights, constrs: constrs}, nil
 }
 
-// Parses a WCNF line containing a clause and returns the clause with a relaxing literal, and its weight.
-func parseWCNFClause(line string, topWeight, relaxLit int) (lits []int, weight int, err error) {
+// maxLineLen is the maximal length of a line in a WCNF file.
+// Clauses in industrial instances can be much longer than bufio.Scanner's default limit.
+const maxLineLen = 1 << 30
+
+// Parses a WCNF line containing a clause and returns the clause, without its terminating 0.
+// If topWeight is not 0, clauses with a weight at least equal to topWeight are hard.
+func parseWCNFClause(line string, topWeight int) (wcnfClause, error) {
 	fields := strings.Fields(line)
-	lits = make([]int, len(fields)-1)
-	for i := 0; i < len(fields); i++ { // Last field is clause terminator 0
-		field := fields[i]
-		if field == "" {
-			continue
+	if len(fields) < 2 || fields[len(fields)-1] != "0" {
+		return wcnfClause{}, fmt.Errorf("invalid WCNF clause %q: should be a weight followed by lits and terminated by 0", line)
+	}
+	var clause wcnfClause
+	if fields[0] == "h" {
+		clause.hard = true
+	} else {
+		weight, err := strconv.Atoi(fields[0])
+		if err != nil || weight < 0 {
+			return wcnfClause{}, fmt.Errorf("invalid weight %q in WCNF clause %q", fields[0], line)
 		}
+		clause.weight = weight
+		clause.hard = topWeight != 0 && weight >= topWeight
+	}
+	clause.lits = make([]int, len(fields)-2)
+	for i, field := range fields[1 : len(fields)-1] {
 		val, err := strconv.Atoi(field)
-		if err != nil {
-			return nil, 0, fmt.Errorf("Invalid integer %q in WCNF clause %q", field, line)
+		if err != nil || val == 0 {
+			return wcnfClause{}, fmt.Errorf("invalid literal %q in WCNF clause %q", field, line)
 		}
-		if i == 0 {
-			weight = val
-		} else {
-			lits[i-1] = val
-		}
-	}
-	if topWeight == 0 || weight < topWeight {
-		lits[len(lits)-1] = relaxLit
-	} else {
-		lits = lits[:len(lits)-1]
+		clause.lits[i] = val
 	}
-	return lits, weight, nil
+	return clause, nil
 }
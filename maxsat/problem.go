@@ -16,6 +16,14 @@ type Problem struct {
 	varInts      []string       // for each int value, the associated variable
 	blockWeights map[int]int    // for each blocking literal, the weight of the associated constraint
 	maxWeight    int            // sum of all blockWeights
+	softLits     [][]solver.Lit // for each objective, all blocking lits, in the order they were created
+	softWeights  [][]int        // for each objective, the weight of each blocking lit
+	algo         Algorithm      // algorithm used to solve the problem
+	constrs      []lsConstr     // constraints, as seen by the local search
+	localSearch  bool           // should a local search be run alongside the exact search?
+	top          int            // if not 0, the cost of a solution must be strictly lower than top
+	unsat        bool           // were the hard constraints found unsatisfiable when building the problem?
+	nbWBOVars    int            // for problems parsed by ParseWBO, number of vars in the file
 }
 
 // New returns a new problem associated with the given constraints.
@@ -40,28 +48,50 @@ func New(constrs ...Constr) *Problem {
 			coeffs = make([]int, len(constr.Coeffs))
 			copy(coeffs, constr.Coeffs)
 		}
+		lsc := lsConstr{lits: make([]solver.Lit, len(lits)), coeffs: coeffs, atLeast: constr.AtLeast, weight: constr.Weight}
+		for j, lit := range lits {
+			lsc.lits[j] = solver.IntToLit(int32(lit))
+		}
+		if coeffs != nil { // coeffs will be modified when building the PB constraint
+			lsc.coeffs = make([]int, len(coeffs))
+			copy(lsc.coeffs, coeffs)
+		}
 		if constr.Weight != 0 { // Soft constraint: add blocking literal
 			pb.varInts = append(pb.varInts, "") // Create new blocking lit
 			bl := len(pb.varInts)
 			pb.blockWeights[bl] = constr.Weight
 			pb.maxWeight += constr.Weight
+			if constr.Objective < 0 {
+				panic("negative objective index in soft constraint")
+			}
+			for len(pb.softLits) <= constr.Objective {
+				pb.softLits = append(pb.softLits, []solver.Lit{})
+				pb.softWeights = append(pb.softWeights, []int{})
+			}
+			obj := constr.Objective
+			pb.softLits[obj] = append(pb.softLits[obj], solver.IntToLit(int32(bl)))
+			pb.softWeights[obj] = append(pb.softWeights[obj], constr.Weight)
+			lsc.blockLit = solver.IntToLit(int32(bl))
 			lits = append(lits, bl)
+			if coeffs == nil && constr.AtLeast > 1 { // Cardinality constraint: the blocking lit needs an explicit coeff
+				coeffs = make([]int, len(constr.Lits))
+				for j := range coeffs {
+					coeffs[j] = 1
+				}
+			}
 			if coeffs != nil { // If this is a clause, there is no explicit coeff
-				// TODO: deal with card constraints: AtLeast !=1 but coeffs == nil!
 				coeffs = append(coeffs, constr.AtLeast)
 			}
 		}
+		pb.constrs = append(pb.constrs, lsc)
 		clauses[i] = solver.GtEq(lits, coeffs, constr.AtLeast)
 	}
-	optLits := make([]solver.Lit, 0, len(pb.blockWeights))
-	optWeights := make([]int, 0, len(pb.blockWeights))
-	for v, w := range pb.blockWeights {
-		optLits = append(optLits, solver.IntToLit(int32(v)))
-		optWeights = append(optWeights, w)
-	}
 	prob := solver.ParsePBConstrs(clauses)
-	prob.SetCostFunc(optLits, optWeights)
+	for i := range pb.softLits {
+		prob.AddCostFunc(pb.softLits[i], pb.softWeights[i])
+	}
 	pb.solver = solver.New(prob)
+	pb.unsat = prob.Status == solver.Unsat
 	return pb
 }
 
@@ -70,6 +100,19 @@ func (pb *Problem) SetVerbose(verbose bool) {
 	pb.solver.Verbose = verbose
 }
 
+// SetAlgorithm sets the algorithm used when calling pb.Solve().
+// By default, LinearSearch is used.
+// Problems with several objectives are always solved through linear searches.
+func (pb *Problem) SetAlgorithm(algo Algorithm) {
+	pb.algo = algo
+}
+
+// SetLocalSearch sets whether a stochastic local search is run alongside the exact search when calling pb.Solve().
+// It is disabled by default.
+func (pb *Problem) SetLocalSearch(enabled bool) {
+	pb.localSearch = enabled
+}
+
 // Output output the problem to stdout in the OPB format.
 func (pb *Problem) Output() {
 	fmt.Println(pb.solver.PBString())
@@ -84,17 +127,92 @@ func (pb *Problem) Solver() *solver.Solver {
 
 // Solve returns an optimal Model for the problem and the associated cost.
 // If the model is nil, the problem was not satisfiable (i.e hard clauses could not be satisfied).
+// If the problem has several objectives, they are optimized in lexicographic order
+// and the returned cost is the one associated with the first objective.
 func (pb *Problem) Solve() (Model, int) {
-	cost := pb.solver.Minimize()
-	if cost == -1 {
+	if len(pb.softLits) > 1 {
+		model, costs := pb.SolveLex()
+		if model == nil {
+			return nil, -1
+		}
+		return model, costs[0]
+	}
+	var (
+		cost  int
+		model []bool
+	)
+	if pb.algo != LinearSearch || pb.localSearch {
+		var constrs []lsConstr
+		if pb.localSearch {
+			constrs = pb.constrs
+		}
+		var lits []solver.Lit
+		var weights []int
+		if len(pb.softLits) == 1 {
+			lits, weights = pb.softLits[0], pb.softWeights[0]
+		}
+		res := optimize(pb.solver, lits, weights, pb.algo, constrs, nil, nil)
+		if res.Status != solver.Sat {
+			return nil, -1
+		}
+		cost, model = res.Weight, res.Model
+	} else {
+		if cost = pb.solver.Minimize(); cost == -1 {
+			return nil, -1
+		}
+		model = pb.solver.Model()
+	}
+	if pb.top > 0 && cost >= pb.top { // The local search ignores the top
 		return nil, -1
 	}
+	return pb.model(model), cost
+}
+
+// SolveLex returns an optimal Model for the problem and the cost associated with each objective.
+// Objectives are optimized in lexicographic order: the first objective is minimized, then the second one
+// is minimized among the optimal solutions for the first one, etc.
+// If the model is nil, the problem was not satisfiable.
+func (pb *Problem) SolveLex() (Model, []int) {
+	if len(pb.softLits) <= 1 {
+		model, cost := pb.Solve()
+		if model == nil {
+			return nil, nil
+		}
+		return model, []int{cost}
+	}
+	res := pb.solver.Optimal(nil, nil)
+	if res.Status != solver.Sat {
+		return nil, nil
+	}
+	return pb.model(res.Model), res.Weights
+}
+
+// A Solution is a model associated with the cost of each objective.
+type Solution struct {
+	Model Model
+	Costs []int
+}
+
+// ParetoFront returns the Pareto-optimal solutions to the problem: no other solution is at least as good as them
+// for all objectives and strictly better for at least one. Exactly one solution is returned for each possible
+// vector of costs. Priorities between objectives are ignored.
+// If the problem is not satisfiable, nil is returned.
+func (pb *Problem) ParetoFront() []Solution {
+	var res []Solution
+	for _, r := range pb.solver.ParetoFront(nil, nil) {
+		res = append(res, Solution{Model: pb.model(r.Model), Costs: r.Weights})
+	}
+	return res
+}
+
+// model returns the Model associated with the given bindings.
+func (pb *Problem) model(bindings []bool) Model {
 	res := make(Model)
-	for i, binding := range pb.solver.Model() {
+	for i, binding := range bindings {
 		name := pb.varInts[i]
 		if name != "" { // Ignore blocking lits
 			res[name] = binding
 		}
 	}
-	return res, cost
+	return res
 }
@@ -3,7 +3,10 @@ package maxsat
 import (
 	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
+
+	"github.com/DoOR-Team/gophersat/solver"
 )
 
 func TestUnsat(t *testing.T) {
@@ -125,8 +128,334 @@ func TestTSP(t *testing.T) {
 	}
 }
 
+func TestTSPOLL(t *testing.T) {
+	constrs := generateTSP(6)
+	_, expected := New(constrs...).Solve()
+	pb := New(constrs...)
+	pb.SetAlgorithm(OLL)
+	if model, cost := pb.Solve(); model == nil {
+		t.Errorf("expected sat, got unsat")
+	} else if cost != expected {
+		t.Errorf("invalid cost, expected %d, got %d", expected, cost)
+	}
+}
+
+func TestTSPIHS(t *testing.T) {
+	constrs := generateTSP(6)
+	_, expected := New(constrs...).Solve()
+	pb := New(constrs...)
+	pb.SetAlgorithm(IHS)
+	if model, cost := pb.Solve(); model == nil {
+		t.Errorf("expected sat, got unsat")
+	} else if cost != expected {
+		t.Errorf("invalid cost, expected %d, got %d", expected, cost)
+	}
+}
+
+func TestUnsatIHS(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a"), Var("b")),
+		HardClause(Not("b")),
+		SoftClause(Var("a")),
+	)
+	pb.SetAlgorithm(IHS)
+	if model, cost := pb.Solve(); model != nil {
+		t.Errorf("expected unsat, got model %v, cost %d", model, cost)
+	}
+}
+
+func TestUnsatOLL(t *testing.T) {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		HardClause(Not("a"), Var("b")),
+		HardClause(Not("b")),
+		SoftClause(Var("a")),
+	)
+	pb.SetAlgorithm(OLL)
+	if model, cost := pb.Solve(); model != nil {
+		t.Errorf("expected unsat, got model %v, cost %d", model, cost)
+	}
+}
+
+func TestParseWCNFAlgorithms(t *testing.T) {
+	const wcnf = `c This is a weighted partial MAXSAT problem
+p wcnf 4 8 100
+100 1 2 0
+100 -1 -2 0
+100 3 4 0
+3 -1 0
+5 -2 0
+2 -3 0
+4 -4 0
+1 1 3 0
+`
+	for _, algo := range []Algorithm{LinearSearch, OLL, IHS} {
+		for _, ls := range []bool{false, true} {
+			s, err := ParseWCNF(strings.NewReader(wcnf))
+			if err != nil {
+				t.Fatalf("could not parse WCNF: %v", err)
+			}
+			s.(*Solver).SetAlgorithm(algo)
+			s.(*Solver).SetLocalSearch(ls)
+			res := s.Optimal(nil, nil)
+			if res.Status != solver.Sat {
+				t.Errorf("%v (local search: %t): expected sat, got %v", algo, ls, res.Status)
+				continue
+			}
+			if res.Weight != 5 {
+				t.Errorf("%v (local search: %t): invalid cost, expected 5, got %d", algo, ls, res.Weight)
+			}
+			if len(res.Model) != 4 {
+				t.Errorf("%v (local search: %t): invalid model size, expected 4, got %d", algo, ls, len(res.Model))
+			}
+		}
+	}
+}
+
+func TestTSPLocalSearch(t *testing.T) {
+	constrs := generateTSP(6)
+	_, expected := New(constrs...).Solve()
+	pb := New(constrs...)
+	pb.SetLocalSearch(true)
+	if model, cost := pb.Solve(); model == nil {
+		t.Errorf("expected sat, got unsat")
+	} else if cost != expected {
+		t.Errorf("invalid cost, expected %d, got %d", expected, cost)
+	}
+}
+
+// Dynamic weights of the local search must not overflow with huge weights.
+func TestLocalSearchHugeWeights(t *testing.T) {
+	const huge = 1 << 62
+	ls := newLocalSearch(2, []lsConstr{newLSClause([]int{1}, huge, 3), newLSClause([]int{2}, huge/2, 4), newLSClause([]int{-1, -2}, 1, 5)})
+	if ls.maxWs[0] != lsSoftLimit || ls.maxWs[1] != lsSoftLimit/2 || ls.maxWs[2] != 1 {
+		t.Errorf("invalid dynamic weights %v", ls.maxWs)
+	}
+}
+
+func TestSoftCardConstr(t *testing.T) {
+	pb := New(
+		HardClause(Not("a"), Not("b")),
+		SoftPBConstr([]Lit{Var("a"), Var("b"), Var("c")}, nil, 2),
+	)
+	model, cost := pb.Solve()
+	if model == nil {
+		t.Fatalf("expected sat, got unsat")
+	}
+	if cost != 0 {
+		t.Errorf("invalid cost, expected 0, got %d", cost)
+	}
+	if nb := btoi(model["a"]) + btoi(model["b"]) + btoi(model["c"]); nb < 2 {
+		t.Errorf("invalid model %v: cardinality constraint is not satisfied", model)
+	}
+}
+
+func TestOptimalStreamLocalSearch(t *testing.T) {
+	const wcnf = `p wcnf 3 6 10
+10 1 2 0
+10 -1 -2 0
+3 -1 0
+2 -2 0
+1 3 0
+1 -3 0
+`
+	for _, algo := range []Algorithm{LinearSearch, OLL, IHS} {
+		s, err := ParseWCNF(strings.NewReader(wcnf))
+		if err != nil {
+			t.Fatalf("could not parse WCNF: %v", err)
+		}
+		s.(*Solver).SetAlgorithm(algo)
+		s.(*Solver).SetLocalSearch(true)
+		results := make(chan solver.Result)
+		go s.Optimal(results, nil)
+		prev := -1
+		var res solver.Result
+		for res = range results {
+			if res.Status != solver.Sat {
+				continue
+			}
+			if prev != -1 && res.Weight >= prev {
+				t.Errorf("%v: solutions do not improve: got cost %d after %d", algo, res.Weight, prev)
+			}
+			prev = res.Weight
+		}
+		if res.Status != solver.Sat || res.Weight != 3 {
+			t.Errorf("%v: expected optimal cost 3, got %v with cost %d", algo, res.Status, res.Weight)
+		}
+	}
+}
+
+func btoi(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
 func BenchmarkTSP(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		New(generateTSP(9)...).Solve()
 	}
 }
+
+func TestParseWCNF2022(t *testing.T) {
+	const wcnf = `c This is the same problem as in TestParseWCNFAlgorithms, in the 2022 format
+h 1 2 0
+h -1 -2 0
+h 3 4 0
+3000000000 -1 0
+5000000000 -2 0
+2000000000 -3 0
+4000000000 -4 0
+1000000000 1 3 0
+`
+	s, err := ParseWCNF(strings.NewReader(wcnf))
+	if err != nil {
+		t.Fatalf("could not parse WCNF: %v", err)
+	}
+	res := s.Optimal(nil, nil)
+	if res.Status != solver.Sat {
+		t.Fatalf("expected sat, got %v", res.Status)
+	}
+	if res.Weight != 5000000000 {
+		t.Errorf("invalid cost, expected 5000000000, got %d", res.Weight)
+	}
+	if len(res.Model) != 4 {
+		t.Errorf("invalid model size, expected 4, got %d", len(res.Model))
+	}
+}
+
+func TestParseWCNFErrors(t *testing.T) {
+	for _, wcnf := range []string{
+		"h 1 2\n",
+		"x 1 2 0\n",
+		"-3 1 2 0\n",
+		"3 1 a 0\n",
+		"p cnf 2 1\n1 2 0\n",
+	} {
+		if _, err := ParseWCNF(strings.NewReader(wcnf)); err == nil {
+			t.Errorf("expected error when parsing %q", wcnf)
+		}
+	}
+}
+
+// In biObjectiveConstrs, choosing a costs 2 for the first objective, and choosing b costs 3 for the second one.
+var biObjectiveConstrs = []Constr{
+	HardClause(Var("a"), Var("b")),
+	{Lits: []Lit{Not("a")}, AtLeast: 1, Weight: 2},
+	{Lits: []Lit{Not("b")}, AtLeast: 1, Weight: 3, Objective: 1},
+}
+
+func TestSolveLex(t *testing.T) {
+	model, costs := New(biObjectiveConstrs...).SolveLex()
+	if model == nil {
+		t.Fatalf("expected sat, got unsat")
+	}
+	if len(costs) != 2 || costs[0] != 0 || costs[1] != 3 {
+		t.Errorf("invalid costs, expected [0 3], got %v", costs)
+	}
+	if model["a"] || !model["b"] {
+		t.Errorf("invalid model %v", model)
+	}
+	if _, cost := New(biObjectiveConstrs...).Solve(); cost != 0 {
+		t.Errorf("invalid cost for first objective, expected 0, got %d", cost)
+	}
+}
+
+func TestParetoFront(t *testing.T) {
+	front := New(biObjectiveConstrs...).ParetoFront()
+	if len(front) != 2 {
+		t.Fatalf("expected 2 solutions, got %v", front)
+	}
+	for _, sol := range front {
+		switch {
+		case sol.Costs[0] == 0 && sol.Costs[1] == 3:
+			if sol.Model["a"] || !sol.Model["b"] {
+				t.Errorf("invalid model %v for costs %v", sol.Model, sol.Costs)
+			}
+		case sol.Costs[0] == 2 && sol.Costs[1] == 0:
+			if !sol.Model["a"] || sol.Model["b"] {
+				t.Errorf("invalid model %v for costs %v", sol.Model, sol.Costs)
+			}
+		default:
+			t.Errorf("unexpected costs %v", sol.Costs)
+		}
+	}
+}
+
+// In exactlyOneWCNF, exactly one of x1, x2 and x3 must be true, x1 costs 2, and x2 and x3 cost 1 each.
+const exactlyOneWCNF = `p wcnf 3 7 10
+10 1 2 3 0
+10 -1 -2 0
+10 -1 -3 0
+10 -2 -3 0
+2 -1 0
+1 -2 0
+1 -3 0
+`
+
+func TestEnumerate(t *testing.T) {
+	s, err := ParseWCNF(strings.NewReader(exactlyOneWCNF))
+	if err != nil {
+		t.Fatalf("could not parse WCNF: %v", err)
+	}
+	models := make(chan []bool)
+	nbc := make(chan int, 1)
+	go func() { nbc <- s.Enumerate(models, nil) }()
+	seen := make(map[string]bool)
+	for model := range models {
+		if len(model) != 3 {
+			t.Fatalf("invalid model size, expected 3, got %d", len(model))
+		}
+		if model[0] || model[1] == model[2] {
+			t.Errorf("invalid optimal model %v", model)
+		}
+		seen[fmt.Sprint(model)] = true
+	}
+	if nb := <-nbc; nb != 2 || len(seen) != 2 {
+		t.Errorf("expected 2 optimal models, got %d (%d distinct)", nb, len(seen))
+	}
+}
+
+func TestEnumerateBest(t *testing.T) {
+	for _, tc := range []struct {
+		k     int
+		costs []int
+	}{
+		{0, []int{1, 1, 2}},
+		{2, []int{1, 1}},
+		{3, []int{1, 1, 2}},
+		{5, []int{1, 1, 2}},
+	} {
+		s, err := ParseWCNF(strings.NewReader(exactlyOneWCNF))
+		if err != nil {
+			t.Fatalf("could not parse WCNF: %v", err)
+		}
+		results := make(chan solver.Result)
+		go s.(*Solver).EnumerateBest(tc.k, results, nil)
+		var costs []int
+		for res := range results {
+			if len(res.Model) != 3 {
+				t.Fatalf("k=%d: invalid model size, expected 3, got %d", tc.k, len(res.Model))
+			}
+			if cost := 2*btoi(res.Model[0]) + btoi(res.Model[1]) + btoi(res.Model[2]); cost != res.Weight {
+				t.Errorf("k=%d: model %v has cost %d, reported %d", tc.k, res.Model, cost, res.Weight)
+			}
+			costs = append(costs, res.Weight)
+		}
+		if fmt.Sprint(costs) != fmt.Sprint(tc.costs) {
+			t.Errorf("k=%d: expected costs %v, got %v", tc.k, tc.costs, costs)
+		}
+	}
+}
+
+func TestEnumerateUnsat(t *testing.T) {
+	s, err := ParseWCNF(strings.NewReader("p wcnf 1 3 10\n10 1 0\n10 -1 0\n1 1 0\n"))
+	if err != nil {
+		t.Fatalf("could not parse WCNF: %v", err)
+	}
+	if nb := s.Enumerate(nil, nil); nb != 0 {
+		t.Errorf("expected no model, got %d", nb)
+	}
+}
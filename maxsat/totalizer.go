@@ -0,0 +1,76 @@
+package maxsat
+
+import "github.com/DoOR-Team/gophersat/solver"
+
+// A totalizer is an incremental encoding of a cardinality constraint.
+// It is a binary tree whose leaves are the input literals, and where each node
+// counts, in unary, the number of true literals among the leaves below it.
+// Only the implication from the inputs to the outputs is encoded,
+// so assuming the negation of an output bounds the number of true inputs.
+// The totalizer is lazy: outputs are only created up to the bound that is currently needed,
+// and the bound can be extended later on.
+// This is why the Totalizer encoding of the encode package is not used here: it builds the whole CNF
+// of an at-most-k constraint at once, for a fixed k, and does not keep the tree to extend it afterwards.
+type totalizer struct {
+	outputs     []solver.Lit // outputs[i] is true if at least i+1 inputs are true
+	left, right *totalizer   // Children, or nil for a leaf
+	nbInputs    int          // Number of leaves below this node
+}
+
+// newTotalizer returns a totalizer over the given inputs, whose outputs are created up to bound.
+// New vars and clauses are added to s.
+func newTotalizer(s *solver.Solver, inputs []solver.Lit, bound int) *totalizer {
+	if len(inputs) == 1 {
+		return &totalizer{outputs: []solver.Lit{inputs[0]}, nbInputs: 1}
+	}
+	mid := len(inputs) / 2
+	t := &totalizer{
+		left:     newTotalizer(s, inputs[:mid], bound),
+		right:    newTotalizer(s, inputs[mid:], bound),
+		nbInputs: len(inputs),
+	}
+	t.extend(s, bound)
+	return t
+}
+
+// output returns the literal that is true if at least k inputs are true.
+// Outputs must have been created up to k beforehand.
+func (t *totalizer) output(k int) solver.Lit {
+	return t.outputs[k-1]
+}
+
+// extend creates all outputs up to bound, if they don't exist yet.
+func (t *totalizer) extend(s *solver.Solver, bound int) {
+	if bound > t.nbInputs {
+		bound = t.nbInputs
+	}
+	if t.left == nil || len(t.outputs) >= bound {
+		return
+	}
+	t.left.extend(s, bound)
+	t.right.extend(s, bound)
+	old := len(t.outputs)
+	for len(t.outputs) < bound {
+		t.outputs = append(t.outputs, s.NewVar().Lit())
+	}
+	// For each new output k, if i lits are true on the left and j = k-i on the right, then output k is true.
+	// Clauses for smaller outputs were created when they were added, so they are not added again.
+	left, right := t.left.outputs, t.right.outputs
+	for k := old + 1; k <= bound; k++ {
+		for i := 0; i <= k && i <= len(left); i++ {
+			j := k - i
+			if j > len(right) {
+				continue
+			}
+			lits := make([]solver.Lit, 0, 3)
+			if i > 0 {
+				lits = append(lits, left[i-1].Negation())
+			}
+			if j > 0 {
+				lits = append(lits, right[j-1].Negation())
+			}
+			lits = append(lits, t.outputs[k-1])
+			s.AppendClause(solver.NewClause(lits))
+		}
+	}
+}
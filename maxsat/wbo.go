@@ -0,0 +1,82 @@
+package maxsat
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/DoOR-Team/gophersat/opb"
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// ParseWBO parses a weighted boolean optimization problem in the WBO format, and returns the corresponding problem.
+// Variables are named after their name in the file, e.g "x1". Products of literals are replaced by auxiliary
+// variables, whose names start with "_p". If the problem declares a top cost, only solutions whose cost is
+// strictly lower than it are accepted. Variables that were eliminated when normalizing the problem
+// can be missing from its models; NbWBOVars returns the number of variables of the file.
+func ParseWBO(r io.Reader) (*Problem, error) {
+	p, err := opb.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+	if !p.WBO {
+		return nil, fmt.Errorf("missing \"soft:\" line in WBO problem")
+	}
+	if len(p.Objectives) != 0 {
+		return nil, fmt.Errorf("unexpected objective in WBO problem")
+	}
+	nbVars := p.NbVars // Vars above this one are auxiliary vars
+	p.Normalize()
+	lit := func(val int) Lit {
+		v := val
+		if v < 0 {
+			v = -v
+		}
+		name := fmt.Sprintf("x%d", v)
+		if v > nbVars {
+			name = fmt.Sprintf("_p%d", v-nbVars)
+		}
+		return Lit{Var: name, Negated: val < 0}
+	}
+	constrs := make([]Constr, len(p.Constraints))
+	for i, c := range p.Constraints {
+		constrs[i] = Constr{Lits: make([]Lit, len(c.Terms)), AtLeast: c.RHS, Weight: c.Weight}
+		coeffs := make([]int, len(c.Terms))
+		card := true // Are all coeffs 1?
+		for j, t := range c.Terms {
+			constrs[i].Lits[j] = lit(t.Lits[0])
+			coeffs[j] = t.Coeff
+			card = card && t.Coeff == 1
+		}
+		if !card {
+			constrs[i].Coeffs = coeffs
+		}
+	}
+	pb := New(constrs...)
+	if p.Top > 0 && len(pb.softLits) != 0 && !pb.unsat { // No clause can be added to an unsat solver
+		pb.setTop(p.Top)
+	}
+	pb.nbWBOVars = nbVars
+	return pb, nil
+}
+
+// NbWBOVars returns the number of variables declared or used in the file pb was parsed from by ParseWBO,
+// i.e the variables are named "x1" to "xN".
+func (pb *Problem) NbWBOVars() int {
+	return pb.nbWBOVars
+}
+
+// setTop states that the cost of a solution must be strictly lower than top.
+func (pb *Problem) setTop(top int) {
+	pb.top = top
+	lits := make([]solver.Lit, len(pb.softLits[0]))
+	weights := make([]int, len(lits))
+	card := 1 - top
+	for i, bl := range pb.softLits[0] {
+		lits[i] = bl.Negation()
+		weights[i] = pb.softWeights[0][i]
+		card += weights[i]
+	}
+	if card > 0 {
+		pb.solver.AppendClause(solver.NewPBClause(lits, weights, card))
+	}
+}
@@ -0,0 +1,78 @@
+package maxsat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseWBO(t *testing.T) {
+	const content = `* #variable= 3 #constraint= 4 #soft= 3
+soft: %s ;
+[2] +1 x1 >= 1 ;
+[3] +1 x2 x3 >= 1 ;
+[4] +1 ~x3 = 1 ;
+-1 x1 -1 x2 >= -1 ;
+`
+	tests := []struct {
+		top          string
+		expectedCost int // -1 if the problem is unsat
+	}{
+		{"", 3},
+		{"10", 3},
+		{"4", 3},
+		{"3", -1},
+	}
+	for _, test := range tests {
+		pb, err := ParseWBO(strings.NewReader(strings.Replace(content, "%s", test.top, 1)))
+		if err != nil {
+			t.Fatalf("could not parse problem with top %q: %v", test.top, err)
+		}
+		if pb.NbWBOVars() != 3 {
+			t.Errorf("top %q: expected 3 vars, got %d", test.top, pb.NbWBOVars())
+		}
+		model, cost := pb.Solve()
+		if cost != test.expectedCost {
+			t.Errorf("top %q: expected cost %d, got %d", test.top, test.expectedCost, cost)
+		}
+		if cost != -1 && (!model["x1"] || model["x2"] || model["x3"]) {
+			t.Errorf("top %q: invalid model %v", test.top, model)
+		}
+	}
+}
+
+// Vars eliminated by normalization are missing from the model, but are still counted.
+func TestParseWBOEliminatedVars(t *testing.T) {
+	pb, err := ParseWBO(strings.NewReader("soft: ;\n[3] 1 x1 1 ~x1 >= 2 ;\n1 x2 >= 1 ;\n"))
+	if err != nil {
+		t.Fatalf("could not parse problem: %v", err)
+	}
+	if pb.NbWBOVars() != 2 {
+		t.Errorf("expected 2 vars, got %d", pb.NbWBOVars())
+	}
+	if model, cost := pb.Solve(); cost != 3 || !model["x2"] {
+		t.Errorf("expected cost 3 with x2 true, got cost %d and model %v", cost, model)
+	}
+}
+
+// A top cost used to make ParseWBO panic when the hard constraints were trivially unsatisfiable.
+func TestParseWBOUnsatTop(t *testing.T) {
+	pb, err := ParseWBO(strings.NewReader("soft: 3 ;\n+1 x3 >= 2 ;\n[5] +1 x2 x1 >= 1 ;\n"))
+	if err != nil {
+		t.Fatalf("could not parse problem: %v", err)
+	}
+	if _, cost := pb.Solve(); cost != -1 {
+		t.Errorf("expected unsat problem, got cost %d", cost)
+	}
+}
+
+func TestParseWBOErrors(t *testing.T) {
+	for _, content := range []string{
+		"+1 x1 >= 1 ;\n",
+		"soft: ;\nmin: +1 x1 ;\n+1 x1 >= 1 ;\n",
+		"soft: ;\n[1] +1 x1 >= 1\n",
+	} {
+		if _, err := ParseWBO(strings.NewReader(content)); err == nil {
+			t.Errorf("expected error when parsing %q, got nil", content)
+		}
+	}
+}
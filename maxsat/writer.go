@@ -0,0 +1,153 @@
+package maxsat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// numbering returns the number of each variable of the problem in the files it is written to,
+// indexed by the variable of the underlying solver.
+// Named variables come first, in the order they first appeared in the constraints, followed by blocking literals.
+func (pb *Problem) numbering() []int {
+	res := make([]int, len(pb.varInts))
+	n := 0
+	for i, name := range pb.varInts {
+		if name != "" {
+			n++
+			res[i] = n
+		}
+	}
+	for i, name := range pb.varInts {
+		if name == "" {
+			n++
+			res[i] = n
+		}
+	}
+	return res
+}
+
+// checkClauses returns an error if the problem contains PB or cardinality constraints,
+// as they cannot be represented in the given format.
+func (pb *Problem) checkClauses(format string) error {
+	for _, c := range pb.constrs {
+		if c.atLeast != 1 {
+			return fmt.Errorf("cannot write a PB or cardinality constraint in the %s format", format)
+		}
+		for i := range c.lits {
+			if c.coeff(i) < 1 {
+				return fmt.Errorf("cannot write a PB constraint in the %s format", format)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteDIMACS writes the problem to w in the DIMACS CNF format.
+// Variables are numbered from 1, in the order they first appeared in the constraints.
+// It returns an error if the problem contains soft constraints, PB or cardinality constraints,
+// as they cannot be represented in that format.
+func (pb *Problem) WriteDIMACS(w io.Writer) error {
+	if len(pb.softLits) != 0 {
+		return fmt.Errorf("cannot write an optimization problem in the DIMACS format")
+	}
+	if err := pb.checkClauses("DIMACS"); err != nil {
+		return err
+	}
+	nums := pb.numbering()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p cnf %d %d\n", len(pb.intVars), len(pb.constrs))
+	for _, c := range pb.constrs {
+		for _, lit := range c.lits {
+			writeDIMACSLit(bw, nums, lit)
+			bw.WriteByte(' ')
+		}
+		bw.WriteString("0\n")
+	}
+	return bw.Flush()
+}
+
+// WriteWCNF writes the problem to w in the WCNF format.
+// Named variables are numbered from 1, in the order they first appeared in the constraints.
+// It returns an error if the problem contains PB or cardinality constraints, or several objectives,
+// as they cannot be represented in that format.
+func (pb *Problem) WriteWCNF(w io.Writer) error {
+	if len(pb.softLits) > 1 {
+		return fmt.Errorf("cannot write a problem with several objectives in the WCNF format")
+	}
+	if err := pb.checkClauses("WCNF"); err != nil {
+		return err
+	}
+	top := 1
+	for _, c := range pb.constrs {
+		top += c.weight
+	}
+	nums := pb.numbering()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p wcnf %d %d %d\n", len(pb.intVars), len(pb.constrs), top)
+	for _, c := range pb.constrs {
+		if c.weight == 0 {
+			bw.WriteString(strconv.Itoa(top))
+		} else {
+			bw.WriteString(strconv.Itoa(c.weight))
+		}
+		for _, lit := range c.lits {
+			bw.WriteByte(' ')
+			writeDIMACSLit(bw, nums, lit)
+		}
+		bw.WriteString(" 0\n")
+	}
+	return bw.Flush()
+}
+
+// WriteOPB writes the problem to w in the OPB format.
+// Named variables are numbered from 1, in the order they first appeared in the constraints.
+// Soft constraints are relaxed by a blocking literal, numbered after the named variables,
+// and the "min:" line of each objective, by decreasing priority, minimizes the weighted sum of its blocking literals.
+func (pb *Problem) WriteOPB(w io.Writer) error {
+	nums := pb.numbering()
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "* #variable= %d #constraint= %d\n", len(pb.varInts), len(pb.constrs))
+	for obj := range pb.softLits {
+		bw.WriteString("min:")
+		for i, lit := range pb.softLits[obj] {
+			fmt.Fprintf(bw, " +%d ", pb.softWeights[obj][i])
+			writePBLit(bw, nums, lit)
+		}
+		bw.WriteString(" ;\n")
+	}
+	for _, c := range pb.constrs {
+		for i, lit := range c.lits {
+			fmt.Fprintf(bw, "%+d ", c.coeff(i))
+			writePBLit(bw, nums, lit)
+			bw.WriteByte(' ')
+		}
+		if c.weight != 0 {
+			fmt.Fprintf(bw, "%+d ", c.atLeast)
+			writePBLit(bw, nums, c.blockLit)
+			bw.WriteByte(' ')
+		}
+		fmt.Fprintf(bw, ">= %d ;\n", c.atLeast)
+	}
+	return bw.Flush()
+}
+
+// writeDIMACSLit writes lit as a DIMACS literal, renumbered according to nums.
+func writeDIMACSLit(bw *bufio.Writer, nums []int, lit solver.Lit) {
+	if !lit.IsPositive() {
+		bw.WriteByte('-')
+	}
+	bw.WriteString(strconv.Itoa(nums[lit.Var()]))
+}
+
+// writePBLit writes lit as an OPB literal, i.e "x1" or "~x1", renumbered according to nums.
+func writePBLit(bw *bufio.Writer, nums []int, lit solver.Lit) {
+	if !lit.IsPositive() {
+		bw.WriteByte('~')
+	}
+	bw.WriteByte('x')
+	bw.WriteString(strconv.Itoa(nums[lit.Var()]))
+}
@@ -0,0 +1,132 @@
+package maxsat
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// randomClause returns a random clause over the variables a to f.
+func randomClause(r *rand.Rand) []Lit {
+	perm := r.Perm(6)[:1+r.Intn(3)]
+	lits := make([]Lit, len(perm))
+	for i, v := range perm {
+		lits[i] = Lit{Var: string(rune('a' + v)), Negated: r.Intn(2) == 0}
+	}
+	return lits
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 100; iter++ {
+		var constrs []Constr
+		for i := 0; i < 2+r.Intn(8); i++ {
+			if r.Intn(2) == 0 {
+				constrs = append(constrs, HardClause(randomClause(r)...))
+			} else {
+				constrs = append(constrs, WeightedClause(randomClause(r), 1+r.Intn(5)))
+			}
+		}
+		pb := New(constrs...)
+		_, expected := pb.Solve()
+		var wcnf, opb bytes.Buffer
+		if err := New(constrs...).WriteWCNF(&wcnf); err != nil {
+			t.Fatalf("could not write %v as WCNF: %v", constrs, err)
+		}
+		if err := New(constrs...).WriteOPB(&opb); err != nil {
+			t.Fatalf("could not write %v as OPB: %v", constrs, err)
+		}
+		content := wcnf.String()
+		s, err := ParseWCNF(&wcnf)
+		if err != nil {
+			t.Fatalf("could not parse WCNF output %q: %v", content, err)
+		}
+		if res := s.Optimal(nil, nil); (res.Status == solver.Sat && res.Weight != expected) || (res.Status != solver.Sat && expected != -1) {
+			t.Errorf("after WCNF round trip of %v through %q, expected cost %d, got %v", constrs, content, expected, res)
+		}
+		content = opb.String()
+		pb2, err := solver.ParseOPB(&opb)
+		if err != nil {
+			t.Fatalf("could not parse OPB output %q: %v", content, err)
+		}
+		if cost := solver.New(pb2).Minimize(); cost != expected {
+			t.Errorf("after OPB round trip of %v through %q, expected cost %d, got %d", constrs, content, expected, cost)
+		}
+	}
+}
+
+func TestSolverWriteWCNF(t *testing.T) {
+	pb := solver.ParseSlice([][]int{{1, 2, 3}, {-1, -2}, {-2, -3}, {-1, 3}})
+	pb.SetCostFunc([]solver.Lit{solver.IntToLit(1), solver.IntToLit(2), solver.IntToLit(3)}, []int{4, 1, 2})
+	var b bytes.Buffer
+	if err := pb.WriteWCNF(&b); err != nil {
+		t.Fatalf("could not write problem: %v", err)
+	}
+	s, err := ParseWCNF(&b)
+	if err != nil {
+		t.Fatalf("could not parse problem: %v", err)
+	}
+	if res := s.Optimal(nil, nil); res.Status != solver.Sat || res.Weight != 1 {
+		t.Errorf("expected cost 1, got %v", res)
+	}
+}
+
+func TestWriteWCNFErrors(t *testing.T) {
+	pb := New(HardClause(Var("a"), Var("b")), SoftPBConstr([]Lit{Var("a"), Var("b")}, []int{1, 2}, 2))
+	if err := pb.WriteWCNF(&bytes.Buffer{}); err == nil {
+		t.Errorf("PB constraint should not have been written as WCNF")
+	}
+	pb = New(SoftClause(Var("a")), Constr{Lits: []Lit{Var("b")}, AtLeast: 1, Weight: 1, Objective: 1})
+	if err := pb.WriteWCNF(&bytes.Buffer{}); err == nil {
+		t.Errorf("problem with two objectives should not have been written as WCNF")
+	}
+}
+
+func TestWriteDIMACS(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 100; iter++ {
+		var constrs []Constr
+		for i := 0; i < 2+r.Intn(8); i++ {
+			constrs = append(constrs, HardClause(randomClause(r)...))
+		}
+		_, expected := New(constrs...).Solve()
+		var cnf bytes.Buffer
+		if err := New(constrs...).WriteDIMACS(&cnf); err != nil {
+			t.Fatalf("could not write %v as DIMACS: %v", constrs, err)
+		}
+		content := cnf.String()
+		pb, err := solver.ParseCNF(&cnf)
+		if err != nil {
+			t.Fatalf("could not parse DIMACS output %q: %v", content, err)
+		}
+		if status := solver.New(pb).Solve(); (status == solver.Sat) != (expected != -1) {
+			t.Errorf("after DIMACS round trip of %v through %q, expected cost %d, got status %v", constrs, content, expected, status)
+		}
+	}
+	if err := New(HardClause(Var("a")), SoftClause(Var("b"))).WriteDIMACS(&bytes.Buffer{}); err == nil {
+		t.Errorf("problem with a soft clause should not have been written as DIMACS")
+	}
+	if err := New(HardPBConstr([]Lit{Var("a"), Var("b")}, []int{1, 2}, 2)).WriteDIMACS(&bytes.Buffer{}); err == nil {
+		t.Errorf("PB constraint should not have been written as DIMACS")
+	}
+}
+
+func ExampleProblem_WriteWCNF() {
+	pb := New(
+		HardClause(Var("a"), Var("b")),
+		WeightedClause([]Lit{Not("a")}, 2),
+		SoftClause(Not("b")),
+	)
+	if err := pb.WriteWCNF(os.Stdout); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// p wcnf 2 3 4
+	// 4 1 2 0
+	// 2 -1 0
+	// 1 -2 0
+}
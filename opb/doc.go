@@ -0,0 +1,19 @@
+// Package opb reads pseudo-boolean problems in the OPB and WBO formats of the pseudo-boolean competitions.
+//
+// An OPB file is a list of constraints, one per line, each ended by a ";", possibly preceded by an objective
+// starting with "min:" or "max:". Comments start with "*". A constraint is a sum of weighted terms, an operator
+// (">=", "<=" or "=") and an integer, e.g
+//
+//	+2 x1 -3 ~x2 >= 1 ;
+//
+// where "~x2" is the negation of the variable x2. Terms of the non-linear categories are products of literals,
+// e.g "3 x1 x2" is 3 if both x1 and x2 are true, and 0 else.
+//
+// A WBO file describes a weighted boolean optimization problem. Its first line after the comments is "soft: top ;",
+// where top is optional. Soft constraints are then preceded by their weight between brackets, e.g "[5] +1 x1 +1 x2 >= 1 ;",
+// and constraints without a weight are hard. The cost of an assignment, i.e the sum of the weights of
+// the soft constraints it violates, must be minimized, and must be strictly lower than top.
+//
+// Normalize rewrites a problem so that it only contains linear ">=" constraints with positive coefficients,
+// as expected by the solvers of gophersat. Products are replaced by auxiliary variables.
+package opb
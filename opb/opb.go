@@ -0,0 +1,195 @@
+package opb
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Term is a product of literals, multiplied by a coefficient.
+// Literals are positive for variables, negative for negated variables.
+// Linear terms have a single literal.
+type Term struct {
+	Coeff int
+	Lits  []int
+}
+
+// A Constraint is a sum of terms, compared to an integer.
+type Constraint struct {
+	Terms  []Term
+	Op     string // ">=", "<=" or "="
+	RHS    int
+	Weight int // Weight of a soft constraint in a WBO problem, or 0 for a hard constraint
+}
+
+// An Objective is a sum of terms, to be minimized or maximized.
+type Objective struct {
+	Terms    []Term
+	Maximize bool
+	// Offset is a constant added to the sum of the terms, for normalized objectives.
+	Offset int
+}
+
+// Value returns the value of the objective as it was written, once normalized, given the cost of an assignment,
+// i.e the sum of the coefficients of the terms of the normalized objective that are true.
+func (o Objective) Value(cost int) int {
+	if o.Maximize {
+		return -(cost + o.Offset)
+	}
+	return cost + o.Offset
+}
+
+// A Problem is a pseudo-boolean problem.
+type Problem struct {
+	NbVars      int // Number of variables, i.e the declared one or the biggest variable used
+	Objectives  []Objective
+	Constraints []Constraint
+	WBO         bool // Is the problem a WBO problem, i.e does it start with a "soft:" line?
+	Top         int  // For WBO problems, the upper bound of the cost, or 0 if there is none
+	normalized  bool
+}
+
+// Normalize rewrites pb so that it only contains linear ">=" constraints with positive coefficients,
+// where each variable appears at most once.
+// Each product of several literals is replaced by a new variable, that is defined by hard clauses as being equal
+// to the product. Equalities are replaced by two inequalities. Constraints that are trivially satisfied are removed.
+// Objectives are rewritten as minimizations: their Offset is set so that their Value
+// can be computed from the cost of an assignment. Normalizing a problem twice has no effect.
+func (pb *Problem) Normalize() {
+	if pb.normalized {
+		return
+	}
+	pb.normalized = true
+	n := normalizer{pb: pb, products: make(map[string]int)}
+	var constrs []Constraint
+	for _, c := range pb.Constraints {
+		lits, coeffs := n.linearize(c.Terms, 1)
+		switch c.Op {
+		case ">=":
+			constrs = n.appendGtEq(constrs, lits, coeffs, c.RHS, c.Weight)
+		case "<=":
+			constrs = n.appendGtEq(constrs, lits, negate(coeffs), -c.RHS, c.Weight)
+		default:
+			constrs = n.appendGtEq(constrs, lits, coeffs, c.RHS, c.Weight)
+			constrs = n.appendGtEq(constrs, lits, negate(coeffs), -c.RHS, c.Weight)
+		}
+	}
+	for i, o := range pb.Objectives {
+		sign := 1
+		if o.Maximize {
+			sign = -1
+		}
+		lits, coeffs := n.linearize(o.Terms, sign)
+		terms, offset := normalizeTerms(lits, coeffs)
+		pb.Objectives[i] = Objective{Terms: terms, Maximize: o.Maximize, Offset: o.Offset + offset}
+	}
+	pb.Constraints = append(n.defs, constrs...)
+}
+
+func negate(coeffs []int) []int {
+	res := make([]int, len(coeffs))
+	for i, c := range coeffs {
+		res[i] = -c
+	}
+	return res
+}
+
+// A normalizer holds the auxiliary variables introduced while normalizing a problem.
+type normalizer struct {
+	pb       *Problem
+	products map[string]int // Variable associated with each product of literals, indexed by its sorted literals
+	defs     []Constraint   // Clauses defining auxiliary variables
+}
+
+// linearize returns the literal and coefficient of each term, multiplied by sign.
+// Terms that are always false, because they contain a literal and its negation, are ignored.
+func (n *normalizer) linearize(terms []Term, sign int) (lits, coeffs []int) {
+	for _, t := range terms {
+		if lit := n.product(t.Lits); lit != 0 {
+			lits = append(lits, lit)
+			coeffs = append(coeffs, sign*t.Coeff)
+		}
+	}
+	return lits, coeffs
+}
+
+// product returns a literal equal to the product of lits, or 0 if the product is always false.
+func (n *normalizer) product(lits []int) int {
+	if len(lits) == 1 {
+		return lits[0]
+	}
+	sorted := make([]int, 0, len(lits))
+	seen := make(map[int]bool, len(lits))
+	for _, lit := range lits {
+		if seen[-lit] {
+			return 0
+		}
+		if !seen[lit] {
+			seen[lit] = true
+			sorted = append(sorted, lit)
+		}
+	}
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	sort.Ints(sorted)
+	strs := make([]string, len(sorted))
+	for i, lit := range sorted {
+		strs[i] = strconv.Itoa(lit)
+	}
+	key := strings.Join(strs, " ")
+	if v, ok := n.products[key]; ok {
+		return v
+	}
+	n.pb.NbVars++
+	v := n.pb.NbVars
+	n.products[key] = v
+	// v -> lit for each lit, and (lit1 & lit2 & ...) -> v
+	back := Constraint{Terms: []Term{{Coeff: 1, Lits: []int{v}}}, Op: ">=", RHS: 1}
+	for _, lit := range sorted {
+		n.defs = append(n.defs, Constraint{Terms: []Term{{Coeff: 1, Lits: []int{-v}}, {Coeff: 1, Lits: []int{lit}}}, Op: ">=", RHS: 1})
+		back.Terms = append(back.Terms, Term{Coeff: 1, Lits: []int{-lit}})
+	}
+	n.defs = append(n.defs, back)
+	return v
+}
+
+// appendGtEq appends to constrs the normalized constraint stating the weighted sum of lits is at least rhs,
+// unless it is trivially satisfied.
+func (n *normalizer) appendGtEq(constrs []Constraint, lits, coeffs []int, rhs, weight int) []Constraint {
+	terms, offset := normalizeTerms(lits, coeffs)
+	if rhs -= offset; rhs <= 0 {
+		return constrs
+	}
+	return append(constrs, Constraint{Terms: terms, Op: ">=", RHS: rhs, Weight: weight})
+}
+
+// normalizeTerms returns terms with positive coefficients such that the weighted sum of lits
+// is the sum of the terms plus offset.
+// Each variable appears at most once in the resulting terms, in the order of its first appearance in lits.
+func normalizeTerms(lits, coeffs []int) (terms []Term, offset int) {
+	var vars []int
+	sums := make(map[int]int) // Coefficient of each variable, as a positive literal
+	for i, lit := range lits {
+		v, c := lit, coeffs[i]
+		if lit < 0 { // c.~v = c - c.v
+			v = -lit
+			offset += c
+			c = -c
+		}
+		if _, ok := sums[v]; !ok {
+			vars = append(vars, v)
+		}
+		sums[v] += c
+	}
+	for _, v := range vars {
+		switch c := sums[v]; {
+		case c > 0:
+			terms = append(terms, Term{Coeff: c, Lits: []int{v}})
+		case c < 0: // c.v = c + (-c).~v
+			offset += c
+			terms = append(terms, Term{Coeff: -c, Lits: []int{-v}})
+		}
+	}
+	return terms, offset
+}
@@ -0,0 +1,221 @@
+package opb
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const content = `* #variable= 4 #constraint= 3
+max: +2 x1 x2 -1 ~x3 ;
+3 x1 x2 +2 ~x3 >= 2 ;
++1 x1 +1 x2 <= 1 ;
+x1 x3 ~x2 = 0 ;
+`
+	pb, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("could not parse problem: %v", err)
+	}
+	if pb.NbVars != 4 {
+		t.Errorf("expected 4 vars, got %d", pb.NbVars)
+	}
+	if pb.WBO {
+		t.Errorf("problem was parsed as a WBO problem")
+	}
+	if len(pb.Objectives) != 1 || !pb.Objectives[0].Maximize {
+		t.Fatalf("expected a single max objective, got %v", pb.Objectives)
+	}
+	if got := fmt.Sprint(pb.Objectives[0].Terms); got != "[{2 [1 2]} {-1 [-3]}]" {
+		t.Errorf("invalid objective terms %s", got)
+	}
+	if len(pb.Constraints) != 3 {
+		t.Fatalf("expected 3 constraints, got %d", len(pb.Constraints))
+	}
+	ops := []string{">=", "<=", "="}
+	for i, c := range pb.Constraints {
+		if c.Op != ops[i] {
+			t.Errorf("constraint %d: expected operator %q, got %q", i, ops[i], c.Op)
+		}
+	}
+	if got := fmt.Sprint(pb.Constraints[2].Terms); got != "[{1 [1 3 -2]}]" {
+		t.Errorf("invalid terms for constraint without coefficient: %s", got)
+	}
+}
+
+func TestParseWBO(t *testing.T) {
+	const content = `* #variable= 2 #constraint= 2 #soft= 1
+soft: 5 ;
+[3] +1 x1 +1 x2 >= 1 ;
+-1 x1 -1 x2 >= -1 ;
+`
+	pb, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("could not parse problem: %v", err)
+	}
+	if !pb.WBO || pb.Top != 5 {
+		t.Errorf("expected WBO problem with top 5, got WBO=%t, top=%d", pb.WBO, pb.Top)
+	}
+	if len(pb.Constraints) != 2 || pb.Constraints[0].Weight != 3 || pb.Constraints[1].Weight != 0 {
+		t.Errorf("invalid constraints %v", pb.Constraints)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{"+1 x1 >= 1\n", "line 1: missing final \";\""},
+		{"+1 x1 > 1 ;\n", "line 1: invalid operator \">\""},
+		{"+1 x1 >= a ;\n", "line 1: invalid right-hand side \"a\""},
+		{"+1 y1 >= 1 ;\n", "line 1: invalid literal \"y1\""},
+		{"+1 x0 >= 1 ;\n", "line 1: invalid literal \"x0\""},
+		{"* comment\n+1 x1 +2 >= 1 ;\n", "line 2: missing literal after coefficient \"+2\""},
+		{"[2] +1 x1 >= 1 ;\n", "line 1: soft constraint in a problem without a \"soft:\" line"},
+		{"soft: ;\n[0] +1 x1 >= 1 ;\n", "line 2: invalid weight \"[0]\""},
+		{"+1 x1 >= 1 ;\nsoft: ;\n", "line 2: \"soft:\" line after constraints"},
+		{"soft: 1 2 ;\n", "line 1: expected top cost"},
+		{";\n", "line 1: empty statement"},
+	}
+	for _, test := range tests {
+		_, err := Parse(strings.NewReader(test.content))
+		if err == nil {
+			t.Errorf("parsing %q: expected error %q, got nil", test.content, test.err)
+		} else if !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("parsing %q: expected error %q, got %q", test.content, test.err, err)
+		}
+	}
+}
+
+// value returns the value of terms under the given assignment, where assign[v] is the binding of var v.
+func value(terms []Term, assign []bool) int {
+	res := 0
+	for _, t := range terms {
+		prod := true
+		for _, lit := range t.Lits {
+			if lit > 0 {
+				prod = prod && assign[lit]
+			} else {
+				prod = prod && !assign[-lit]
+			}
+		}
+		if prod {
+			res += t.Coeff
+		}
+	}
+	return res
+}
+
+func holds(c Constraint, assign []bool) bool {
+	switch val := value(c.Terms, assign); c.Op {
+	case ">=":
+		return val >= c.RHS
+	case "<=":
+		return val <= c.RHS
+	default:
+		return val == c.RHS
+	}
+}
+
+func randomTerms(r *rand.Rand, nbVars int) []Term {
+	terms := make([]Term, 1+r.Intn(4))
+	for i := range terms {
+		terms[i].Coeff = r.Intn(7) - 3
+		for j := 0; j < 1+r.Intn(3); j++ {
+			lit := 1 + r.Intn(nbVars)
+			if r.Intn(2) == 0 {
+				lit = -lit
+			}
+			terms[i].Lits = append(terms[i].Lits, lit)
+		}
+	}
+	return terms
+}
+
+// Checks that normalized problems accept the same assignments as the original ones, with the same objective values.
+func TestNormalize(t *testing.T) {
+	const nbVars = 4
+	r := rand.New(rand.NewSource(1))
+	ops := []string{">=", "<=", "="}
+	for iter := 0; iter < 200; iter++ {
+		var orig Problem
+		orig.NbVars = nbVars
+		orig.Objectives = []Objective{{Terms: randomTerms(r, nbVars), Maximize: r.Intn(2) == 0}}
+		for i := 0; i < 1+r.Intn(3); i++ {
+			c := Constraint{Terms: randomTerms(r, nbVars), Op: ops[r.Intn(len(ops))], RHS: r.Intn(7) - 3}
+			orig.Constraints = append(orig.Constraints, c)
+		}
+		pb := orig
+		pb.Objectives = append([]Objective(nil), orig.Objectives...)
+		pb.Normalize()
+		for _, c := range pb.Constraints {
+			if c.Op != ">=" || c.RHS <= 0 {
+				t.Fatalf("invalid normalized constraint %v", c)
+			}
+			for _, term := range c.Terms {
+				if term.Coeff <= 0 || len(term.Lits) != 1 {
+					t.Fatalf("invalid term %v in normalized constraint %v", term, c)
+				}
+			}
+		}
+		// For each assignment of the original vars, there must be exactly one extension satisfying the
+		// definitions of the auxiliary vars.
+		found := make(map[int]int) // For each accepted assignment of the original vars, the objective value
+		assign := make([]bool, pb.NbVars+1)
+		for mask := 0; mask < 1<<uint(pb.NbVars); mask++ {
+			for v := 1; v <= pb.NbVars; v++ {
+				assign[v] = mask&(1<<uint(v-1)) != 0
+			}
+			ok := true
+			for _, c := range pb.Constraints {
+				ok = ok && holds(c, assign)
+			}
+			if ok {
+				orig := mask & (1<<nbVars - 1)
+				if _, dup := found[orig]; dup {
+					t.Fatalf("iter %d: assignment %b accepted twice", iter, orig)
+				}
+				found[orig] = pb.Objectives[0].Value(value(pb.Objectives[0].Terms, assign))
+			}
+		}
+		for mask := 0; mask < 1<<nbVars; mask++ {
+			for v := 1; v <= nbVars; v++ {
+				assign[v] = mask&(1<<uint(v-1)) != 0
+			}
+			ok := true
+			for _, c := range orig.Constraints {
+				ok = ok && holds(c, assign)
+			}
+			val, accepted := found[mask]
+			if ok != accepted {
+				t.Fatalf("iter %d: assignment %b: expected accepted=%t, got %t", iter, mask, ok, accepted)
+			}
+			if expected := value(orig.Objectives[0].Terms, assign); ok && val != expected {
+				t.Fatalf("iter %d: assignment %b: expected objective value %d, got %d", iter, mask, expected, val)
+			}
+		}
+	}
+}
+
+func ExampleProblem_Normalize() {
+	pb, err := Parse(strings.NewReader("max: +2 x1 x2 ;\n+1 x1 +1 x2 <= 1 ;\n"))
+	if err != nil {
+		fmt.Printf("could not parse problem: %v\n", err)
+		return
+	}
+	pb.Normalize()
+	fmt.Println(pb.NbVars)
+	for _, c := range pb.Constraints {
+		fmt.Println(c.Terms, c.Op, c.RHS)
+	}
+	fmt.Println(pb.Objectives[0].Terms, pb.Objectives[0].Offset)
+	// Output:
+	// 3
+	// [{1 [-3]} {1 [1]}] >= 1
+	// [{1 [-3]} {1 [2]}] >= 1
+	// [{1 [3]} {1 [-1]} {1 [-2]}] >= 1
+	// [{1 [-1]} {1 [-2]}] >= 1
+	// [{2 [-3]}] -2
+}
@@ -0,0 +1,190 @@
+package opb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/DoOR-Team/gophersat/input"
+)
+
+// maxLineLen is the maximal length of a line in an OPB file.
+const maxLineLen = 1 << 30
+
+// Parse parses a problem in the OPB or WBO format from r, possibly compressed.
+// The problem is returned as written: it must be normalized before being solved.
+func Parse(r io.Reader) (*Problem, error) {
+	r, err := input.Decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxLineLen)
+	var (
+		pb     Problem
+		lineNb int
+	)
+	for sc.Scan() {
+		lineNb++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if line[0] == '*' {
+			if lineNb == 1 {
+				pb.NbVars = declaredVars(line)
+			}
+			continue
+		}
+		if err := pb.parseLine(line); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNb, err)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("could not read problem: %v", err)
+	}
+	return &pb, nil
+}
+
+// declaredVars returns the number of variables declared in the header comment of the problem, or 0 if there is none.
+func declaredVars(line string) int {
+	fields := strings.Fields(line)
+	for i := 0; i < len(fields)-1; i++ {
+		if fields[i] == "#variable=" {
+			if nb, err := strconv.Atoi(fields[i+1]); err == nil && nb > 0 {
+				return nb
+			}
+		}
+	}
+	return 0
+}
+
+// parseLine parses a line that is not a comment.
+func (pb *Problem) parseLine(line string) error {
+	if !strings.HasSuffix(line, ";") {
+		return fmt.Errorf("missing final \";\" in %q", line)
+	}
+	fields := strings.Fields(line[:len(line)-1])
+	if len(fields) == 0 {
+		return fmt.Errorf("empty statement")
+	}
+	switch fields[0] {
+	case "soft:":
+		return pb.parseSoft(fields[1:])
+	case "min:", "max:":
+		terms, err := pb.parseTerms(fields[1:])
+		if err != nil {
+			return err
+		}
+		pb.Objectives = append(pb.Objectives, Objective{Terms: terms, Maximize: fields[0] == "max:"})
+		return nil
+	}
+	var c Constraint
+	if strings.HasPrefix(fields[0], "[") {
+		if !pb.WBO {
+			return fmt.Errorf("soft constraint in a problem without a \"soft:\" line")
+		}
+		if !strings.HasSuffix(fields[0], "]") {
+			return fmt.Errorf("invalid weight %q", fields[0])
+		}
+		w, err := strconv.Atoi(fields[0][1 : len(fields[0])-1])
+		if err != nil || w <= 0 {
+			return fmt.Errorf("invalid weight %q", fields[0])
+		}
+		c.Weight = w
+		fields = fields[1:]
+	}
+	if len(fields) < 2 {
+		return fmt.Errorf("expected operator and right-hand side in %q", line)
+	}
+	c.Op = fields[len(fields)-2]
+	if c.Op != ">=" && c.Op != "<=" && c.Op != "=" {
+		return fmt.Errorf("invalid operator %q: expected \">=\", \"<=\" or \"=\"", c.Op)
+	}
+	rhs, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return fmt.Errorf("invalid right-hand side %q", fields[len(fields)-1])
+	}
+	c.RHS = rhs
+	if c.Terms, err = pb.parseTerms(fields[:len(fields)-2]); err != nil {
+		return err
+	}
+	pb.Constraints = append(pb.Constraints, c)
+	return nil
+}
+
+// parseSoft parses the fields of a "soft:" line, after the keyword.
+func (pb *Problem) parseSoft(fields []string) error {
+	if pb.WBO {
+		return fmt.Errorf("duplicate \"soft:\" line")
+	}
+	if len(pb.Constraints) != 0 || len(pb.Objectives) != 0 {
+		return fmt.Errorf("\"soft:\" line after constraints")
+	}
+	pb.WBO = true
+	switch len(fields) {
+	case 0:
+		return nil
+	case 1:
+		top, err := strconv.Atoi(fields[0])
+		if err != nil || top <= 0 {
+			return fmt.Errorf("invalid top cost %q", fields[0])
+		}
+		pb.Top = top
+		return nil
+	default:
+		return fmt.Errorf("expected top cost, found %q", strings.Join(fields, " "))
+	}
+}
+
+// parseTerms parses a sum of terms. Each term is a coefficient followed by one or more literals.
+// For compatibility with older files, the coefficient can be omitted, in which case it is 1.
+func (pb *Problem) parseTerms(fields []string) ([]Term, error) {
+	var terms []Term
+	for i := 0; i < len(fields); {
+		t := Term{Coeff: 1}
+		if coeff, err := strconv.Atoi(fields[i]); err == nil {
+			t.Coeff = coeff
+			i++
+		}
+		for i < len(fields) && isLit(fields[i]) {
+			lit, err := pb.parseLit(fields[i])
+			if err != nil {
+				return nil, err
+			}
+			t.Lits = append(t.Lits, lit)
+			i++
+		}
+		if len(t.Lits) == 0 {
+			if i < len(fields) {
+				return nil, fmt.Errorf("invalid literal %q", fields[i])
+			}
+			return nil, fmt.Errorf("missing literal after coefficient %q", fields[i-1])
+		}
+		terms = append(terms, t)
+	}
+	return terms, nil
+}
+
+// isLit returns true iff field looks like a literal, i.e starts with "x" or "~x".
+func isLit(field string) bool {
+	return strings.HasPrefix(field, "x") || strings.HasPrefix(field, "~x")
+}
+
+// parseLit parses a literal, "x1" or "~x1", and updates the number of variables if needed.
+func (pb *Problem) parseLit(field string) (int, error) {
+	name := strings.TrimPrefix(field, "~")
+	v, err := strconv.Atoi(name[1:])
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid literal %q", field)
+	}
+	if v > pb.NbVars {
+		pb.NbVars = v
+	}
+	if name != field {
+		return -v, nil
+	}
+	return v, nil
+}
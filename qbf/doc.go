@@ -0,0 +1,18 @@
+// Package qbf solves quantified boolean formulas in prenex CNF form, as described in the QDIMACS format.
+//
+// A QBF is a CNF preceded by a prefix of quantified blocks of variables, e.g
+//
+//	∃x1 x2 ∀y1 y2 ∃z (x1 ∨ y1 ∨ z) ∧ (¬x2 ∨ ¬z) ∧ ...
+//
+// which is true iff there is an assignment of x1 and x2 such that, for all assignments of y1 and y2,
+// there is an assignment of z satisfying the CNF. Such problems typically arise in synthesis:
+// "is there a configuration such that, for all inputs, the specification holds?".
+//
+// Problems are solved by counterexample-guided abstraction refinement. For a 2QBF ∃X ∀Y φ,
+// one incremental SAT solver proposes candidate assignments of X, and another one looks for
+// an assignment of Y falsifying φ under that candidate. Each counterexample is then added to the first solver,
+// as the clauses of φ where Y is replaced by its counterexample value, until either no candidate
+// remains (the formula is false) or no counterexample is found (the formula is true, and the candidate
+// is a winning assignment). Problems with more quantifier blocks are solved by applying the same idea
+// recursively, inner blocks being expanded with fresh copies of their variables.
+package qbf
@@ -0,0 +1,284 @@
+package qbf
+
+import "github.com/DoOR-Team/gophersat/solver"
+
+// A node is a formula in negation normal form: a literal, a conjunction or a disjunction.
+// The empty conjunction is true, the empty disjunction is false.
+type node struct {
+	lit  int  // If not 0, the node is this literal
+	and  bool // Else, is the node a conjunction or a disjunction of its subformulas?
+	subs []*node
+}
+
+var (
+	trueNode  = &node{and: true}
+	falseNode = &node{and: false}
+)
+
+func (n *node) isConst(val bool) bool {
+	return n.lit == 0 && len(n.subs) == 0 && n.and == val
+}
+
+// cnfNode returns the node associated with the given clauses.
+func cnfNode(clauses [][]int) *node {
+	res := &node{and: true, subs: make([]*node, len(clauses))}
+	for i, clause := range clauses {
+		c := &node{and: false, subs: make([]*node, len(clause))}
+		for j, l := range clause {
+			c.subs[j] = &node{lit: l}
+		}
+		res.subs[i] = c
+	}
+	return res
+}
+
+// negation returns the negation of n, in negation normal form.
+func (n *node) negation() *node {
+	if n.lit != 0 {
+		return &node{lit: -n.lit}
+	}
+	res := &node{and: !n.and, subs: make([]*node, len(n.subs))}
+	for i, sub := range n.subs {
+		res.subs[i] = sub.negation()
+	}
+	return res
+}
+
+// subst returns n, where variables in vals are replaced by their value, and variables in rename are renamed.
+// Constants are then propagated, so the result is either a constant or a formula without constants.
+func (n *node) subst(vals map[int]bool, rename map[int]int) *node {
+	if n.lit != 0 {
+		v := abs(n.lit)
+		if val, ok := vals[v]; ok {
+			if val == (n.lit > 0) {
+				return trueNode
+			}
+			return falseNode
+		}
+		if v2, ok := rename[v]; ok {
+			if n.lit < 0 {
+				return &node{lit: -v2}
+			}
+			return &node{lit: v2}
+		}
+		return n
+	}
+	res := &node{and: n.and}
+	for _, sub := range n.subs {
+		sub = sub.subst(vals, rename)
+		switch {
+		case sub.isConst(!n.and): // Absorbing element
+			return sub
+		case sub.isConst(n.and): // Neutral element
+		default:
+			res.subs = append(res.subs, sub)
+		}
+	}
+	if len(res.subs) == 1 {
+		return res.subs[0]
+	}
+	return res
+}
+
+// A game is a QBF whose matrix is not necessarily in CNF.
+// Its prefix is made of non-empty, alternating blocks, except its first block, that can be empty.
+type game struct {
+	prefix []Block
+	matrix *node
+	nbVars *int // Number of variables used so far, shared by all games derived from the same problem
+}
+
+// negation returns the negation of g: all quantifiers are inverted, and the matrix is negated.
+func (g game) negation() game {
+	prefix := make([]Block, len(g.prefix))
+	for i, b := range g.prefix {
+		prefix[i] = b
+		if b.Quant == Exists {
+			prefix[i].Quant = Forall
+		} else {
+			prefix[i].Quant = Exists
+		}
+	}
+	return game{prefix: prefix, matrix: g.matrix.negation(), nbVars: g.nbVars}
+}
+
+// solve returns true iff g, whose first block is existential, is true.
+// In that case, it also returns the value of the variables of the first block in a winning move.
+func (g game) solve() (bool, map[int]bool) {
+	switch len(g.prefix) {
+	case 1:
+		s := newSatSolver()
+		s.assert(g.matrix)
+		if s.s.SolveAssumptions(nil) != solver.Sat {
+			return false, nil
+		}
+		return true, s.values(g.prefix[0].Vars)
+	case 2:
+		return g.solve2()
+	default:
+		return g.solveRec()
+	}
+}
+
+// solve2 solves a 2QBF ∃X ∀Y φ, with one incremental solver for candidates of X, and another one
+// looking for counterexamples, i.e values of Y falsifying φ for the current candidate.
+func (g game) solve2() (bool, map[int]bool) {
+	xs, ys := g.prefix[0].Vars, g.prefix[1].Vars
+	cand := newSatSolver()
+	for _, x := range xs {
+		cand.lit(x)
+	}
+	counter := newSatSolver()
+	counter.assert(g.matrix.negation())
+	for {
+		if cand.s.SolveAssumptions(nil) != solver.Sat {
+			return false, nil
+		}
+		move := cand.values(xs)
+		assumps := make([]solver.Lit, len(xs))
+		for i, x := range xs {
+			assumps[i] = counter.lit(x)
+			if !move[x] {
+				assumps[i] = assumps[i].Negation()
+			}
+		}
+		if counter.s.SolveAssumptions(assumps) != solver.Sat {
+			return true, move
+		}
+		cand.assert(g.matrix.subst(counter.values(ys), nil))
+	}
+}
+
+// solveRec solves a game ∃X ∀Y G, where G is a game with at least one block.
+// The abstraction ∃X G[Y:=μ1] ∧ ... ∧ G[Y:=μn], where the μi are the counter moves found so far
+// and the variables of G are renamed in each copy, is solved recursively. Its winning move for X is a
+// candidate; the game ∃Y ¬G[X:=candidate] is then solved recursively to find a new counter move.
+func (g game) solveRec() (bool, map[int]bool) {
+	xs, ys := g.prefix[0].Vars, g.prefix[1].Vars
+	inner := g.prefix[2:]
+	abstr := game{prefix: []Block{{Quant: Exists, Vars: append([]int{}, xs...)}}, matrix: trueNode, nbVars: g.nbVars}
+	var conjuncts []*node
+	for {
+		win, move := abstr.solve()
+		if !win {
+			return false, nil
+		}
+		cand := make(map[int]bool, len(xs))
+		for _, x := range xs {
+			cand[x] = move[x]
+		}
+		counter := game{prefix: g.prefix[1:], matrix: g.matrix.subst(cand, nil), nbVars: g.nbVars}.negation()
+		win, counterMove := counter.solve()
+		if !win {
+			return true, cand
+		}
+		vals := make(map[int]bool, len(ys))
+		for _, y := range ys {
+			vals[y] = counterMove[y]
+		}
+		// Expansion of the inner game, with fresh copies of its variables.
+		// Its first block is existential, so it is merged with X, and so on.
+		rename := make(map[int]int)
+		for i, b := range inner {
+			vars := make([]int, len(b.Vars))
+			for j, v := range b.Vars {
+				*g.nbVars++
+				rename[v] = *g.nbVars
+				vars[j] = *g.nbVars
+			}
+			if i < len(abstr.prefix) {
+				abstr.prefix[i].Vars = append(abstr.prefix[i].Vars, vars...)
+			} else {
+				abstr.prefix = append(abstr.prefix, Block{Quant: b.Quant, Vars: vars})
+			}
+		}
+		conjuncts = append(conjuncts, g.matrix.subst(vals, rename))
+		abstr.matrix = &node{and: true, subs: conjuncts}
+	}
+}
+
+// A satSolver wraps an incremental solver, where clauses are added as nodes.
+type satSolver struct {
+	s    *solver.Solver
+	vars map[int]solver.Var // Solver var associated with each variable of the problem
+}
+
+func newSatSolver() *satSolver {
+	return &satSolver{s: solver.New(solver.ParseSliceNb(nil, 0)), vars: make(map[int]solver.Var)}
+}
+
+// lit returns the solver literal associated with the given DIMACS literal.
+func (s *satSolver) lit(l int) solver.Lit {
+	v, ok := s.vars[abs(l)]
+	if !ok {
+		v = s.s.NewVar()
+		s.vars[abs(l)] = v
+	}
+	return v.SignedLit(l < 0)
+}
+
+// values returns the values of the given variables in the last model found.
+func (s *satSolver) values(vars []int) map[int]bool {
+	model := s.s.Model()
+	res := make(map[int]bool, len(vars))
+	for _, v := range vars {
+		if sv, ok := s.vars[v]; ok && int(sv) < len(model) {
+			res[v] = model[sv]
+		}
+	}
+	return res
+}
+
+// add adds the given clause to the solver. Duplicate literals are removed, and tautologies ignored.
+func (s *satSolver) add(lits []solver.Lit) {
+	seen := make(map[solver.Lit]bool, len(lits))
+	clause := make([]solver.Lit, 0, len(lits))
+	for _, l := range lits {
+		if seen[l.Negation()] {
+			return
+		}
+		if !seen[l] {
+			seen[l] = true
+			clause = append(clause, l)
+		}
+	}
+	s.s.AppendClause(solver.NewClause(clause))
+}
+
+// assert adds the clauses stating n is true.
+func (s *satSolver) assert(n *node) {
+	switch {
+	case n.lit != 0:
+		s.add([]solver.Lit{s.lit(n.lit)})
+	case n.and:
+		for _, sub := range n.subs {
+			s.assert(sub)
+		}
+	default:
+		s.add(s.encodeAll(n.subs))
+	}
+}
+
+// encode returns a literal implying n, thanks to a Plaisted-Greenbaum transformation.
+func (s *satSolver) encode(n *node) solver.Lit {
+	if n.lit != 0 {
+		return s.lit(n.lit)
+	}
+	t := s.s.NewVar().Lit()
+	if n.and {
+		for _, sub := range n.subs {
+			s.add([]solver.Lit{t.Negation(), s.encode(sub)})
+		}
+	} else {
+		s.add(append([]solver.Lit{t.Negation()}, s.encodeAll(n.subs)...))
+	}
+	return t
+}
+
+func (s *satSolver) encodeAll(subs []*node) []solver.Lit {
+	res := make([]solver.Lit, len(subs))
+	for i, sub := range subs {
+		res[i] = s.encode(sub)
+	}
+	return res
+}
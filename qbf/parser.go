@@ -0,0 +1,107 @@
+package qbf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/DoOR-Team/gophersat/input"
+)
+
+// ParseQDIMACS parses a problem in the QDIMACS format, i.e a DIMACS CNF whose clauses are preceded by
+// quantifier lines, starting with "e" (exists) or "a" (forall) followed by the quantified variables and a final 0.
+// A variable cannot be quantified more than once.
+func ParseQDIMACS(r io.Reader) (*Problem, error) {
+	r, err := input.Decompress(r)
+	if err != nil {
+		return nil, err
+	}
+	sc := bufio.NewScanner(r)
+	var (
+		pb         Problem
+		header     bool
+		nbClauses  int
+		quantified = make(map[int]bool)
+		lineNb     int
+	)
+	for sc.Scan() {
+		lineNb++
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if !header {
+			if fields[0] != "p" {
+				return nil, fmt.Errorf("line %d: expected header, found %q", lineNb, fields[0])
+			}
+			if len(fields) != 4 || fields[1] != "cnf" {
+				return nil, fmt.Errorf("line %d: invalid header %q", lineNb, sc.Text())
+			}
+			var err error
+			if pb.NbVars, err = strconv.Atoi(fields[2]); err != nil || pb.NbVars < 0 {
+				return nil, fmt.Errorf("line %d: invalid number of variables %q", lineNb, fields[2])
+			}
+			if nbClauses, err = strconv.Atoi(fields[3]); err != nil || nbClauses < 0 {
+				return nil, fmt.Errorf("line %d: invalid number of clauses %q", lineNb, fields[3])
+			}
+			header = true
+			continue
+		}
+		switch fields[0] {
+		case "p":
+			return nil, fmt.Errorf("line %d: duplicate header", lineNb)
+		case "e", "a":
+			if len(pb.Clauses) != 0 {
+				return nil, fmt.Errorf("line %d: quantifier after clauses", lineNb)
+			}
+			vars, err := pb.parseInts(fields[1:], false)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNb, err)
+			}
+			for _, v := range vars {
+				if quantified[v] {
+					return nil, fmt.Errorf("line %d: variable %d quantified twice", lineNb, v)
+				}
+				quantified[v] = true
+			}
+			pb.Prefix = append(pb.Prefix, Block{Quant: Quantifier(fields[0][0]), Vars: vars})
+		default:
+			clause, err := pb.parseInts(fields, true)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", lineNb, err)
+			}
+			pb.Clauses = append(pb.Clauses, clause)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("could not read problem: %v", err)
+	}
+	if !header {
+		return nil, fmt.Errorf("missing header")
+	}
+	if len(pb.Clauses) != nbClauses {
+		return nil, fmt.Errorf("expected %d clauses, found %d", nbClauses, len(pb.Clauses))
+	}
+	return &pb, nil
+}
+
+// parseInts parses a list of variables (or literals, if lits is true) ending with 0.
+func (pb *Problem) parseInts(fields []string, lits bool) ([]int, error) {
+	if len(fields) == 0 || fields[len(fields)-1] != "0" {
+		return nil, fmt.Errorf("missing final 0")
+	}
+	res := make([]int, len(fields)-1)
+	for i, field := range fields[:len(fields)-1] {
+		val, err := strconv.Atoi(field)
+		if err != nil || val == 0 || (!lits && val < 0) {
+			return nil, fmt.Errorf("invalid value %q", field)
+		}
+		if abs(val) > pb.NbVars {
+			return nil, fmt.Errorf("invalid variable %d for problem with %d vars only", abs(val), pb.NbVars)
+		}
+		res[i] = val
+	}
+	return res, nil
+}
@@ -0,0 +1,118 @@
+package qbf
+
+import (
+	"fmt"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// A Quantifier is either Exists or Forall.
+type Quantifier byte
+
+const (
+	// Exists is the existential quantifier, written "e" in QDIMACS.
+	Exists Quantifier = 'e'
+	// Forall is the universal quantifier, written "a" in QDIMACS.
+	Forall Quantifier = 'a'
+)
+
+// String returns the QDIMACS name of the quantifier.
+func (q Quantifier) String() string {
+	switch q {
+	case Exists:
+		return "e"
+	case Forall:
+		return "a"
+	default:
+		return fmt.Sprintf("Quantifier(%d)", byte(q))
+	}
+}
+
+// A Block is a set of variables bound by the same quantifier.
+// Variables are numbered from 1, as in DIMACS.
+type Block struct {
+	Quant Quantifier
+	Vars  []int
+}
+
+// A Problem is a quantified boolean formula in prenex CNF form.
+// Variables that appear in the clauses but not in the prefix are free: they are existentially
+// quantified in an outermost block.
+type Problem struct {
+	NbVars  int     // Number of variables
+	Prefix  []Block // Quantified blocks, from the outermost to the innermost one
+	Clauses [][]int // Clauses of the matrix, as DIMACS clauses
+}
+
+// Solve returns solver.Sat if the formula is true, solver.Unsat else.
+// If the formula is true and its outermost block is existential (which is always the case when there are free variables),
+// it also returns a winning assignment of the variables of that block, as a list of DIMACS literals,
+// i.e whatever the values of the inner universal variables, the other existential variables can be assigned so
+// that the matrix is satisfied.
+func (pb *Problem) Solve() (solver.Status, []int) {
+	prefix := pb.normalizedPrefix()
+	nbVars := pb.NbVars
+	g := game{prefix: prefix, matrix: cnfNode(pb.Clauses), nbVars: &nbVars}
+	if prefix[0].Quant == Forall { // The formula is true iff its negation is false
+		if win, _ := g.negation().solve(); win {
+			return solver.Unsat, nil
+		}
+		return solver.Sat, nil
+	}
+	win, move := g.solve()
+	if !win {
+		return solver.Unsat, nil
+	}
+	res := make([]int, len(prefix[0].Vars))
+	for i, v := range prefix[0].Vars {
+		if move[v] {
+			res[i] = v
+		} else {
+			res[i] = -v
+		}
+	}
+	return solver.Sat, res
+}
+
+// normalizedPrefix returns the prefix of the problem, where free variables are added to an outermost existential block,
+// empty blocks are removed, and consecutive blocks with the same quantifier are merged.
+// The returned prefix is never empty, and its blocks are alternating.
+func (pb *Problem) normalizedPrefix() []Block {
+	bound := make(map[int]bool)
+	for _, b := range pb.Prefix {
+		for _, v := range b.Vars {
+			bound[v] = true
+		}
+	}
+	var free []int
+	seen := make(map[int]bool)
+	for _, clause := range pb.Clauses {
+		for _, l := range clause {
+			if v := abs(l); !bound[v] && !seen[v] {
+				seen[v] = true
+				free = append(free, v)
+			}
+		}
+	}
+	res := []Block{{Quant: Exists, Vars: free}}
+	for _, b := range pb.Prefix {
+		if len(b.Vars) == 0 {
+			continue
+		}
+		last := &res[len(res)-1]
+		if last.Quant == b.Quant || len(last.Vars) == 0 {
+			last.Quant = b.Quant
+			last.Vars = append(append([]int{}, last.Vars...), b.Vars...)
+		} else {
+			res = append(res, Block{Quant: b.Quant, Vars: append([]int{}, b.Vars...)})
+		}
+	}
+	return res
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
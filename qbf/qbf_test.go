@@ -0,0 +1,144 @@
+package qbf
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/DoOR-Team/gophersat/solver"
+)
+
+// bruteForce returns true iff the QBF made of the given normalized prefix and clauses is true,
+// given the values of the variables in vals.
+func bruteForce(prefix []Block, clauses [][]int, vals map[int]bool) bool {
+	if len(prefix) == 0 {
+		for _, clause := range clauses {
+			sat := false
+			for _, l := range clause {
+				if vals[abs(l)] == (l > 0) {
+					sat = true
+				}
+			}
+			if !sat {
+				return false
+			}
+		}
+		return true
+	}
+	b := prefix[0]
+	if len(b.Vars) == 0 {
+		return bruteForce(prefix[1:], clauses, vals)
+	}
+	v := b.Vars[0]
+	rest := append([]Block{{Quant: b.Quant, Vars: b.Vars[1:]}}, prefix[1:]...)
+	for _, val := range []bool{false, true} {
+		vals[v] = val
+		res := bruteForce(rest, clauses, vals)
+		delete(vals, v)
+		if res == (b.Quant == Exists) {
+			return res
+		}
+	}
+	return b.Quant == Forall
+}
+
+func randomProblem(r *rand.Rand) *Problem {
+	pb := &Problem{NbVars: 2 + r.Intn(7)}
+	perm := r.Perm(pb.NbVars)
+	for i := 0; i < len(perm); {
+		n := 1 + r.Intn(3)
+		if i+n > len(perm) {
+			n = len(perm) - i
+		}
+		b := Block{Quant: Exists}
+		if r.Intn(2) == 0 {
+			b.Quant = Forall
+		}
+		if r.Intn(5) != 0 { // Some variables are left free
+			for _, v := range perm[i : i+n] {
+				b.Vars = append(b.Vars, v+1)
+			}
+			pb.Prefix = append(pb.Prefix, b)
+		}
+		i += n
+	}
+	nbClauses := 1 + r.Intn(3*pb.NbVars)
+	for i := 0; i < nbClauses; i++ {
+		clause := make([]int, 1+r.Intn(4))
+		for j := range clause {
+			clause[j] = 1 + r.Intn(pb.NbVars)
+			if r.Intn(2) == 0 {
+				clause[j] = -clause[j]
+			}
+		}
+		pb.Clauses = append(pb.Clauses, clause)
+	}
+	return pb
+}
+
+func TestSolve(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 1000; iter++ {
+		pb := randomProblem(r)
+		prefix := pb.normalizedPrefix()
+		expected := bruteForce(prefix, pb.Clauses, make(map[int]bool))
+		status, move := pb.Solve()
+		if (status == solver.Sat) != expected {
+			t.Fatalf("iter %d: prefix %v, clauses %v: expected %t, got %v", iter, prefix, pb.Clauses, expected, status)
+		}
+		if status == solver.Sat && prefix[0].Quant == Exists {
+			vals := make(map[int]bool)
+			for _, l := range move {
+				vals[abs(l)] = l > 0
+			}
+			if len(move) != len(prefix[0].Vars) || !bruteForce(prefix[1:], pb.Clauses, vals) {
+				t.Fatalf("iter %d: prefix %v, clauses %v: invalid winning move %v", iter, prefix, pb.Clauses, move)
+			}
+		}
+	}
+}
+
+func TestParseQDIMACS(t *testing.T) {
+	valid := "c a comment\np cnf 3 2\ne 1 0\na 2 0\ne 3 0\n1 -2 3 0\n-3 2 0\n"
+	pb, err := ParseQDIMACS(strings.NewReader(valid))
+	if err != nil {
+		t.Fatalf("could not parse valid problem: %v", err)
+	}
+	if len(pb.Prefix) != 3 || pb.Prefix[1].Quant != Forall || len(pb.Clauses) != 2 {
+		t.Errorf("invalid problem parsed: %+v", pb)
+	}
+	for _, invalid := range []string{
+		"e 1 0\np cnf 1 0\n",
+		"p cnf 2 1\ne 1 0\ne 1 0\n1 0\n",
+		"p cnf 2 1\n1 0\ne 2 0\n",
+		"p cnf 2 1\ne 3 0\n1 0\n",
+		"p cnf 2 1\n1 2\n",
+		"p cnf 2 2\n1 2 0\n",
+	} {
+		if _, err := ParseQDIMACS(strings.NewReader(invalid)); err == nil {
+			t.Errorf("problem %q should not have been parsed", invalid)
+		}
+	}
+}
+
+func ExampleProblem_Solve() {
+	// Is there a value of x1 such that, for all values of y2, there is a value of z3 satisfying the clauses?
+	qdimacs := `p cnf 3 3
+e 1 0
+a 2 0
+e 3 0
+1 2 3 0
+-2 -3 0
+1 -3 0
+`
+	pb, err := ParseQDIMACS(strings.NewReader(qdimacs))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	status, move := pb.Solve()
+	fmt.Println(status, move)
+	// Output:
+	// SAT [1]
+}
@@ -0,0 +1,117 @@
+package solver
+
+// This file deals with incremental solving: adding variables to an existing solver,
+// and solving a problem under a set of assumptions.
+
+// NbVars returns the current number of variables in the solver.
+func (s *Solver) NbVars() int {
+	return s.nbVars
+}
+
+// NewVar adds a new, unbound variable to the solver and returns it.
+// This is useful when clauses referring to fresh variables (selectors, auxiliary variables
+// of an encoding, etc.) must be added with AppendClause once the solver was created.
+func (s *Solver) NewVar() Var {
+	v := Var(s.nbVars)
+	s.nbVars++
+	s.model = append(s.model, 0)
+	s.activity = append(s.activity, 0)
+	s.varQueue.activity = s.activity // The slice might have been reallocated
+	s.polarity = append(s.polarity, false)
+	s.assumptions = append(s.assumptions, false)
+	s.reason = append(s.reason, nil)
+	s.trailBuf = append(s.trailBuf, 0)
+	s.wl.wlistBin = append(s.wl.wlistBin, nil, nil)
+	s.wl.wlist = append(s.wl.wlist, nil, nil)
+	s.wl.wlistPb = append(s.wl.wlistPb, nil, nil)
+	s.varQueue.insert(int(v))
+	return v
+}
+
+// SolveAssumptions solves the problem, supposing all the given literals are true.
+// Contrary to Assume, assumptions are not added to the problem: they only hold during the current call,
+// so the same solver can be called several times in a row with different assumptions,
+// keeping all the clauses it learned in the meantime.
+// If the returned status is Unsat, FailedAssumptions returns the subset of the assumptions
+// that made the problem unsatisfiable. If that subset is empty, the problem is unsatisfiable
+// no matter the assumptions, and the solver should not be called anymore.
+func (s *Solver) SolveAssumptions(assumptions []Lit) Status {
+	s.failed = nil
+	if s.status == Unsat {
+		return Unsat
+	}
+	s.cleanupBindings(1)
+	s.assumps = assumptions
+	s.assumpLvls = s.assumpLvls[:0]
+	status := s.Solve()
+	s.assumps = nil
+	s.assumpLvls = s.assumpLvls[:0]
+	if s.failed != nil { // UNSAT is only due to assumptions: solver can still be used later
+		s.status = Indet
+	}
+	return status
+}
+
+// FailedAssumptions returns, after a call to SolveAssumptions that returned Unsat,
+// a subset of the assumptions that cannot be satisfied together with the problem.
+// This subset is not guaranteed to be minimal.
+// If the problem is UNSAT even without assumptions, nil is returned.
+func (s *Solver) FailedAssumptions() []Lit {
+	return s.failed
+}
+
+// nextLit returns the next literal to decide at level lvl.
+// Pending assumptions are decided first; once they are all satisfied, a literal is chosen
+// through the usual heuristic.
+// If one of the assumptions is already falsified, the assumptions responsible for it are
+// stored in s.failed and -1 is returned.
+func (s *Solver) nextLit(lvl decLevel) Lit {
+	for len(s.assumpLvls) < len(s.assumps) {
+		lit := s.assumps[len(s.assumpLvls)]
+		switch s.litStatus(lit) {
+		case Sat: // Already true: nothing to decide
+			s.assumpLvls = append(s.assumpLvls, lvl-1)
+		case Unsat:
+			s.failed = s.analyzeFinal(lit)
+			return -1
+		default:
+			s.assumpLvls = append(s.assumpLvls, lvl)
+			s.Stats.NbDecisions++
+			return lit
+		}
+	}
+	return s.chooseLit()
+}
+
+// analyzeFinal returns the list of assumptions that made the given assumption false.
+// lit itself is part of the returned list.
+// Since assumptions are decided before any other literal, all decision literals
+// met while going back through the trail are assumptions.
+func (s *Solver) analyzeFinal(lit Lit) []Lit {
+	res := []Lit{lit}
+	if abs(s.model[lit.Var()]) <= 1 { // Falsified at top level
+		return res
+	}
+	seen := make([]bool, s.nbVars)
+	seen[lit.Var()] = true
+	for i := len(s.trail) - 1; i >= 0; i-- {
+		l := s.trail[i]
+		v := l.Var()
+		if !seen[v] || abs(s.model[v]) <= 1 {
+			continue
+		}
+		reason := s.reason[v]
+		if reason == nil {
+			res = append(res, l)
+			continue
+		}
+		for j := 0; j < reason.Len(); j++ {
+			l2 := reason.Get(j)
+			// In clauses where cardinality > 1, some lits might be true in the reason clause: ignore them
+			if v2 := l2.Var(); v2 != v && abs(s.model[v2]) > 1 && s.litStatus(l2) == Unsat {
+				seen[v2] = true
+			}
+		}
+	}
+	return res
+}
@@ -3,6 +3,7 @@ package solver
 import (
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -10,6 +11,7 @@ import (
 type pbData struct {
 	weights []int  // weight of each literal. If nil, weights are all 1.
 	watched []bool // indices of watched literals.
+	card    int    // minimal cardinality. It is stored here because it can be too big for the clause's lbdValue.
 }
 
 // A Clause is a list of Lit, associated with possible data (for learned clauses).
@@ -18,7 +20,7 @@ type Clause struct {
 	// lbdValue's bits are as follow:
 	// leftmost bit: learned flag.
 	// second bit: locked flag (if learned).
-	// last 30 bits: LBD value (if learned) or minimal cardinality - 1 (if !learned).
+	// last 30 bits: LBD value (if learned) or minimal cardinality - 1 (if !learned and not a PB constraint).
 	// NOTE: actual cardinality is value + 1, since this is the default value and go defaults to 0.
 	lbdValue uint32
 	activity float32
@@ -68,14 +70,14 @@ func NewPBClause(lits []Lit, weights []int, card int) *Clause {
 	}
 	wl := &weightedLits{lits: lits, weights: weights}
 	sort.Sort(wl)
-	pbData := pbData{weights: weights, watched: make([]bool, len(lits))}
+	pbData := pbData{weights: weights, watched: make([]bool, len(lits)), card: card}
 	if pbData.weights == nil {
 		pbData.weights = make([]int, len(lits))
 		for i := range pbData.weights {
 			pbData.weights[i] = 1
 		}
 	}
-	return &Clause{lits: lits, lbdValue: uint32(card - 1), pbData: &pbData}
+	return &Clause{lits: lits, pbData: &pbData}
 }
 
 // NewLearnedClause returns a new clause marked as learned.
@@ -88,6 +90,9 @@ func (c *Clause) Cardinality() int {
 	if c.Learned() {
 		return 1
 	}
+	if c.pbData != nil {
+		return c.pbData.card
+	}
 	return int(c.lbdValue & ^bothMasks) + 1
 }
 
@@ -184,6 +189,13 @@ func (c *Clause) swap(i, j int) {
 // updateCardinality adds "add" to c's cardinality.
 // Must not be called on learned clauses!
 func (c *Clause) updateCardinality(add int) {
+	if c.pbData != nil {
+		c.pbData.card += add
+		if c.pbData.card < 1 {
+			c.pbData.card = 1
+		}
+		return
+	}
 	if add < 0 && uint32(-add) > c.lbdValue {
 		c.lbdValue = 0
 	} else {
@@ -216,11 +228,13 @@ func (c *Clause) Shrink(newLen int) {
 
 // CNF returns a DIMACS CNF representation of the clause.
 func (c *Clause) CNF() string {
-	res := ""
+	var b strings.Builder
 	for _, lit := range c.lits {
-		res += fmt.Sprintf("%d ", lit.Int())
+		b.WriteString(strconv.Itoa(int(lit.Int())))
+		b.WriteByte(' ')
 	}
-	return fmt.Sprintf("%s0", res)
+	b.WriteByte('0')
+	return b.String()
 }
 
 // PBString returns a string representation of c as a pseudo-boolean expression.
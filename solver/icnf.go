@@ -0,0 +1,143 @@
+package solver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// An ICNFReader reads an incremental problem in the iCNF format and feeds a single incremental solver with it.
+// The iCNF format is like the DIMACS CNF format, with a mandatory "p inccnf" header, optionally followed by
+// the number of variables and the number of clauses, but clauses are interleaved with queries: lines starting with "a", followed by a list of assumptions and a final 0.
+// Each query asks whether the clauses read so far are satisfiable under the given assumptions.
+// Lines are read lazily, so queries can be answered as soon as they are read, e.g when reading from an interactive input.
+type ICNFReader struct {
+	sc        *bufio.Scanner
+	s         *Solver
+	lineNb    int
+	header    bool // Was the header read?
+	nbVars    int  // Number of vars declared in the header, or -1
+	nbClauses int  // Number of clauses declared in the header, or -1
+	nbRead    int  // Number of clauses read so far
+}
+
+// NewICNFReader returns a reader reading the problem from r.
+func NewICNFReader(r io.Reader) *ICNFReader {
+	return &ICNFReader{sc: bufio.NewScanner(r), s: New(ParseSliceNb(nil, 0)), nbVars: -1, nbClauses: -1}
+}
+
+// Solver returns the solver containing all the clauses read so far.
+func (ir *ICNFReader) Solver() *Solver {
+	return ir.s
+}
+
+// Next reads the input up to the next query, adds the clauses it finds to the solver, and returns the assumptions of the query.
+// The caller is then expected to call ir.Solver().SolveAssumptions with them.
+// At the end of the input, it returns io.EOF. Clauses read after the last query are added to the solver nonetheless.
+// An error is returned if the header is missing or duplicated, or if the problem does not match the counts it declares.
+func (ir *ICNFReader) Next() ([]Lit, error) {
+	for ir.sc.Scan() {
+		ir.lineNb++
+		fields := strings.Fields(ir.sc.Text())
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+		if !ir.header {
+			if err := ir.parseHeader(fields); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		switch fields[0] {
+		case "p":
+			return nil, fmt.Errorf("line %d: duplicate header", ir.lineNb)
+		case "a":
+			assumps, err := ir.parseLits(fields[1:])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: could not parse assumptions: %v", ir.lineNb, err)
+			}
+			return assumps, nil
+		default:
+			lits, err := ir.parseLits(fields)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: could not parse clause: %v", ir.lineNb, err)
+			}
+			if ir.nbClauses >= 0 && ir.nbRead == ir.nbClauses {
+				return nil, fmt.Errorf("line %d: more clauses than the %d declared", ir.lineNb, ir.nbClauses)
+			}
+			ir.nbRead++
+			ir.addClause(lits)
+		}
+	}
+	if err := ir.sc.Err(); err != nil {
+		return nil, fmt.Errorf("could not read problem: %v", err)
+	}
+	if !ir.header {
+		return nil, fmt.Errorf("missing header")
+	}
+	if ir.nbClauses >= 0 && ir.nbRead != ir.nbClauses {
+		return nil, fmt.Errorf("expected %d clauses, found %d", ir.nbClauses, ir.nbRead)
+	}
+	return nil, io.EOF
+}
+
+// parseHeader parses the "p inccnf" header, possibly followed by the number of vars and clauses.
+func (ir *ICNFReader) parseHeader(fields []string) error {
+	if fields[0] != "p" {
+		return fmt.Errorf("line %d: expected header, found %q", ir.lineNb, fields[0])
+	}
+	if (len(fields) != 2 && len(fields) != 4) || fields[1] != "inccnf" {
+		return fmt.Errorf("line %d: invalid header %q", ir.lineNb, ir.sc.Text())
+	}
+	if len(fields) == 4 {
+		var err error
+		if ir.nbVars, err = strconv.Atoi(fields[2]); err != nil || ir.nbVars < 0 {
+			return fmt.Errorf("line %d: invalid number of variables %q", ir.lineNb, fields[2])
+		}
+		if ir.nbClauses, err = strconv.Atoi(fields[3]); err != nil || ir.nbClauses < 0 {
+			return fmt.Errorf("line %d: invalid number of clauses %q", ir.lineNb, fields[3])
+		}
+	}
+	ir.header = true
+	return nil
+}
+
+// parseLits parses a list of literals ending with 0. Variables that are not known yet are added to the solver.
+func (ir *ICNFReader) parseLits(fields []string) ([]Lit, error) {
+	if len(fields) == 0 || fields[len(fields)-1] != "0" {
+		return nil, fmt.Errorf("missing final 0")
+	}
+	lits := make([]Lit, len(fields)-1)
+	for i, field := range fields[:len(fields)-1] {
+		val, err := strconv.Atoi(field)
+		if err != nil || val == 0 {
+			return nil, fmt.Errorf("invalid literal %q", field)
+		}
+		if ir.nbVars >= 0 && (val > ir.nbVars || -val > ir.nbVars) {
+			return nil, fmt.Errorf("literal %d exceeds the %d declared variables", val, ir.nbVars)
+		}
+		lits[i] = IntToLit(int32(val))
+		for int(lits[i].Var()) >= ir.s.NbVars() {
+			ir.s.NewVar()
+		}
+	}
+	return lits, nil
+}
+
+// addClause adds the clause to the solver. Duplicate literals are removed, and tautologies are ignored.
+func (ir *ICNFReader) addClause(lits []Lit) {
+	seen := make(map[Lit]bool, len(lits))
+	clause := make([]Lit, 0, len(lits))
+	for _, lit := range lits {
+		if seen[lit.Negation()] {
+			return
+		}
+		if !seen[lit] {
+			seen[lit] = true
+			clause = append(clause, lit)
+		}
+	}
+	ir.s.AppendClause(NewClause(clause))
+}
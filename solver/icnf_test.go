@@ -0,0 +1,95 @@
+package solver
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestICNFReader(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 50; iter++ {
+		var (
+			b        strings.Builder
+			clauses  [][]int
+			expected []Status // Expected status of each query
+		)
+		b.WriteString("p inccnf\n")
+		nbVars := 5 + r.Intn(10)
+		for q := 0; q < 10; q++ {
+			for i := 0; i < 1+r.Intn(8); i++ {
+				clause := make([]int, 1+r.Intn(3))
+				for j := range clause {
+					clause[j] = 1 + r.Intn(nbVars)
+					if r.Intn(2) == 0 {
+						clause[j] = -clause[j]
+					}
+				}
+				clauses = append(clauses, clause)
+				b.WriteString(strings.Trim(fmt.Sprint(clause), "[]") + " 0\n")
+			}
+			assumps := make([]int, r.Intn(4))
+			for j := range assumps {
+				assumps[j] = 1 + r.Intn(nbVars)
+				if r.Intn(2) == 0 {
+					assumps[j] = -assumps[j]
+				}
+			}
+			b.WriteString("a " + strings.TrimSpace(strings.Trim(fmt.Sprint(assumps), "[]")+" 0") + "\n")
+			all := append([][]int{}, clauses...)
+			for _, a := range assumps {
+				all = append(all, []int{a})
+			}
+			expected = append(expected, New(ParseSliceNb(all, nbVars)).Solve())
+		}
+		ir := NewICNFReader(strings.NewReader(b.String()))
+		for q := 0; ; q++ {
+			assumps, err := ir.Next()
+			if err == io.EOF {
+				if q != len(expected) {
+					t.Fatalf("iter %d: expected %d queries, got %d", iter, len(expected), q)
+				}
+				break
+			}
+			if err != nil {
+				t.Fatalf("iter %d: could not read query %d: %v", iter, q, err)
+			}
+			if status := ir.Solver().SolveAssumptions(assumps); status != expected[q] {
+				t.Fatalf("iter %d, query %d: expected %v, got %v", iter, q, expected[q], status)
+			}
+		}
+	}
+}
+
+func TestICNFReaderErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{"p cnf 2 2\n", "line 1: invalid header"},
+		{"p inccnf\n1 2\n", "line 2: could not parse clause: missing final 0"},
+		{"p inccnf\na 1 x 0\n", "line 2: could not parse assumptions: invalid literal"},
+		{"p inccnf\n1 0 2 0\n", "line 2: could not parse clause: invalid literal"},
+		{"1 2 0\na 1 0\n", "line 1: expected header"},
+		{"c no header\n", "missing header"},
+		{"p inccnf\n1 2 0\np inccnf\n", "line 3: duplicate header"},
+		{"p inccnf 2 1\n1 2 0\n-1 0\n", "line 3: more clauses than the 1 declared"},
+		{"p inccnf 2 2\n1 2 0\n", "expected 2 clauses, found 1"},
+		{"p inccnf 2 1\n1 3 0\n", "line 2: could not parse clause: literal 3 exceeds the 2 declared variables"},
+		{"p inccnf x 1\n", "line 1: invalid number of variables"},
+	}
+	for _, test := range tests {
+		ir := NewICNFReader(strings.NewReader(test.content))
+		var err error
+		for err == nil {
+			_, err = ir.Next()
+		}
+		if err == io.EOF {
+			t.Errorf("problem %q should not have been read", test.content)
+		} else if !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("reading %q: expected error %q, got %q", test.content, test.err, err)
+		}
+	}
+}
@@ -5,10 +5,16 @@ package solver
 // This value is typically used in optimization processes.
 // If the weight is 0, that means all constraints could be solved.
 // By definition, in decision problems, the cost will always be 0.
+// If the problem has several cost functions, Weight is the cost associated with the first one,
+// and Weights contains the cost associated with each of them, by decreasing priority.
+// LowerBound is the best proven lower bound of the cost when the result was found: if it is equal to Weight,
+// the result is optimal. It is only computed for problems with a single cost function.
 type Result struct {
-	Status Status
-	Model  []bool
-	Weight int
+	Status     Status
+	Model      []bool
+	Weight     int
+	Weights    []int
+	LowerBound int
 }
 
 // Interface is any type implementing a solver.
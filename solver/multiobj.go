@@ -0,0 +1,267 @@
+package solver
+
+import "fmt"
+
+// This file deals with problems that have several cost functions,
+// either ranked by priority (lexicographic optimization) or not (Pareto optimization).
+
+// A costFunc is a linear function to minimize: the sum of the weights of its lits that are true.
+type costFunc struct {
+	lits    []Lit
+	weights []int // If nil, all weights are 1
+}
+
+// weight returns the weight of the i'th lit of cf.
+func (cf costFunc) weight(i int) int {
+	if cf.weights == nil {
+		return 1
+	}
+	return cf.weights[i]
+}
+
+// maxCost returns the biggest possible cost for cf.
+func (cf costFunc) maxCost() int {
+	if cf.weights == nil {
+		return len(cf.lits)
+	}
+	res := 0
+	for _, w := range cf.weights {
+		res += w
+	}
+	return res
+}
+
+// cost returns the value of cf for the given model.
+func (cf costFunc) cost(model []bool) int {
+	res := 0
+	for i, lit := range cf.lits {
+		if model[lit.Var()] == lit.IsPositive() {
+			res += cf.weight(i)
+		}
+	}
+	return res
+}
+
+// normalized returns a cost function where each var appears at most once, along with a constant offset,
+// so that the value of cf is always the value of the returned function plus the offset.
+// Repeated lits have their weights summed, and a lit and its negation cancel each other out.
+func (cf costFunc) normalized() (costFunc, int) {
+	coeffs := make(map[Var]int) // for each var, weight of its positive lit minus weight of its negative lit
+	var vars []Var
+	offset := 0
+	for i, lit := range cf.lits {
+		v := lit.Var()
+		if _, ok := coeffs[v]; !ok {
+			vars = append(vars, v)
+		}
+		if w := cf.weight(i); lit.IsPositive() {
+			coeffs[v] += w
+		} else {
+			coeffs[v] -= w
+			offset += w
+		}
+	}
+	var res costFunc
+	for _, v := range vars {
+		switch c := coeffs[v]; {
+		case c > 0:
+			res.lits = append(res.lits, v.Lit())
+			res.weights = append(res.weights, c)
+		case c < 0:
+			res.lits = append(res.lits, v.Lit().Negation())
+			res.weights = append(res.weights, -c)
+			offset += c
+		}
+	}
+	return res, offset
+}
+
+// atMost returns a PB clause stating that cf must not exceed bound.
+// If sel is not -1, the clause only holds when sel is true.
+// It returns nil if the constraint is trivially satisfied.
+func (cf costFunc) atMost(bound int, sel Lit) *Clause {
+	card := cf.maxCost() - bound
+	if card < 1 {
+		return nil
+	}
+	lits := make([]Lit, len(cf.lits), len(cf.lits)+1)
+	weights := make([]int, len(cf.lits), len(cf.lits)+1)
+	for i, lit := range cf.lits {
+		lits[i] = lit.Negation()
+		weights[i] = cf.weight(i)
+	}
+	if sel != -1 {
+		lits = append(lits, sel.Negation())
+		weights = append(weights, card)
+	}
+	return NewPBClause(lits, weights, card)
+}
+
+// costFuncs returns all the cost functions of the problem, by decreasing priority.
+func (s *Solver) costFuncs() []costFunc {
+	return append([]costFunc{{lits: s.minLits, weights: s.minWeights}}, s.moreCosts...)
+}
+
+// costs returns the value of each cost function for the given model.
+func costs(cfs []costFunc, model []bool) []int {
+	res := make([]int, len(cfs))
+	for i, cf := range cfs {
+		res[i] = cf.cost(model)
+	}
+	return res
+}
+
+// newSelector returns a fresh lit that can be used to activate constraints through assumptions.
+func (s *Solver) newSelector() Lit {
+	return s.NewVar().Lit()
+}
+
+// disable makes sure the constraints activated by the given selector will not be used anymore.
+func (s *Solver) disable(sel Lit) {
+	s.AppendClause(NewClause([]Lit{sel.Negation()}))
+}
+
+// Disable makes sure the constraints activated by the given selector, through assumptions, will not be used anymore.
+func (s *Solver) Disable(sel Lit) {
+	s.disable(sel)
+}
+
+// stopped returns true iff something was sent on stop, or it was closed.
+func stopped(stop chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// optimalLex returns the optimal solution to the problem, where cost functions are minimized
+// in lexicographic order: a model is better than another one if it has a lower cost for the first function,
+// or the same cost for the first function and a lower cost for the second one, etc.
+// Each cost function is minimized in turn, and its optimal value is then fixed for the following ones.
+// If results is not nil, every new best solution is written to it, and it is closed at the end of the call.
+// If the search is stopped, the best solution found so far is returned.
+func (s *Solver) optimalLex(results chan Result, stop chan struct{}) Result {
+	if results != nil {
+		defer close(results)
+	}
+	nbVars := s.nbVars
+	cfs := s.costFuncs()
+	if s.Solve() == Unsat {
+		res := Result{Status: Unsat}
+		if results != nil {
+			results <- res
+		}
+		return res
+	}
+	var best Result
+	update := func() {
+		model := s.Model()[:nbVars]
+		ws := costs(cfs, model)
+		best = Result{Status: Sat, Model: model, Weight: ws[0], Weights: ws}
+		if s.Verbose {
+			fmt.Printf("o %v\n", ws)
+		}
+		if results != nil {
+			results <- best
+		}
+	}
+	update()
+	for i, cf := range cfs {
+		// All models found from here have the same cost as best for previous functions, and a lower cost for this one.
+		for best.Weights[i] > 0 && !stopped(stop) {
+			sel := s.newSelector()
+			s.AppendClause(cf.atMost(best.Weights[i]-1, sel))
+			status := s.SolveAssumptions([]Lit{sel})
+			s.disable(sel)
+			if status != Sat {
+				break
+			}
+			update()
+		}
+		if stopped(stop) {
+			break
+		}
+		if c := cf.atMost(best.Weights[i], -1); c != nil {
+			s.AppendClause(c)
+		}
+	}
+	s.setLastModel(best.Model)
+	return best
+}
+
+// ParetoFront returns the Pareto front of the problem, i.e one model for each Pareto-optimal cost vector.
+// A model is Pareto-optimal if no other model is at least as good for all cost functions, and strictly better
+// for at least one. Priorities between cost functions are ignored.
+// Each returned result has its Weights field set to the cost of each cost function.
+// If results is not nil, each Pareto-optimal result is written to it as soon as it is found,
+// and it is closed at the end of the call.
+// If the search is stopped, the part of the front that was found so far is returned.
+// Once this method was called, the solver cannot be used to solve the problem anymore.
+func (s *Solver) ParetoFront(results chan Result, stop chan struct{}) []Result {
+	if results != nil {
+		defer close(results)
+	}
+	if s.minLits == nil {
+		if s.Solve() != Sat {
+			return nil
+		}
+		res := Result{Status: Sat, Model: s.Model(), Weights: []int{}}
+		if results != nil {
+			results <- res
+		}
+		return []Result{res}
+	}
+	nbVars := s.nbVars
+	cfs := s.costFuncs()
+	var front []Result
+	for !stopped(stop) && s.Solve() == Sat {
+		model := s.Model()[:nbVars]
+		ws := costs(cfs, model)
+		var improve []Lit // For each cost function that can be improved, a selector meaning it is improved
+		for {
+			if stopped(stop) { // Current model might not be Pareto-optimal
+				return front
+			}
+			// Look for a model that dominates the current one
+			improve = nil
+			sel := s.newSelector()
+			for i, cf := range cfs {
+				if c := cf.atMost(ws[i], sel); c != nil {
+					s.AppendClause(c)
+				}
+				if ws[i] > 0 {
+					sel2 := s.newSelector()
+					s.AppendClause(cf.atMost(ws[i]-1, sel2))
+					improve = append(improve, sel2)
+				}
+			}
+			if len(improve) == 0 { // All costs are 0: this model dominates all others
+				break
+			}
+			s.AppendClause(NewClause(append([]Lit{sel.Negation()}, improve...)))
+			status := s.SolveAssumptions([]Lit{sel})
+			s.disable(sel)
+			if status != Sat {
+				break
+			}
+			model = s.Model()[:nbVars]
+			ws = costs(cfs, model)
+		}
+		res := Result{Status: Sat, Model: model, Weight: ws[0], Weights: ws}
+		front = append(front, res)
+		if s.Verbose {
+			fmt.Printf("o %v\n", ws)
+		}
+		if results != nil {
+			results <- res
+		}
+		if len(improve) == 0 {
+			break
+		}
+		// From now on, models must be strictly better than this one for at least one cost function
+		s.AppendClause(NewClause(improve))
+	}
+	return front
+}
@@ -0,0 +1,223 @@
+package solver
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// A randomMultiObj is a random problem with several cost functions.
+type randomMultiObj struct {
+	nbVars  int
+	clauses [][]int
+	cfs     []costFunc
+}
+
+func newRandomMultiObj(r *rand.Rand) randomMultiObj {
+	pb := randomMultiObj{nbVars: 4 + r.Intn(6)}
+	for i := 0; i < 2+r.Intn(12); i++ {
+		perm := r.Perm(pb.nbVars)
+		clause := make([]int, 1+r.Intn(3))
+		for j := range clause {
+			clause[j] = perm[j] + 1
+			if r.Intn(2) == 0 {
+				clause[j] = -clause[j]
+			}
+		}
+		pb.clauses = append(pb.clauses, clause)
+	}
+	for i := 0; i < 2+r.Intn(2); i++ {
+		var cf costFunc
+		for _, v := range r.Perm(pb.nbVars)[:1+r.Intn(pb.nbVars)] {
+			cf.lits = append(cf.lits, Var(v).SignedLit(r.Intn(2) == 0))
+			cf.weights = append(cf.weights, 1+r.Intn(4))
+		}
+		if r.Intn(2) == 0 {
+			cf.weights = nil
+		}
+		pb.cfs = append(pb.cfs, cf)
+	}
+	return pb
+}
+
+func (pb randomMultiObj) solver() *Solver {
+	prob := ParseSliceNb(pb.clauses, pb.nbVars)
+	for _, cf := range pb.cfs {
+		prob.AddCostFunc(cf.lits, cf.weights)
+	}
+	return New(prob)
+}
+
+// costVectors returns the cost vector of all models of pb.
+func (pb randomMultiObj) costVectors() [][]int {
+	var res [][]int
+	model := make([]bool, pb.nbVars)
+	for i := 0; i < 1<<uint(pb.nbVars); i++ {
+		for v := range model {
+			model[v] = i&(1<<uint(v)) != 0
+		}
+		if pb.sat(model) {
+			res = append(res, costs(pb.cfs, model))
+		}
+	}
+	return res
+}
+
+func (pb randomMultiObj) sat(model []bool) bool {
+	for _, clause := range pb.clauses {
+		sat := false
+		for _, lit := range clause {
+			if model[IntToLit(int32(lit)).Var()] == (lit > 0) {
+				sat = true
+				break
+			}
+		}
+		if !sat {
+			return false
+		}
+	}
+	return true
+}
+
+// lexLess returns true iff c1 is lexicographically smaller than c2.
+func lexLess(c1, c2 []int) bool {
+	for i := range c1 {
+		if c1[i] != c2[i] {
+			return c1[i] < c2[i]
+		}
+	}
+	return false
+}
+
+// dominates returns true iff c1 dominates c2.
+func dominates(c1, c2 []int) bool {
+	strict := false
+	for i := range c1 {
+		if c1[i] > c2[i] {
+			return false
+		}
+		if c1[i] < c2[i] {
+			strict = true
+		}
+	}
+	return strict
+}
+
+func TestOptimalLex(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 300; iter++ {
+		pb := newRandomMultiObj(r)
+		var expected []int
+		for _, c := range pb.costVectors() {
+			if expected == nil || lexLess(c, expected) {
+				expected = c
+			}
+		}
+		s := pb.solver()
+		res := s.Optimal(nil, nil)
+		if expected == nil {
+			if res.Status != Unsat {
+				t.Fatalf("iter %d: expected unsat, got %v", iter, res.Status)
+			}
+			continue
+		}
+		if res.Status != Sat {
+			t.Fatalf("iter %d: expected sat, got %v", iter, res.Status)
+		}
+		if len(res.Model) != pb.nbVars || !pb.sat(res.Model) {
+			t.Fatalf("iter %d: invalid model %v", iter, res.Model)
+		}
+		if got := costs(pb.cfs, res.Model); !equalCosts(got, expected) || !equalCosts(res.Weights, expected) || res.Weight != expected[0] {
+			t.Fatalf("iter %d: expected costs %v, got %v (model costs %v)", iter, expected, res.Weights, got)
+		}
+	}
+}
+
+func TestParetoFront(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 300; iter++ {
+		pb := newRandomMultiObj(r)
+		all := pb.costVectors()
+		var expected [][]int
+		for _, c := range all {
+			optimal := true
+			for _, c2 := range all {
+				if dominates(c2, c) {
+					optimal = false
+					break
+				}
+			}
+			if optimal && !containsCosts(expected, c) {
+				expected = append(expected, c)
+			}
+		}
+		results := make(chan Result)
+		go pb.solver().ParetoFront(results, nil)
+		var got [][]int
+		for res := range results {
+			if !pb.sat(res.Model) || !equalCosts(costs(pb.cfs, res.Model), res.Weights) {
+				t.Fatalf("iter %d: invalid model %v", iter, res.Model)
+			}
+			got = append(got, res.Weights)
+		}
+		sortCosts(expected)
+		sortCosts(got)
+		if len(got) != len(expected) {
+			t.Fatalf("iter %d: expected front %v, got %v", iter, expected, got)
+		}
+		for i := range got {
+			if !equalCosts(got[i], expected[i]) {
+				t.Fatalf("iter %d: expected front %v, got %v", iter, expected, got)
+			}
+		}
+	}
+}
+
+func TestParseOPBSeveralMin(t *testing.T) {
+	const opb = `* #variable= 3 #constraint= 2
+min: +1 x1 +1 x2 +1 x3 ;
+min: +3 x1 +2 x2 +1 x3 ;
++1 x1 +1 x2 +1 x3 >= 2 ;
++1 x1 +1 x3 >= 1 ;
+`
+	pb, err := ParseOPB(strings.NewReader(opb))
+	if err != nil {
+		t.Fatalf("could not parse OPB: %v", err)
+	}
+	if n := strings.Count(pb.PBString(), "min:"); n != 2 {
+		t.Errorf("expected 2 cost functions in PB representation, got %d", n)
+	}
+	res := New(pb).Optimal(nil, nil)
+	if res.Status != Sat {
+		t.Fatalf("expected sat, got %v", res.Status)
+	}
+	if !equalCosts(res.Weights, []int{2, 3}) {
+		t.Errorf("expected costs [2 3], got %v", res.Weights)
+	}
+}
+
+func equalCosts(c1, c2 []int) bool {
+	if len(c1) != len(c2) {
+		return false
+	}
+	for i := range c1 {
+		if c1[i] != c2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsCosts(cs [][]int, c []int) bool {
+	for _, c2 := range cs {
+		if equalCosts(c, c2) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortCosts(cs [][]int) {
+	sort.Slice(cs, func(i, j int) bool { return lexLess(cs[i], cs[j]) })
+}
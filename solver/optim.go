@@ -0,0 +1,191 @@
+package solver
+
+import "fmt"
+
+// An OptimStrategy is a way to look for an optimal solution to a pseudo-boolean optimization problem.
+type OptimStrategy int
+
+const (
+	// LinearSearch is a SAT-UNSAT search: each time a model is found, a constraint is added
+	// so that the next model must be strictly better, until the problem becomes UNSAT.
+	// It provides good solutions quickly, but the lower bound is only known once the optimum was proved.
+	LinearSearch OptimStrategy = iota
+	// BinarySearch looks for a model whose cost is at most halfway between the best known lower and upper bounds.
+	// The bound only holds under an assumption, so that, when no such model exists, the lower bound is raised
+	// and the search goes on with the same solver.
+	BinarySearch
+	// CoreBoosted starts with a core phase, where all the lits of the cost function are assumed to be false.
+	// Each unsatisfiable core raises the lower bound by the smallest weight of its lits, which are then not assumed anymore.
+	// Once the remaining assumptions are satisfiable, a linear search is run from the model that was found,
+	// and stops as soon as it reaches the lower bound.
+	CoreBoosted
+)
+
+// String returns the name of the strategy.
+func (strat OptimStrategy) String() string {
+	switch strat {
+	case LinearSearch:
+		return "linear"
+	case BinarySearch:
+		return "binary"
+	case CoreBoosted:
+		return "core"
+	default:
+		return fmt.Sprintf("OptimStrategy(%d)", int(strat))
+	}
+}
+
+// ParseOptimStrategy returns the strategy whose name is given, as returned by OptimStrategy.String.
+func ParseOptimStrategy(name string) (OptimStrategy, error) {
+	for _, strat := range []OptimStrategy{LinearSearch, BinarySearch, CoreBoosted} {
+		if strat.String() == name {
+			return strat, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown optimization strategy %q", name)
+}
+
+// optimal returns the optimal solution to a problem with at most one cost function, using s.Strategy.
+// If results is not nil, every new best solution is written to it, along with the best known lower bound,
+// and it is closed at the end of the call.
+// If the search is stopped, the best solution found so far is returned.
+func (s *Solver) optimal(results chan Result, stop chan struct{}) Result {
+	if results != nil {
+		defer close(results)
+	}
+	nbVars := s.nbVars
+	cf := costFunc{lits: s.minLits, weights: s.minWeights}
+	var (
+		best Result
+		lb   int // Best known lower bound
+	)
+	bcf, offset := cf.normalized() // Bounds are set on bcf, where each var appears at most once
+	// update records the current model and returns whether it is better than the best known one.
+	update := func() bool {
+		model := s.Model()[:nbVars]
+		cost := cf.cost(model)
+		if best.Status == Sat && cost >= best.Weight {
+			return false
+		}
+		best = Result{Status: Sat, Model: model, Weight: cost, LowerBound: lb}
+		if s.Verbose {
+			fmt.Printf("c cost %d, lower bound %d\n", best.Weight, lb)
+		}
+		if results != nil {
+			results <- best
+		}
+		return true
+	}
+	var status Status
+	if s.Strategy == CoreBoosted && s.minLits != nil {
+		status, lb = s.raiseLowerBound(cf, stop)
+	} else {
+		status = s.Solve()
+	}
+	switch status {
+	case Unsat:
+		best.Status = Unsat
+		if results != nil {
+			results <- best
+		}
+		return best
+	case Indet: // Stopped before the first model was found
+		return best
+	}
+	if lb < offset {
+		lb = offset
+	}
+	update()
+	for lb < best.Weight && !stopped(stop) {
+		if s.Strategy == BinarySearch {
+			mid := lb + (best.Weight-1-lb)/2
+			sel := s.newSelector()
+			s.AppendClause(bcf.atMost(mid-offset, sel))
+			status := s.SolveAssumptions([]Lit{sel})
+			failed := s.FailedAssumptions()
+			s.disable(sel)
+			if status == Sat {
+				if !update() {
+					break
+				}
+			} else if failed != nil {
+				lb = mid + 1
+			} else {
+				break
+			}
+		} else {
+			s.AppendClause(bcf.atMost(best.Weight-1-offset, -1))
+			s.rebuildOrderHeap()
+			if s.Solve() != Sat {
+				lb = best.Weight
+				break
+			}
+			if !update() {
+				break
+			}
+		}
+	}
+	if lb >= best.Weight {
+		best.LowerBound = best.Weight
+	}
+	s.setLastModel(best.Model)
+	return best
+}
+
+// raiseLowerBound is the core phase of the CoreBoosted strategy.
+// It assumes all lits of cf are false and, as long as this is not possible, raises the lower bound
+// thanks to the unsatisfiable core and stops assuming the lits of the core.
+// It returns the status of the last call to the solver and the lower bound that was proved.
+// If the status is Sat, the solver holds a model of the problem.
+func (s *Solver) raiseLowerBound(cf costFunc, stop chan struct{}) (Status, int) {
+	lb := 0
+	var assumps []Lit
+	weights := make(map[Lit]int)
+	for i, lit := range cf.lits {
+		if w := cf.weight(i); w > 0 {
+			assumps = append(assumps, lit.Negation())
+			weights[lit.Negation()] += w
+		}
+	}
+	for !stopped(stop) {
+		if s.SolveAssumptions(assumps) == Sat {
+			return Sat, lb
+		}
+		core := s.FailedAssumptions()
+		if core == nil {
+			return Unsat, lb
+		}
+		minW := -1
+		inCore := make(map[Lit]bool)
+		for _, lit := range core {
+			inCore[lit] = true
+			if minW == -1 || weights[lit] < minW {
+				minW = weights[lit]
+			}
+		}
+		lb += minW
+		if s.Verbose {
+			fmt.Printf("c lower bound %d\n", lb)
+		}
+		var remaining []Lit
+		for _, lit := range assumps {
+			if !inCore[lit] {
+				remaining = append(remaining, lit)
+			}
+		}
+		assumps = remaining
+	}
+	return Indet, lb
+}
+
+// setLastModel sets the model returned by s.Model() to the given one.
+func (s *Solver) setLastModel(model []bool) {
+	s.lastModel = make(Model, len(model))
+	for i, b := range model {
+		if b {
+			s.lastModel[i] = 1
+		} else {
+			s.lastModel[i] = -1
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package solver
 
 import (
+	"math/rand"
 	"os"
 	"strings"
 	"testing"
@@ -98,6 +99,108 @@ func TestOptimal(t *testing.T) {
 	}
 }
 
+// Once the first model is found, the constraint bounding the cost of the next one has only two lits,
+// and a cardinality of 6.
+func TestMinimizeTwoSoftLits(t *testing.T) {
+	pb := ParseSlice([][]int{{1, 2}, {-1, -2}, {-1, 3}, {-2, 4}})
+	pb.SetCostFunc([]Lit{IntToLit(3), IntToLit(4)}, []int{3, 5})
+	s := New(pb)
+	if cost := s.Minimize(); cost != 3 {
+		t.Errorf("invalid cost, expected 3, got %d", cost)
+	}
+}
+
+// Weights so big their sum does not fit in the clause's lbdValue.
+func TestMinimizeBigWeights(t *testing.T) {
+	pb := ParseSlice([][]int{{1, 2}, {-1, -2}, {-1, 3}, {-2, 4}, {-1, -2, 5}})
+	pb.SetCostFunc([]Lit{IntToLit(3), IntToLit(4), IntToLit(5)}, []int{3000000000, 5000000000, 1000000000})
+	s := New(pb)
+	if cost := s.Minimize(); cost != 3000000000 {
+		t.Errorf("invalid cost, expected 3000000000, got %d", cost)
+	}
+}
+
+var optimStrategies = []OptimStrategy{LinearSearch, BinarySearch, CoreBoosted}
+
+func TestOptimStrategies(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 300; iter++ {
+		pb := newRandomMultiObj(r)
+		pb.cfs = pb.cfs[:1]
+		expected := -1
+		for _, c := range pb.costVectors() {
+			if expected == -1 || c[0] < expected {
+				expected = c[0]
+			}
+		}
+		for _, strat := range optimStrategies {
+			s := pb.solver()
+			s.Strategy = strat
+			res := s.Optimal(nil, nil)
+			if expected == -1 {
+				if res.Status != Unsat {
+					t.Fatalf("iter %d, %v: expected unsat, got %v", iter, strat, res.Status)
+				}
+				continue
+			}
+			if res.Status != Sat || res.Weight != expected || res.LowerBound != expected {
+				t.Fatalf("iter %d, %v: expected optimal cost %d, got %v with cost %d and lower bound %d", iter, strat, expected, res.Status, res.Weight, res.LowerBound)
+			}
+			if !pb.sat(res.Model) || pb.cfs[0].cost(res.Model) != expected {
+				t.Fatalf("iter %d, %v: invalid model %v", iter, strat, res.Model)
+			}
+			if model := s.Model(); len(model) != pb.nbVars || pb.cfs[0].cost(model) != expected {
+				t.Fatalf("iter %d, %v: invalid model %v after optimization", iter, strat, model)
+			}
+		}
+	}
+}
+
+// The cost function repeats lits and contains both a var and its negation.
+func TestOptimStrategiesDuplicateLits(t *testing.T) {
+	for _, strat := range optimStrategies {
+		pb := ParseSliceNb([][]int{{1}, {-3, 5, 1}, {3}}, 6)
+		lits := []Lit{IntToLit(4), IntToLit(-4), IntToLit(3), IntToLit(3), IntToLit(-4)}
+		pb.SetCostFunc(lits, []int{3, 6, 3, 4, 5})
+		s := New(pb)
+		s.Strategy = strat
+		if res := s.Optimal(nil, nil); res.Status != Sat || res.Weight != 10 || res.LowerBound != 10 {
+			t.Errorf("%v: expected optimal cost 10, got %v with cost %d and lower bound %d", strat, res.Status, res.Weight, res.LowerBound)
+		}
+	}
+}
+
+func TestOptimStrategiesResults(t *testing.T) {
+	for _, strat := range optimStrategies {
+		f, err := os.Open("testcnf/lo_8x8_009.opb")
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		pb, err := ParseOPB(f)
+		_ = f.Close()
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		s := New(pb)
+		s.Strategy = strat
+		results := make(chan Result)
+		go s.Optimal(results, nil)
+		var prev Result
+		for res := range results {
+			if res.LowerBound > res.Weight {
+				t.Errorf("%v: lower bound %d is greater than cost %d", strat, res.LowerBound, res.Weight)
+			}
+			if prev.Status == Sat && (res.Weight >= prev.Weight || res.LowerBound < prev.LowerBound) {
+				t.Errorf("%v: got cost %d and lower bound %d after cost %d and lower bound %d", strat, res.Weight, res.LowerBound, prev.Weight, prev.LowerBound)
+			}
+			prev = res
+		}
+		if prev.Status != Sat || prev.Weight != 27 {
+			t.Errorf("%v: expected optimal cost 27, got %v with cost %d", strat, prev.Status, prev.Weight)
+		}
+	}
+}
+
 func runOptimBench(path string, b *testing.B) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -1,11 +1,10 @@
 package solver
 
 import (
-	"bufio"
 	"fmt"
 	"io"
-	"strconv"
-	"strings"
+
+	"github.com/DoOR-Team/gophersat/opb"
 )
 
 // ParseCardConstrs parses the given cardinality constraints.
@@ -129,138 +128,81 @@ func ParsePBConstrs(constrs []PBConstr) *Problem {
 	return &pb
 }
 
-// parsePBOptim parses the "min:" instruction.
-func (pb *Problem) parsePBOptim(fields []string, line string) error {
-	weights, lits, err := pb.parseTerms(fields[1:], line)
-	if err != nil {
-		return err
+// appendGtEq adds to pb the constraint stating that the sum of lits, weighted by weights, is at least card.
+// Weights must be positive.
+func (pb *Problem) appendGtEq(lits, weights []int, card int) {
+	sumW := 0
+	for _, w := range weights {
+		sumW += w
 	}
-	pb.minLits = make([]Lit, len(lits))
-	for i, lit := range lits {
-		pb.minLits[i] = IntToLit(int32(lit))
-	}
-	pb.minWeights = weights
-	return nil
-}
-
-func (pb *Problem) parsePBLine(line string) error {
-	if line[len(line)-1] != ';' {
-		return fmt.Errorf("line %q does not end with semicolon", line)
+	if sumW < card { // Clause cannot be satsfied
+		pb.Status = Unsat
+		return
 	}
-	fields := strings.Fields(line[:len(line)-1])
-	if len(fields) == 0 {
-		return fmt.Errorf("empty line in file")
+	if sumW == card { // All lits must be true
+		for _, lit := range lits {
+			pb.Units = append(pb.Units, IntToLit(int32(lit)))
+		}
+		return
 	}
-	if fields[0] == "min:" { // Optimization constraint
-		return pb.parsePBOptim(fields, line)
+	clause := make([]Lit, len(lits))
+	for j, val := range lits {
+		clause[j] = IntToLit(int32(val))
 	}
-	return pb.parsePBConstrLine(fields, line)
+	pb.Clauses = append(pb.Clauses, NewPBClause(clause, weights, card))
 }
 
-func (pb *Problem) parsePBConstrLine(fields []string, line string) error {
-	if len(fields) < 3 {
-		return fmt.Errorf("invalid syntax %q", line)
-	}
-	operator := fields[len(fields)-2]
-	if operator != ">=" && operator != "=" {
-		return fmt.Errorf("invalid operator %q in %q: expected \">=\" or \"=\"", operator, line)
-	}
-	rhs, err := strconv.Atoi(fields[len(fields)-1])
-	if err != nil {
-		return fmt.Errorf("invalid value %q in %q: %v", fields[len(fields)-1], line, err)
-	}
-	weights, lits, err := pb.parseTerms(fields[:len(fields)-2], line)
+// ParseOPB parses a file corresponding to the OPB syntax.
+// See http://www.cril.univ-artois.fr/PB16/format.pdf for more details.
+// Products of literals are replaced by auxiliary variables, numbered after the variables of the problem.
+// "max:" objectives, and objectives with negative weights, are turned into equivalent cost functions
+// with positive weights: use ObjectiveValue to get the value of the original objectives.
+// WBO problems, that contain soft constraints, are rejected: they can be parsed with maxsat.ParseWBO.
+func ParseOPB(f io.Reader) (*Problem, error) {
+	p, err := opb.Parse(f)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	var constrs []PBConstr
-	if operator == ">=" {
-		constrs = []PBConstr{GtEq(lits, weights, rhs)}
-	} else {
-		constrs = Eq(lits, weights, rhs)
+	if p.WBO {
+		return nil, fmt.Errorf("cannot parse WBO problem as an OPB problem")
 	}
-	for _, constr := range constrs {
-		card := constr.AtLeast
-		sumW := constr.WeightSum()
-		if sumW < card { // Clause cannot be satsfied
-			pb.Status = Unsat
-			return nil
-		}
-		if sumW == card { // All lits must be true
-			for i := range constr.Lits {
-				lit := IntToLit(int32(constr.Lits[i]))
-				pb.Units = append(pb.Units, lit)
-			}
-		} else {
-			lits := make([]Lit, len(constr.Lits))
-			for j, val := range constr.Lits {
-				lits[j] = IntToLit(int32(val))
-			}
-			pb.Clauses = append(pb.Clauses, NewPBClause(lits, constr.Weights, card))
+	p.Normalize()
+	pb := Problem{NbVars: p.NbVars}
+	for _, o := range p.Objectives {
+		lits := make([]Lit, len(o.Terms))
+		weights := make([]int, len(o.Terms))
+		for i, t := range o.Terms {
+			lits[i] = IntToLit(int32(t.Lits[0]))
+			weights[i] = t.Coeff
 		}
+		pb.AddCostFunc(lits, weights)
+		pb.objectives = append(pb.objectives, opb.Objective{Maximize: o.Maximize, Offset: o.Offset})
 	}
-	return nil
-}
-
-func (pb *Problem) parseTerms(terms []string, line string) (weights []int, lits []int, err error) {
-	weights = make([]int, 0, len(terms)/2)
-	lits = make([]int, 0, len(terms)/2)
-	i := 0
-	for i < len(terms) {
-		var l string
-		w, err := strconv.Atoi(terms[i])
-		if err != nil {
-			l = terms[i]
-			if !strings.HasPrefix(l, "x") && !strings.HasPrefix(l, "~x") {
-				return nil, nil, fmt.Errorf("invalid weight %q in %q: %v", terms[i*2], line, err)
-			}
-			// This is a weightless lit, i.e a lit with weight 1.
-			weights = append(weights, 1)
-		} else {
-			weights = append(weights, w)
-			i++
-			l = terms[i]
-			if !strings.HasPrefix(l, "x") && !strings.HasPrefix(l, "~x") || len(l) < 2 {
-				return nil, nil, fmt.Errorf("invalid variable name %q in %q", l, line)
-			}
-		}
-		var lit int
-		if l[0] == '~' {
-			lit, err = strconv.Atoi(l[2:])
-			lits = append(lits, -lit)
-		} else {
-			lit, err = strconv.Atoi(l[1:])
-			lits = append(lits, lit)
-		}
-		if err != nil {
-			return nil, nil, fmt.Errorf("invalid variable %q in %q: %v", l, line, err)
+	for _, c := range p.Constraints {
+		lits := make([]int, len(c.Terms))
+		weights := make([]int, len(c.Terms))
+		for i, t := range c.Terms {
+			lits[i] = t.Lits[0]
+			weights[i] = t.Coeff
 		}
-		if lit > pb.NbVars {
-			pb.NbVars = lit
+		if pb.appendGtEq(lits, weights, c.RHS); pb.Status == Unsat {
+			return &pb, nil
 		}
-		i++
 	}
-	return weights, lits, nil
-}
-
-// ParseOPB parses a file corresponding to the OPB syntax.
-// See http://www.cril.univ-artois.fr/PB16/format.pdf for more details.
-func ParseOPB(f io.Reader) (*Problem, error) {
-	scanner := bufio.NewScanner(f)
-	var pb Problem
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" || line[0] == '*' {
-			continue
-		}
-		if err := pb.parsePBLine(line); err != nil {
-			return nil, err
+	pb.Model = make([]decLevel, pb.NbVars)
+	for _, unit := range pb.Units {
+		v := unit.Var()
+		if pb.Model[v] == 0 {
+			if unit.IsPositive() {
+				pb.Model[v] = 1
+			} else {
+				pb.Model[v] = -1
+			}
+		} else if pb.Model[v] > 0 != unit.IsPositive() {
+			pb.Status = Unsat
+			return &pb, nil
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("could not parse OPB: %v", err)
-	}
-	pb.Model = make([]decLevel, pb.NbVars)
 	pb.simplifyPB()
 	return &pb, nil
 }
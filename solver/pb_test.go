@@ -2,6 +2,7 @@ package solver
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -198,6 +199,36 @@ func TestPB(t *testing.T) {
 	}
 }
 
+func TestParseOPBNonLinear(t *testing.T) {
+	tests := []struct {
+		content  string
+		expected int // Expected optimal value, or 0 if the problem is unsat
+	}{
+		{"max: +1 x1 +1 x2 x3 ;\n+1 ~x1 +1 ~x2 >= 1 ;\n", 1},
+		{"max: +2 x1 x2 -1 x3 ;\n3 x1 x2 +2 ~x3 >= 2 ;\n", 2},
+		{"min: -1 x1 x2 +2 x3 ;\n+1 x1 x2 +1 x3 = 1 ;\n", -1},
+		{"min: +1 x1 +1 x2 ;\n+1 x1 x2 >= 1 ;\n", 2},
+		{"min: +1 x1 ;\n+1 x1 ~x1 >= 1 ;\n", 0},
+	}
+	for _, test := range tests {
+		pb, err := ParseOPB(strings.NewReader(test.content))
+		if err != nil {
+			t.Errorf("could not parse %q: %v", test.content, err)
+			continue
+		}
+		res := New(pb).Optimal(nil, nil)
+		if test.expected == 0 {
+			if res.Status != Unsat {
+				t.Errorf("%q: expected unsat, got %v", test.content, res.Status)
+			}
+		} else if res.Status != Sat {
+			t.Errorf("%q: expected sat, got %v", test.content, res.Status)
+		} else if val := pb.ObjectiveValue(0, res.Weight); val != test.expected {
+			t.Errorf("%q: expected optimal value %d, got %d", test.content, test.expected, val)
+		}
+	}
+}
+
 func runPBBench(path string, b *testing.B) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -225,3 +256,26 @@ func Benchmark3Col(b *testing.B) {
 func BenchmarkBandwidth(b *testing.B) {
 	runPBBench("testcnf/fixed-bandwidth-10.cnf.gz-extracted.pb", b)
 }
+
+func TestParseOPBUnits(t *testing.T) {
+	tests := []struct {
+		content string
+		status  Status
+	}{
+		{"1 x1 >= 1 ;\n1 ~x1 +1 x2 >= 1 ;\n", Sat},
+		{"1 x1 >= 1 ;\n1 ~x1 >= 1 ;\n1 x1 +1 x2 >= 1 ;\n", Unsat},
+		{"1 x1 = 1 ;\n1 x2 = 0 ;\n1 ~x1 +1 x2 >= 1 ;\n", Unsat},
+	}
+	for _, test := range tests {
+		pb, err := ParseOPB(strings.NewReader(test.content))
+		if err != nil {
+			t.Fatalf("could not parse %q: %v", test.content, err)
+		}
+		s := New(pb)
+		if status := s.Solve(); status != test.status {
+			t.Errorf("%q: expected status %v, got %v", test.content, test.status, status)
+		} else if status == Sat && (!s.Model()[0] || !s.Model()[1]) {
+			t.Errorf("%q: invalid model %v", test.content, s.Model())
+		}
+	}
+}
@@ -1,18 +1,23 @@
 package solver
 
 import (
-	"fmt"
+	"bufio"
+	"strings"
+
+	"github.com/DoOR-Team/gophersat/opb"
 )
 
 // A Problem is a list of clauses & a nb of vars.
 type Problem struct {
-	NbVars     int        // Total nb of vars
-	Clauses    []*Clause  // List of non-empty, non-unit clauses
-	Status     Status     // Status of the problem. Can be trivially UNSAT (if empty clause was met or inferred by UP) or Indet.
-	Units      []Lit      // List of unit literal found in the problem.
-	Model      []decLevel // For each var, its inferred binding. 0 means unbound, 1 means bound to true, -1 means bound to false.
-	minLits    []Lit      // For an optimisation problem, the list of lits whose sum must be minimized
-	minWeights []int      // For an optimisation problem, the weight of each lit.
+	NbVars     int             // Total nb of vars
+	Clauses    []*Clause       // List of non-empty, non-unit clauses
+	Status     Status          // Status of the problem. Can be trivially UNSAT (if empty clause was met or inferred by UP) or Indet.
+	Units      []Lit           // List of unit literal found in the problem.
+	Model      []decLevel      // For each var, its inferred binding. 0 means unbound, 1 means bound to true, -1 means bound to false.
+	minLits    []Lit           // For an optimisation problem, the list of lits whose sum must be minimized
+	minWeights []int           // For an optimisation problem, the weight of each lit.
+	moreCosts  []costFunc      // For a multi-objective problem, the cost functions that come after the first one.
+	objectives []opb.Objective // For a problem parsed from an OPB file, the original objectives, without their terms.
 }
 
 // Optim returns true iff pb is an optimisation problem, ie
@@ -23,71 +28,59 @@ func (pb *Problem) Optim() bool {
 }
 
 // CNF returns a DIMACS CNF representation of the problem.
+// Cardinality and PB constraints are written as if they were clauses. To write large problems, use WriteDIMACS.
 func (pb *Problem) CNF() string {
-	res := fmt.Sprintf("p cnf %d %d\n", pb.NbVars, len(pb.Clauses)+len(pb.Units))
-	for _, unit := range pb.Units {
-		res += fmt.Sprintf("%d 0\n", unit.Int())
-	}
-	for _, clause := range pb.Clauses {
-		res += fmt.Sprintf("%s\n", clause.CNF())
-	}
-	return res
+	var b strings.Builder
+	bw := bufio.NewWriter(&b)
+	pb.writeDIMACS(bw)
+	bw.Flush()
+	return b.String()
 }
 
-// PBString returns a representation of the problem as a pseudo-boolean problem.
+// PBString returns a representation of the problem as a pseudo-boolean problem. To write large problems, use WriteOPB.
 func (pb *Problem) PBString() string {
-	res := pb.costFuncString()
-	for _, unit := range pb.Units {
-		sign := ""
-		if !unit.IsPositive() {
-			sign = "~"
-			unit = unit.Negation()
-		}
-		res += fmt.Sprintf("1 %sx%d = 1 ;\n", sign, unit.Int())
-	}
-	for _, clause := range pb.Clauses {
-		res += fmt.Sprintf("%s\n", clause.PBString())
+	var b strings.Builder
+	pb.WriteOPB(&b)
+	return b.String()
+}
+
+// ObjectiveValue returns the value of the i'th objective of the problem, as it was written in the OPB file
+// the problem was parsed from, given the value of the i'th cost function, e.g the weight of a solver.Result.
+// For other problems, it returns cost.
+func (pb *Problem) ObjectiveValue(i, cost int) int {
+	if i >= len(pb.objectives) {
+		return cost
 	}
-	return res
+	return pb.objectives[i].Value(cost)
 }
 
 // SetCostFunc sets the function to minimize when optimizing the problem.
 // If all weights are 1, weights can be nil.
 // In all other cases, len(lits) must be the same as len(weights).
+// Cost functions previously added with AddCostFunc, if any, are removed.
 func (pb *Problem) SetCostFunc(lits []Lit, weights []int) {
 	if weights != nil && len(lits) != len(weights) {
 		panic("length of lits and of weights don't match")
 	}
 	pb.minLits = lits
 	pb.minWeights = weights
+	pb.moreCosts = nil
+	pb.objectives = nil
 }
 
-// costFuncString returns a string representation of the cost function of the problem, if any, followed by a \n.
-// If there is no cost function, the empty string will be returned.
-func (pb *Problem) costFuncString() string {
+// AddCostFunc adds a function to minimize when optimizing the problem.
+// Cost functions are ranked by decreasing priority, in the order they were set:
+// optimizing the problem means minimizing them in lexicographic order.
+// If the problem had no cost function yet, this is the same as calling SetCostFunc.
+func (pb *Problem) AddCostFunc(lits []Lit, weights []int) {
 	if pb.minLits == nil {
-		return ""
+		pb.SetCostFunc(lits, weights)
+		return
 	}
-	res := "min: "
-	for i, lit := range pb.minLits {
-		w := 1
-		if pb.minWeights != nil {
-			w = pb.minWeights[i]
-		}
-		sign := ""
-		if w >= 0 && i != 0 { // No plus sign for the first term or for negative terms.
-			sign = "+"
-		}
-		val := lit.Int()
-		neg := ""
-		if val < 0 {
-			val = -val
-			neg = "~"
-		}
-		res += fmt.Sprintf("%s%d %sx%d", sign, w, neg, val)
+	if weights != nil && len(lits) != len(weights) {
+		panic("length of lits and of weights don't match")
 	}
-	res += " ;\n"
-	return res
+	pb.moreCosts = append(pb.moreCosts, costFunc{lits: lits, weights: weights})
 }
 
 func (pb *Problem) updateStatus(nbClauses int) {
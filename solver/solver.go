@@ -1,8 +1,8 @@
 package solver
 
 import (
+	"bufio"
 	"fmt"
-	"sort"
 	"strings"
 	"time"
 )
@@ -54,9 +54,10 @@ func (m Model) String() string {
 
 // A Solver solves a given problem. It is the main data structure.
 type Solver struct {
-	Verbose     bool        // Indicates whether the solver should display information during solving or not. False by default
-	Certified   bool        // Indicates whether a certificate should be generated during solving or not, using the RUP notation. This is useful to prove UNSAT instances. False by default.
-	CertChan    chan string // Indicates where to write the certificate. If Certified is true but CertChan is nil, the certificate will be written on stdout.
+	Verbose     bool          // Indicates whether the solver should display information during solving or not. False by default
+	Strategy    OptimStrategy // Strategy used to solve optimization problems. LinearSearch by default.
+	Certified   bool          // Indicates whether a certificate should be generated during solving or not, using the RUP notation. This is useful to prove UNSAT instances. False by default.
+	CertChan    chan string   // Indicates where to write the certificate. If Certified is true but CertChan is nil, the certificate will be written on stdout.
 	nbVars      int
 	status      Status
 	wl          watcherList
@@ -73,13 +74,16 @@ type Solver struct {
 	varInc          float64 // On each var bump, how big the increment should be
 	clauseInc       float32 // On each var bump, how big the increment should be
 	lbdStats        lbdStats
-	Stats           Stats   // Statistics about the solving process.
-	minLits         []Lit   // Lits to minimize if the problem was an optimization problem.
-	minWeights      []int   // Weight of each lit to minimize if the problem was an optimization problem.
-	hypothesis      []Lit   // Literals that are, ideally, true. Useful when trying to minimize a function.
-	localNbRestarts int     // How many restarts since Solve() was called?
-	varDecay        float64 // On each var decay, how much the varInc should be decayed
-	trailBuf        []int   // A buffer while cleaning bindings
+	Stats           Stats      // Statistics about the solving process.
+	minLits         []Lit      // Lits to minimize if the problem was an optimization problem.
+	minWeights      []int      // Weight of each lit to minimize if the problem was an optimization problem.
+	moreCosts       []costFunc // Lower-priority cost functions, if the problem has several objectives.
+	localNbRestarts int        // How many restarts since Solve() was called?
+	varDecay        float64    // On each var decay, how much the varInc should be decayed
+	trailBuf        []int      // A buffer while cleaning bindings
+	assumps         []Lit      // Literals assumed during the current call to SolveAssumptions, if any
+	assumpLvls      []decLevel // For each assumption already handled in the current branch, the level it was handled at
+	failed          []Lit      // After a call to SolveAssumptions, the subset of assumptions that caused the problem to be UNSAT
 }
 
 // New makes a solver, given a number of variables and a set of clauses.
@@ -109,6 +113,7 @@ func New(problem *Problem) *Solver {
 		clauseInc:   1.0,
 		minLits:     problem.minLits,
 		minWeights:  problem.minWeights,
+		moreCosts:   problem.moreCosts,
 		varDecay:    defaultVarDecay,
 		trailBuf:    make([]int, nbVars),
 	}
@@ -289,6 +294,9 @@ func (s *Solver) cleanupBindings(lvl decLevel) {
 	for i := len(toInsert) - 1; i >= 0; i-- {
 		s.varQueue.insert(toInsert[i])
 	}
+	for len(s.assumpLvls) > 0 && s.assumpLvls[len(s.assumpLvls)-1] > lvl { // These assumptions must be handled again
+		s.assumpLvls = s.assumpLvls[:len(s.assumpLvls)-1]
+	}
 	/*for i := len(s.trail) - 1; i >= 0; i-- {
 		lit := s.trail[i]
 		v := lit.Var()
@@ -343,7 +351,7 @@ func (s *Solver) propagateAndSearch(lit Lit, lvl decLevel) Status {
 				s.bumpNbMax()
 			}
 			lvl++
-			lit = s.chooseLit()
+			lit = s.nextLit(lvl)
 		} else { // Deal with conflict
 			s.Stats.NbConflicts++
 			if s.Stats.NbConflicts%5000 == 0 && s.varDecay < 0.95 {
@@ -364,8 +372,8 @@ func (s *Solver) propagateAndSearch(lit Lit, lvl decLevel) Status {
 					return s.setUnsat()
 				}
 				s.rebuildOrderHeap()
-				lit = s.chooseLit()
 				lvl = 2
+				lit = s.nextLit(lvl)
 			} else {
 				if learnt.Len() == 2 {
 					s.Stats.NbBinaryLearned++
@@ -380,6 +388,10 @@ func (s *Solver) propagateAndSearch(lit Lit, lvl decLevel) Status {
 			}
 		}
 	}
+	if s.assumps != nil && s.failed != nil { // Some assumptions could not be satisfied
+		s.cleanupBindings(1)
+		return Unsat
+	}
 	return Sat
 }
 
@@ -400,7 +412,7 @@ func (s *Solver) setUnsat() Status {
 func (s *Solver) search() Status {
 	s.localNbRestarts++
 	lvl := decLevel(2) // Level starts at 2, for implementation reasons : 1 is for top-level bindings; 0 means "no level assigned yet"
-	s.status = s.propagateAndSearch(s.chooseLit(), lvl)
+	s.status = s.propagateAndSearch(s.nextLit(lvl), lvl)
 	return s.status
 }
 
@@ -634,40 +646,33 @@ func (s *Solver) propagateUnits(units []Lit) {
 
 // PBString returns a representation of the solver's state as a pseudo-boolean problem.
 func (s *Solver) PBString() string {
-	meta := fmt.Sprintf("* #variable= %d #constraint= %d #learned= %d\n", s.nbVars, len(s.wl.pbClauses), len(s.wl.learned))
-	minLine := ""
+	var b strings.Builder
+	bw := bufio.NewWriter(&b)
+	fmt.Fprintf(bw, "* #variable= %d #constraint= %d #learned= %d\n", s.nbVars, len(s.wl.pbClauses), len(s.wl.learned))
 	if s.minLits != nil {
-		terms := make([]string, len(s.minLits))
-		for i, lit := range s.minLits {
-			weight := 1
-			if s.minWeights != nil {
-				weight = s.minWeights[i]
-			}
-			val := lit.Int()
-			sign := ""
-			if val < 0 {
-				val = -val
-				sign = "~"
-			}
-			terms[i] = fmt.Sprintf("%d %sx%d", weight, sign, val)
+		costFunc{lits: s.minLits, weights: s.minWeights}.write(bw)
+		for _, cf := range s.moreCosts {
+			cf.write(bw)
 		}
-		minLine = fmt.Sprintf("min: %s ;\n", strings.Join(terms, " +"))
-	}
-	clauses := make([]string, len(s.wl.pbClauses)+len(s.wl.learned))
-	for i, c := range s.wl.pbClauses {
-		clauses[i] = c.PBString()
 	}
-	for i, c := range s.wl.learned {
-		clauses[i+len(s.wl.pbClauses)] = c.PBString()
+	sep := ""
+	for _, clauses := range [][]*Clause{s.wl.pbClauses, s.wl.learned} {
+		for _, c := range clauses {
+			bw.WriteString(sep + c.PBString())
+			sep = "\n"
+		}
 	}
 	for i := 0; i < len(s.model); i++ {
 		if s.model[i] == 1 {
-			clauses = append(clauses, fmt.Sprintf("1 x%d = 1 ;", i+1))
+			fmt.Fprintf(bw, "%s1 x%d = 1 ;", sep, i+1)
+			sep = "\n"
 		} else if s.model[i] == -1 {
-			clauses = append(clauses, fmt.Sprintf("1 x%d = 0 ;", i+1))
+			fmt.Fprintf(bw, "%s1 x%d = 0 ;", sep, i+1)
+			sep = "\n"
 		}
 	}
-	return meta + minLine + strings.Join(clauses, "\n")
+	bw.Flush()
+	return b.String()
 }
 
 // AppendClause appends a new clause to the set of clauses.
@@ -701,7 +706,7 @@ func (s *Solver) AppendClause(clause *Clause) {
 		s.status = Unsat
 		return
 	}
-	if maxW == card { // Unit
+	if maxW == card || clause.Len() == 1 { // Unit
 		s.propagateUnits(clause.lits)
 	} else {
 		s.appendClause(clause)
@@ -714,7 +719,7 @@ func (s *Solver) Model() []bool {
 	if s.lastModel == nil {
 		panic("cannot call Model() from a non-Sat solver")
 	}
-	res := make([]bool, s.nbVars)
+	res := make([]bool, len(s.lastModel))
 	for i, lvl := range s.lastModel {
 		res[i] = lvl > 0
 	}
@@ -770,81 +775,14 @@ func (s *Solver) countCurrentModels() int {
 // Optimal returns the optimal solution, if any.
 // If results is non-nil, all solutions will be written to it.
 // In any case, results will be closed at the end of the call.
-func (s *Solver) Optimal(results chan Result, stop chan struct{}) (res Result) {
-	if results != nil {
-		defer close(results)
-	}
-	status := s.Solve()
-	if status == Unsat { // Problem cannot be satisfied at all
-		res.Status = Unsat
-		if results != nil {
-			results <- res
-		}
-		return res
+// The search is done according to s.Strategy, and each result holds the best lower bound known when it was found.
+// If the returned result is optimal, its lower bound is equal to its weight.
+// Problems with several cost functions are optimized in lexicographic order, through linear searches.
+func (s *Solver) Optimal(results chan Result, stop chan struct{}) Result {
+	if s.moreCosts != nil {
+		return s.optimalLex(results, stop)
 	}
-	if s.minLits == nil { // No optimization clause: this is a decision problem, solution is optimal
-		s.lastModel = make(Model, len(s.model))
-		copy(s.lastModel, s.model)
-		res := Result{
-			Status: Sat,
-			Model:  s.Model(),
-			Weight: 0,
-		}
-		if results != nil {
-			results <- res
-		}
-		return res
-	}
-	maxCost := 0
-	if s.minWeights == nil {
-		maxCost = len(s.minLits)
-	} else {
-		for _, w := range s.minWeights {
-			maxCost += w
-		}
-	}
-	s.hypothesis = make([]Lit, len(s.minLits))
-	for i, lit := range s.minLits {
-		s.hypothesis[i] = lit.Negation()
-	}
-	weights := make([]int, len(s.minWeights))
-	copy(weights, s.minWeights)
-	sort.Sort(wLits{lits: s.hypothesis, weights: weights})
-	s.lastModel = make(Model, len(s.model))
-	var cost int
-	for status == Sat {
-		copy(s.lastModel, s.model) // Save this model: it might be the last one
-		cost = 0
-		for i, lit := range s.minLits {
-			if s.model[lit.Var()] > 0 == lit.IsPositive() {
-				if s.minWeights == nil {
-					cost++
-				} else {
-					cost += s.minWeights[i]
-				}
-			}
-		}
-		res = Result{
-			Status: Sat,
-			Model:  s.Model(),
-			Weight: cost,
-		}
-		if results != nil {
-			results <- res
-		}
-		if cost == 0 {
-			break
-		}
-		// Add a constraint incrementing current best cost
-		lits2 := make([]Lit, len(s.minLits))
-		weights2 := make([]int, len(s.minWeights))
-		copy(lits2, s.hypothesis)
-		copy(weights2, weights)
-		s.AppendClause(NewPBClause(lits2, weights2, maxCost-cost+1))
-		s.rebuildOrderHeap()
-		status = s.Solve()
-	}
-	return res
+	return s.optimal(results, stop)
 }
 
 // Minimize tries to find a model that minimizes the weight of the clause defined as the optimisation clause in the problem.
@@ -853,70 +791,8 @@ func (s *Solver) Optimal(results chan Result, stop chan struct{}) (res Result) {
 // If this function is called on a non-optimization problem, it will either return -1, or a cost of 0 associated with a
 // satisfying model (ie any model is an optimal model).
 func (s *Solver) Minimize() int {
-	status := s.Solve()
-	if status == Unsat { // Problem cannot be satisfied at all
-		return -1
-	}
-	if s.minLits == nil { // No optimization clause: this is a decision problem, solution is optimal
-		return 0
-	}
-	maxCost := 0
-	if s.minWeights == nil {
-		maxCost = len(s.minLits)
-	} else {
-		for _, w := range s.minWeights {
-			maxCost += w
-		}
-	}
-	s.hypothesis = make([]Lit, len(s.minLits))
-	for i, lit := range s.minLits {
-		s.hypothesis[i] = lit.Negation()
-	}
-	weights := make([]int, len(s.minWeights))
-	copy(weights, s.minWeights)
-	sort.Sort(wLits{lits: s.hypothesis, weights: weights})
-	s.lastModel = make(Model, len(s.model))
-	var cost int
-	for status == Sat {
-		copy(s.lastModel, s.model) // Save this model: it might be the last one
-		cost = 0
-		for i, lit := range s.minLits {
-			if s.model[lit.Var()] > 0 == lit.IsPositive() {
-				if s.minWeights == nil {
-					cost++
-				} else {
-					cost += s.minWeights[i]
-				}
-			}
-		}
-		if cost == 0 {
-			return 0
-		}
-		if s.Verbose {
-			fmt.Printf("o %d\n", cost)
-		}
-		// Add a constraint incrementing current best cost
-		lits2 := make([]Lit, len(s.minLits))
-		weights2 := make([]int, len(s.minWeights))
-		copy(lits2, s.hypothesis)
-		copy(weights2, weights)
-		s.AppendClause(NewPBClause(lits2, weights2, maxCost-cost+1))
-		s.rebuildOrderHeap()
-		status = s.Solve()
+	if res := s.Optimal(nil, nil); res.Status == Sat {
+		return res.Weight
 	}
-	return cost
-}
-
-// functions to sort hypothesis for pseudo-boolean minimization clause.
-type wLits struct {
-	lits    []Lit
-	weights []int
-}
-
-func (wl wLits) Len() int           { return len(wl.lits) }
-func (wl wLits) Less(i, j int) bool { return wl.weights[i] > wl.weights[j] }
-
-func (wl wLits) Swap(i, j int) {
-	wl.lits[i], wl.lits[j] = wl.lits[j], wl.lits[i]
-	wl.weights[i], wl.weights[j] = wl.weights[j], wl.weights[i]
+	return -1
 }
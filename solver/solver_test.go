@@ -247,6 +247,72 @@ func TestAssume(t *testing.T) {
 	}
 }
 
+func TestSolveAssumptions(t *testing.T) {
+	clauses := [][]int{
+		{1, 2, 3},
+		{1, -2, 4},
+		{-1, 2, 5},
+		{-1, -2, 6},
+	}
+	s := New(ParseSlice(clauses))
+	assumptions := []Lit{IntToLit(-3), IntToLit(-4), IntToLit(-5), IntToLit(-6)}
+	if status := s.SolveAssumptions(assumptions); status != Unsat {
+		t.Fatalf("all clauses are activated because of assumptions, should be unsat, got %v", status)
+	}
+	failed := s.FailedAssumptions()
+	if len(failed) == 0 {
+		t.Fatalf("expected failed assumptions, got none")
+	}
+	units := make([][]int, len(clauses), len(clauses)+len(failed))
+	copy(units, clauses)
+	for _, lit := range failed {
+		units = append(units, []int{int(lit.Int())})
+	}
+	if status := New(ParseSlice(units)).Solve(); status != Unsat {
+		t.Errorf("failed assumptions %v do not make the problem unsat", failed)
+	}
+	assumptions[0] = assumptions[0].Negation()
+	if status := s.SolveAssumptions(assumptions); status != Sat {
+		t.Fatalf("one of the clauses is deactivated through assumptions, should be sat, got %v", status)
+	}
+	model := s.Model()
+	for _, lit := range assumptions {
+		if model[lit.Var()] != lit.IsPositive() {
+			t.Errorf("assumption %d not satisfied by model %v", lit.Int(), model)
+		}
+	}
+	if status := s.SolveAssumptions(nil); status != Sat {
+		t.Errorf("problem without assumptions should be sat, got %v", status)
+	}
+}
+
+func TestSolveAssumptionsNewVar(t *testing.T) {
+	s := New(ParseSlice([][]int{{1, 2}, {-1, 2}}))
+	sel := s.NewVar().Lit()
+	if s.NbVars() != 3 {
+		t.Fatalf("expected 3 vars, got %d", s.NbVars())
+	}
+	// sel -> -2
+	s.AppendClause(NewClause([]Lit{sel.Negation(), IntToLit(-2)}))
+	if status := s.SolveAssumptions([]Lit{sel}); status != Unsat {
+		t.Fatalf("expected unsat under selector, got %v", status)
+	} else if failed := s.FailedAssumptions(); len(failed) != 1 || failed[0] != sel {
+		t.Errorf("expected selector as failed assumption, got %v", failed)
+	}
+	if status := s.SolveAssumptions([]Lit{sel.Negation()}); status != Sat {
+		t.Fatalf("expected sat without selector, got %v", status)
+	}
+	if status := s.Solve(); status != Sat {
+		t.Errorf("expected sat without assumptions, got %v", status)
+	}
+	s.AppendClause(NewClause([]Lit{IntToLit(-2)}))
+	if status := s.SolveAssumptions(nil); status != Unsat {
+		t.Fatalf("expected unsat, got %v", status)
+	} else if failed := s.FailedAssumptions(); failed != nil {
+		t.Errorf("expected no failed assumptions, got %v", failed)
+	}
+}
+
 func TestCountModel(t *testing.T) {
 	clauses := []CardConstr{
 		AtLeast1(1, 2, 3),
@@ -74,7 +74,7 @@ func (wl *watcherList) Less(i, j int) bool {
 
 // Watches the provided clause.
 func (s *Solver) watchClause(c *Clause) {
-	if c.Len() == 2 {
+	if c.Len() == 2 && !c.PseudoBoolean() {
 		first := c.First()
 		second := c.Second()
 		neg0 := first.Negation()
@@ -0,0 +1,176 @@
+package solver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// WriteDIMACS writes the problem to w in the DIMACS CNF format.
+// It returns an error if the problem contains cardinality or PB constraints, or a cost function,
+// as they cannot be represented in that format.
+func (pb *Problem) WriteDIMACS(w io.Writer) error {
+	if pb.minLits != nil {
+		return fmt.Errorf("cannot write an optimization problem in the DIMACS format")
+	}
+	for _, c := range pb.Clauses {
+		if c.Cardinality() != 1 {
+			return fmt.Errorf("cannot write constraint %q in the DIMACS format", c.PBString())
+		}
+	}
+	bw := bufio.NewWriter(w)
+	pb.writeDIMACS(bw)
+	return bw.Flush()
+}
+
+// writeDIMACS writes the problem as a list of clauses, without checking it only contains clauses.
+func (pb *Problem) writeDIMACS(bw *bufio.Writer) {
+	nbClauses := len(pb.Units) + len(pb.Clauses)
+	if pb.Status == Unsat {
+		nbClauses++
+	}
+	fmt.Fprintf(bw, "p cnf %d %d\n", pb.NbVars, nbClauses)
+	if pb.Status == Unsat { // The problem was proven UNSAT while parsing it: write an empty clause
+		bw.WriteString("0\n")
+	}
+	for _, unit := range pb.Units {
+		writeLit(bw, unit)
+		bw.WriteString(" 0\n")
+	}
+	for _, c := range pb.Clauses {
+		for _, lit := range c.lits {
+			writeLit(bw, lit)
+			bw.WriteByte(' ')
+		}
+		bw.WriteString("0\n")
+	}
+}
+
+// writeLit writes the DIMACS representation of lit.
+func writeLit(bw *bufio.Writer, lit Lit) {
+	var buf [12]byte
+	bw.Write(strconv.AppendInt(buf[:0], int64(lit.Int()), 10))
+}
+
+// WriteOPB writes the problem to w in the OPB format.
+// Cost functions are written as "min:" lines, by decreasing priority.
+func (pb *Problem) WriteOPB(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	pb.writeOPB(bw)
+	return bw.Flush()
+}
+
+func (pb *Problem) writeOPB(bw *bufio.Writer) {
+	nbVars, nbConstrs := pb.NbVars, len(pb.Units)+len(pb.Clauses)
+	if pb.Status == Unsat {
+		nbConstrs += 2
+		if nbVars == 0 {
+			nbVars = 1
+		}
+	}
+	fmt.Fprintf(bw, "* #variable= %d #constraint= %d\n", nbVars, nbConstrs)
+	if pb.minLits != nil {
+		costFunc{lits: pb.minLits, weights: pb.minWeights}.write(bw)
+		for _, cf := range pb.moreCosts {
+			cf.write(bw)
+		}
+	}
+	if pb.Status == Unsat { // The problem was proven UNSAT while parsing it: write two contradictory constraints
+		bw.WriteString("1 x1 >= 1 ;\n1 ~x1 >= 1 ;\n")
+	}
+	for _, unit := range pb.Units {
+		bw.WriteString("1 ")
+		writePBLit(bw, unit)
+		bw.WriteString(" >= 1 ;\n")
+	}
+	for _, c := range pb.Clauses {
+		for i, lit := range c.lits {
+			if i > 0 {
+				bw.WriteString(" +")
+			}
+			bw.WriteString(strconv.Itoa(c.Weight(i)))
+			bw.WriteByte(' ')
+			writePBLit(bw, lit)
+		}
+		fmt.Fprintf(bw, " >= %d ;\n", c.Cardinality())
+	}
+}
+
+// writePBLit writes the OPB representation of lit, i.e "x1" or "~x1".
+func writePBLit(bw *bufio.Writer, lit Lit) {
+	val := lit.Int()
+	if val < 0 {
+		bw.WriteByte('~')
+		val = -val
+	}
+	bw.WriteByte('x')
+	bw.WriteString(strconv.Itoa(int(val)))
+}
+
+// write writes cf as a "min:" line in the OPB format.
+func (cf costFunc) write(bw *bufio.Writer) {
+	bw.WriteString("min:")
+	for i, lit := range cf.lits {
+		if w := cf.weight(i); w >= 0 && i > 0 {
+			fmt.Fprintf(bw, " +%d ", w)
+		} else {
+			fmt.Fprintf(bw, " %d ", w)
+		}
+		writePBLit(bw, lit)
+	}
+	bw.WriteString(" ;\n")
+}
+
+// WriteWCNF writes the problem to w in the WCNF format, as a partial weighted MAXSAT problem.
+// Constraints are written as hard clauses and each literal of the cost function with weight w
+// is written as a soft unit clause with weight w, requiring that literal to be false.
+// It returns an error if the problem contains cardinality or PB constraints, several cost functions,
+// or negative weights.
+func (pb *Problem) WriteWCNF(w io.Writer) error {
+	if pb.moreCosts != nil {
+		return fmt.Errorf("cannot write a problem with several cost functions in the WCNF format")
+	}
+	for _, c := range pb.Clauses {
+		if c.Cardinality() != 1 {
+			return fmt.Errorf("cannot write constraint %q in the WCNF format", c.PBString())
+		}
+	}
+	cf := costFunc{lits: pb.minLits, weights: pb.minWeights}
+	top := 1
+	for i := range cf.lits {
+		if cf.weight(i) < 0 {
+			return fmt.Errorf("cannot write negative weight %d in the WCNF format", cf.weight(i))
+		}
+		top += cf.weight(i)
+	}
+	nbClauses := len(pb.Units) + len(pb.Clauses) + len(cf.lits)
+	if pb.Status == Unsat {
+		nbClauses++
+	}
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "p wcnf %d %d %d\n", pb.NbVars, nbClauses, top)
+	hard := strconv.Itoa(top) + " "
+	if pb.Status == Unsat {
+		bw.WriteString(hard + "0\n")
+	}
+	for _, unit := range pb.Units {
+		bw.WriteString(hard)
+		writeLit(bw, unit)
+		bw.WriteString(" 0\n")
+	}
+	for _, c := range pb.Clauses {
+		bw.WriteString(hard)
+		for _, lit := range c.lits {
+			writeLit(bw, lit)
+			bw.WriteByte(' ')
+		}
+		bw.WriteString("0\n")
+	}
+	for i, lit := range cf.lits {
+		fmt.Fprintf(bw, "%d ", cf.weight(i))
+		writeLit(bw, lit.Negation())
+		bw.WriteString(" 0\n")
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,115 @@
+package solver
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/DoOR-Team/gophersat/dimacs"
+)
+
+func TestWriteRoundTrip(t *testing.T) {
+	for _, test := range []optimTest{
+		{"testcnf/100.cnf", 0},
+		{"testcnf/125.cnf", -1},
+		{"testcnf/lo_8x8_009.opb", 27},
+	} {
+		f, err := os.Open(test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var pb *Problem
+		if strings.HasSuffix(test.path, "cnf") {
+			pb, err = ParseCNF(f)
+		} else {
+			pb, err = ParseOPB(f)
+		}
+		f.Close()
+		if err != nil {
+			t.Fatalf("could not parse %q: %v", test.path, err)
+		}
+		var opb, cnf bytes.Buffer
+		if err := pb.WriteOPB(&opb); err != nil {
+			t.Fatalf("could not write %q as OPB: %v", test.path, err)
+		}
+		if err := pb.WriteDIMACS(&cnf); (err == nil) != strings.HasSuffix(test.path, "cnf") {
+			t.Fatalf("unexpected result when writing %q as DIMACS: %v", test.path, err)
+		}
+		if pb2, err := ParseOPB(&opb); err != nil {
+			t.Errorf("could not parse OPB output for %q: %v", test.path, err)
+		} else if cost := New(pb2).Minimize(); cost != test.cost {
+			t.Errorf("after OPB round trip of %q, expected cost %d, got %d", test.path, test.cost, cost)
+		}
+		if cnf.Len() == 0 {
+			continue
+		}
+		if pb2, err := ParseCNF(&cnf); err != nil {
+			t.Errorf("could not parse DIMACS output for %q: %v", test.path, err)
+		} else if cost := New(pb2).Minimize(); cost != test.cost {
+			t.Errorf("after DIMACS round trip of %q, expected cost %d, got %d", test.path, test.cost, cost)
+		}
+	}
+}
+
+func TestWriteDIMACSRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for iter := 0; iter < 200; iter++ {
+		clauses := make([][]int, 1+r.Intn(12))
+		for i := range clauses {
+			clauses[i] = r.Perm(6)[:1+r.Intn(3)] // No duplicate variable in a clause
+			for j := range clauses[i] {
+				clauses[i][j]++
+				if r.Intn(2) == 0 {
+					clauses[i][j] = -clauses[i][j]
+				}
+			}
+		}
+		var b bytes.Buffer
+		if err := ParseSliceNb(clauses, 6).WriteDIMACS(&b); err != nil {
+			t.Fatalf("could not write %v: %v", clauses, err)
+		}
+		content := b.String()
+		if _, err := dimacs.Parse(strings.NewReader(content), dimacs.Strict); err != nil {
+			t.Fatalf("invalid DIMACS output %q for %v: %v", content, clauses, err)
+		}
+		pb, err := ParseCNF(strings.NewReader(content))
+		if err != nil {
+			t.Fatalf("could not parse output %q for %v: %v", content, clauses, err)
+		}
+		if expected, got := New(ParseSliceNb(clauses, 6)).CountModels(), New(pb).CountModels(); expected != got {
+			t.Fatalf("for %v, expected %d models, got %d after round trip through %q", clauses, expected, got, content)
+		}
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	pb := ParseCardConstrs([]CardConstr{AtLeast1(1, 2), AtMost1(1, 2, 3)})
+	if err := pb.WriteDIMACS(&bytes.Buffer{}); err == nil {
+		t.Errorf("cardinality constraint should not have been written as DIMACS")
+	}
+	if err := pb.WriteWCNF(&bytes.Buffer{}); err == nil {
+		t.Errorf("cardinality constraint should not have been written as WCNF")
+	}
+	pb = ParseSlice([][]int{{1, 2}})
+	pb.SetCostFunc([]Lit{IntToLit(1)}, nil)
+	if err := pb.WriteDIMACS(&bytes.Buffer{}); err == nil {
+		t.Errorf("optimization problem should not have been written as DIMACS")
+	}
+}
+
+func ExampleProblem_WriteWCNF() {
+	pb := ParseSlice([][]int{{1, 2}, {-1, -2}})
+	pb.SetCostFunc([]Lit{IntToLit(1), IntToLit(2)}, []int{3, 5})
+	if err := pb.WriteWCNF(os.Stdout); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// p wcnf 2 4 9
+	// 9 1 2 0
+	// 9 -1 -2 0
+	// 3 -1 0
+	// 5 -2 0
+}